@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/manishmeganathan/essensio/client"
+	"github.com/manishmeganathan/essensio/config"
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+	"github.com/manishmeganathan/essensio/db"
+	"github.com/manishmeganathan/essensio/jsonrpc"
+	"github.com/manishmeganathan/essensio/node"
+)
+
+// EnvRemote is the environment variable that specifies the URL of a running node for commands
+const EnvRemote = config.EnvPrefix + "REMOTE"
+
+// backend calls the methods of the Essensio API, either
+// on a running node or on a chain in a local data directory
+type backend interface {
+	// Call calls the API method with the given "Service.Method" name
+	Call(ctx context.Context, method string, args, reply any) error
+	// Close releases the resources of the backend
+	Close() error
+}
+
+// localBackend is a backend that serves the API in-process from a local chain
+type localBackend struct {
+	chain  *chainmgr.ChainManager
+	server *jsonrpc.Server
+}
+
+// Call implements the backend interface for localBackend
+func (local *localBackend) Call(ctx context.Context, method string, args, reply any) error {
+	return local.server.Call(ctx, method, args, reply)
+}
+
+// Close implements the backend interface for localBackend.
+// The chain is stopped and its database is closed.
+func (local *localBackend) Close() error {
+	return local.chain.Stop()
+}
+
+// remoteBackend is a backend that calls the API of a running node
+type remoteBackend struct {
+	*client.Client
+}
+
+// Close implements the backend interface for remoteBackend
+func (remote remoteBackend) Close() error {
+	remote.Client.Close()
+	return nil
+}
+
+// remoteFlag registers the flag for the URL of a running node on the FlagSet
+func remoteFlag(fs *flag.FlagSet) *string {
+	return fs.String("remote", os.Getenv(EnvRemote), fmt.Sprintf("base URL of a running node to use instead of the data directory (env %v)", EnvRemote))
+}
+
+// connect returns a backend for the running node at the remote URL.
+// If the remote URL is empty, the chain in the local data directory is used.
+func connect(ctx context.Context, cfg *config.Config, remote string) (backend, error) {
+	if remote != "" {
+		return remoteBackend{client.New(remote, client.WithPaths(cfg.RPC.Path, cfg.RPC.WSPath))}, nil
+	}
+
+	chain, err := openChain(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// The API logs every call it serves, which is only useful for a node
+	log.SetOutput(io.Discard)
+
+	server := jsonrpc.NewServer()
+	if err := server.RegisterService(jsonrpc.NewAPI(chain, nil), ""); err != nil {
+		_ = chain.Stop()
+		return nil, fmt.Errorf("failed to register essensio api: %w", err)
+	}
+
+	return &localBackend{chain, server}, nil
+}
+
+// openChain opens the existing chain in the local data directory.
+// Returns an error if the data directory does not contain a chain.
+func openChain(ctx context.Context, cfg *config.Config) (*chainmgr.ChainManager, error) {
+	if !db.Exists(cfg.DataDir) {
+		return nil, fmt.Errorf("no blockchain in data directory '%v'", cfg.DataDir)
+	}
+
+	chain, err := chainmgr.NewChainManager(ctx, node.ChainConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blockchain (use -remote if a node is running): %w", err)
+	}
+
+	return chain, nil
+}
@@ -0,0 +1,391 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+	"github.com/manishmeganathan/essensio/jsonrpc"
+	"github.com/manishmeganathan/essensio/node"
+)
+
+var chainCommand = &Command{
+	Name:    "chain",
+	Summary: "Query and maintain the blockchain",
+	Subcommands: []*Command{
+		{Name: "info", Summary: "Show the chain head, height and parameters", Action: chainInfo},
+		{Name: "supply", Summary: "Show the circulating and maximum supply and the block subsidy", Action: chainSupply},
+		{Name: "show", Summary: "Show a page of blocks from the chain", Action: chainShow},
+		{Name: "get-block", Summary: "Show a block by hash or height, or the latest block", Action: chainGetBlock},
+		{Name: "get-account", Summary: "Show the balance and nonce of an address, now or after a block", Action: chainGetAccount},
+		{Name: "verify", Summary: "Verify the integrity of the chain in the data directory", Action: chainVerify},
+		{Name: "export", Summary: "Export the chain in the data directory to a file", Action: chainExport},
+		{Name: "import", Summary: "Import blocks from a file into the chain in the data directory", Action: chainImport},
+	},
+}
+
+// chainInfo prints the chain information
+func chainInfo(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	remote := remoteFlag(fs)
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	chain, err := connect(ctx, cfg, *remote)
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+
+	var result jsonrpc.GetChainInfoResult
+	if err := chain.Call(ctx, "API.GetChainInfo", &jsonrpc.GetChainInfoArgs{}, &result); err != nil {
+		return err
+	}
+
+	return printJSON(result)
+}
+
+// chainSupply prints the supply and subsidy schedule of the chain
+func chainSupply(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	remote := remoteFlag(fs)
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	chain, err := connect(ctx, cfg, *remote)
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+
+	var result jsonrpc.GetSupplyResult
+	if err := chain.Call(ctx, "API.GetSupply", &jsonrpc.GetSupplyArgs{}, &result); err != nil {
+		return err
+	}
+
+	return printJSON(result)
+}
+
+// chainShow prints a page of blocks from the chain
+func chainShow(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	var start optionalUint64
+	fs.Var(&start, "start", "height of the first block of the page")
+	limit := fs.Uint64("limit", jsonrpc.DefaultPageLimit, "maximum number of blocks in the page")
+	ascending := fs.Bool("asc", false, "iterate from lower blocks to higher blocks")
+	headersOnly := fs.Bool("headers-only", false, "omit the transactions of each block")
+	address := fs.String("address", "", "only show blocks and transactions that involve this address")
+	remote := remoteFlag(fs)
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	request := &jsonrpc.ShowChainArgs{
+		Start:       start.value,
+		Limit:       *limit,
+		Direction:   jsonrpc.DirectionDesc,
+		HeadersOnly: *headersOnly,
+		Address:     *address,
+	}
+
+	if *ascending {
+		request.Direction = jsonrpc.DirectionAsc
+	}
+
+	chain, err := connect(ctx, cfg, *remote)
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+
+	var result jsonrpc.ShowChainResult
+	if err := chain.Call(ctx, "API.ShowChain", request, &result); err != nil {
+		return err
+	}
+
+	return printJSON(result)
+}
+
+// chainGetBlock prints the block with the given hash or height, or the latest block if neither is given
+func chainGetBlock(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	var height optionalUint64
+	fs.Var(&height, "height", "height of the block")
+	hash := fs.String("hash", "", "hash of the block")
+	headersOnly := fs.Bool("headers-only", false, "omit the transactions of the block")
+	remote := remoteFlag(fs)
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *hash != "" && height.value != nil {
+		return fmt.Errorf("only one of -hash and -height may be given")
+	}
+
+	chain, err := connect(ctx, cfg, *remote)
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+
+	var result jsonrpc.ChainBlock
+
+	switch {
+	case *hash != "":
+		err = chain.Call(ctx, "API.GetBlockByHash", &jsonrpc.GetBlockByHashArgs{Hash: *hash, HeadersOnly: *headersOnly}, &result)
+	case height.value != nil:
+		err = chain.Call(ctx, "API.GetBlockByHeight", &jsonrpc.GetBlockByHeightArgs{Height: height.value, HeadersOnly: *headersOnly}, &result)
+	default:
+		err = chain.Call(ctx, "API.GetLatestBlock", &jsonrpc.GetLatestBlockArgs{HeadersOnly: *headersOnly}, &result)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return printJSON(result)
+}
+
+// chainAccount is the state of an address printed by 'chain get-account'
+type chainAccount struct {
+	Address     string        `json:"address"`
+	Balance     common.Amount `json:"balance"`
+	Nonce       uint64        `json:"nonce"`
+	BlockHash   string        `json:"block_hash,omitempty"`
+	BlockHeight *uint64       `json:"block_height,omitempty"`
+}
+
+// chainGetAccount prints the state of an address, either the current state or
+// the state after the block with the given hash or height
+func chainGetAccount(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	address := fs.String("address", "", "address of the account")
+	var height optionalUint64
+	fs.Var(&height, "height", "height of the block after which the account is shown")
+	hash := fs.String("hash", "", "hash of the block after which the account is shown")
+	remote := remoteFlag(fs)
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *hash != "" && height.value != nil {
+		return fmt.Errorf("only one of -hash and -height may be given")
+	}
+
+	chain, err := connect(ctx, cfg, *remote)
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+
+	request := &jsonrpc.AccountArgs{Address: *address, BlockHash: *hash, BlockHeight: height.value}
+
+	var balance jsonrpc.GetBalanceResult
+	if err := chain.Call(ctx, "API.GetBalance", request, &balance); err != nil {
+		return err
+	}
+
+	// The nonce is pinned to the block of the balance, if any
+	if balance.BlockHash != "" {
+		request = &jsonrpc.AccountArgs{Address: *address, BlockHash: balance.BlockHash}
+	}
+
+	var nonce jsonrpc.GetNonceResult
+	if err := chain.Call(ctx, "API.GetNonce", request, &nonce); err != nil {
+		return err
+	}
+
+	return printJSON(chainAccount{
+		Address:     *address,
+		Balance:     balance.Balance,
+		Nonce:       nonce.Nonce,
+		BlockHash:   balance.BlockHash,
+		BlockHeight: balance.BlockHeight,
+	})
+}
+
+// chainVerify verifies every block of the chain in the local data directory
+func chainVerify(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	chain, err := openChain(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer chain.Stop()
+
+	if err := chain.Verify(ctx); err != nil {
+		// Chains whose block hashes were computed from the gob encoding of their
+		// headers cannot be migrated, since their Proof of Work is over those hashes
+		if errors.Is(err, core.ErrInvalidBlockHash) {
+			return fmt.Errorf("chain verification failed: %w (chains created before block hashes were computed "+
+				"from their canonical encoding must be reset, see the 'Block hashes' section of the README)", err)
+		}
+
+		return fmt.Errorf("chain verification failed: %w", err)
+	}
+
+	head, height := chain.Tip()
+	fmt.Printf("Verified %v blocks up to chain head %v\n", height, head.Hex())
+	return nil
+}
+
+// chainExport writes every block of the chain in the local data directory to a file
+func chainExport(ctx context.Context, fs *flag.FlagSet, args []string) (err error) {
+	path := fs.String("file", "", "path of the file to export the blocks to")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *path == "" {
+		return fmt.Errorf("-file is required")
+	}
+
+	chain, err := openChain(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer chain.Stop()
+
+	// A pruned chain cannot be exported from its genesis block
+	if pruned := chain.PrunedHeight(); pruned > 0 {
+		return fmt.Errorf("cannot export a pruned chain: %w below height %v", chainmgr.ErrBlockPruned, pruned)
+	}
+
+	file, err := os.Create(*path)
+	if err != nil {
+		return fmt.Errorf("export file create failed: %w", err)
+	}
+
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("export file close failed: %w", closeErr)
+		}
+	}()
+
+	writer, err := chainmgr.NewBlockWriter(file, chain.Params())
+	if err != nil {
+		return err
+	}
+
+	// Write the blocks from the genesis block to the chain head
+	head, chainHeight := chain.Tip()
+	for height := int64(0); height < chainHeight; height++ {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("export cancelled: %w", err)
+		}
+
+		block, err := chain.GetBlockByHeight(height)
+		if err != nil {
+			return err
+		}
+
+		if err := writer.Write(block); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Exported %v blocks up to chain head %v\n", chainHeight, head.Hex())
+	return nil
+}
+
+// chainImport appends the blocks from a file to the chain in the local data directory.
+// The chain is initialized with the genesis block from the file if it does not exist.
+func chainImport(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	path := fs.String("file", "", "path of the file to import the blocks from")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *path == "" {
+		return fmt.Errorf("-file is required")
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		return fmt.Errorf("import file open failed: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := chainmgr.NewBlockReader(file)
+	if err != nil {
+		return err
+	}
+
+	genesis, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("import file has no blocks")
+		}
+
+		return err
+	}
+
+	// Open the chain, initializing it with the genesis block and parameters from the file if required
+	chainConfig := node.ChainConfig(cfg)
+	chainConfig.Genesis = genesis
+	chainConfig.Params = reader.Params()
+
+	chain, err := chainmgr.NewChainManager(ctx, chainConfig)
+	if err != nil {
+		return fmt.Errorf("failed to open blockchain: %w", err)
+	}
+	defer chain.Stop()
+
+	if params := reader.Params(); chain.Params() != params {
+		if chain.Params().ChainID != params.ChainID {
+			return fmt.Errorf("import file is from chain %v, expected chain %v", params.ChainID, chain.Params().ChainID)
+		}
+
+		return fmt.Errorf("import file is from a chain with different parameters")
+	}
+
+	var imported, skipped int
+	for block := genesis; ; {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("import cancelled after %v blocks: %w", imported, err)
+		}
+
+		added, err := chain.ImportBlock(block)
+		if err != nil {
+			return fmt.Errorf("block %v import failed: %w", block.BlockHeight, err)
+		}
+
+		if added {
+			imported++
+		} else {
+			skipped++
+		}
+
+		if block, err = reader.Read(); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return err
+		}
+	}
+
+	fmt.Printf("Imported %v blocks (%v already present) up to chain head %v\n", imported, skipped, chain.Head().Hex())
+	return nil
+}
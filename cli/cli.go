@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/manishmeganathan/essensio/config"
+)
+
+// Exit codes of the essensio binary
+const (
+	// Represents a command that succeeded
+	ExitOK = 0
+	// Represents a command that failed, including a node that failed to start
+	ExitFailure = 1
+	// Represents a node that failed while running or did not shut down cleanly
+	ExitRuntime = 2
+)
+
+// exitError is an error that exits the binary with a specific exit code
+type exitError struct {
+	code int
+	err  error
+}
+
+// Error implements the error interface for exitError
+func (err *exitError) Error() string {
+	return err.err.Error()
+}
+
+// Unwrap returns the underlying error of the exitError
+func (err *exitError) Unwrap() error {
+	return err.err
+}
+
+// Command is a command of the essensio binary.
+// A Command either has an Action or a set of Subcommands.
+type Command struct {
+	// Represents the name of the command
+	Name string
+	// Represents a short description of the command
+	Summary string
+
+	// Represents the action of the command. It registers its own flags on the
+	// FlagSet and then loads the configuration with the given arguments.
+	Action func(ctx context.Context, fs *flag.FlagSet, args []string) error
+	// Represents the subcommands of the command
+	Subcommands []*Command
+}
+
+// root is the top level command of the essensio binary
+var root = &Command{
+	Name:        "essensio",
+	Subcommands: []*Command{nodeCommand, walletCommand, chainCommand, lightCommand, snapshotCommand, dbCommand, scriptCommand},
+}
+
+// Run runs the command for the given arguments and returns the exit code.
+// If no command is given, a node is started as with 'node run'.
+// The context of the command is cancelled on the first SIGINT or SIGTERM.
+func Run(args []string) int {
+	// Start the node if the arguments begin with flags, as before commands existed
+	if len(args) == 0 || (strings.HasPrefix(args[0], "-") && !isHelp(args[0])) {
+		args = append([]string{"node", "run"}, args...)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		// Restore the default signal behaviour so that a second signal kills the process
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := root.execute(ctx, root.Name, args)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+
+	fmt.Fprintln(os.Stderr, "Error:", err)
+
+	var exit *exitError
+	if errors.As(err, &exit) {
+		return exit.code
+	}
+
+	return ExitFailure
+}
+
+// isHelp returns whether the argument is a request for help
+func isHelp(arg string) bool {
+	return arg == "help" || arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+// execute runs the Command at the given command path with the given arguments.
+// Commands with subcommands dispatch to the subcommand named by the first argument.
+func (command *Command) execute(ctx context.Context, path string, args []string) error {
+	if command.Action != nil {
+		fs := flag.NewFlagSet(path, flag.ContinueOnError)
+		fs.Usage = func() {
+			fmt.Fprintf(fs.Output(), "Usage: %v [flags]\n\n%v\n\nFlags:\n", path, command.Summary)
+			fs.PrintDefaults()
+		}
+
+		return command.Action(ctx, fs, args)
+	}
+
+	if len(args) == 0 {
+		command.usage(path)
+		return fmt.Errorf("'%v' requires a command", path)
+	}
+
+	if isHelp(args[0]) {
+		command.usage(path)
+		return flag.ErrHelp
+	}
+
+	for _, subcommand := range command.Subcommands {
+		if subcommand.Name == args[0] {
+			return subcommand.execute(ctx, path+" "+subcommand.Name, args[1:])
+		}
+	}
+
+	command.usage(path)
+	return fmt.Errorf("unknown command '%v %v'", path, args[0])
+}
+
+// usage prints the subcommands of the Command at the given command path
+func (command *Command) usage(path string) {
+	fmt.Fprintf(os.Stderr, "Usage: %v <command> [flags]\n\nCommands:\n", path)
+
+	writer := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	for _, subcommand := range command.Subcommands {
+		fmt.Fprintf(writer, "  %v\t%v\n", subcommand.Name, subcommand.Summary)
+	}
+	_ = writer.Flush()
+
+	fmt.Fprintf(os.Stderr, "\nRun '%v <command> -h' for more information on a command.\n", path)
+}
+
+// loadConfig loads the configuration with the flags registered on the FlagSet and the given arguments.
+// Returns an error if any positional arguments remain, because commands only accept flags.
+func loadConfig(fs *flag.FlagSet, args []string) (*config.Config, error) {
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %v", strings.Join(fs.Args(), " "))
+	}
+
+	return cfg, nil
+}
+
+// printJSON prints the indented JSON encoding of v to stdout
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+// optionalUint64 is a flag.Value for an unsigned integer flag that is nil unless set
+type optionalUint64 struct {
+	value *uint64
+}
+
+// String implements the flag.Value interface for optionalUint64
+func (flag *optionalUint64) String() string {
+	if flag.value == nil {
+		return ""
+	}
+
+	return strconv.FormatUint(*flag.value, 10)
+}
+
+// Set implements the flag.Value interface for optionalUint64
+func (flag *optionalUint64) Set(value string) error {
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	flag.value = &parsed
+	return nil
+}
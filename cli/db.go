@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/manishmeganathan/essensio/config"
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+	"github.com/manishmeganathan/essensio/core/state"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+var dbCommand = &Command{
+	Name:    "db",
+	Summary: "Inspect and maintain the database in the data directory",
+	Subcommands: []*Command{
+		{Name: "inspect", Summary: "Show the size and contents of the database", Action: dbInspect},
+		{Name: "compact", Summary: "Reclaim space from overwritten database entries", Action: dbCompact},
+	},
+}
+
+// keyCategories are the categories of database keys in the order they are shown by 'db inspect'
+var keyCategories = []string{"block headers", "block bodies", "height index", "transaction index", "work index", "accounts", "utxo set", "utxo index", "state trie", "chain state", "other"}
+
+// categorize returns the category of the given database key
+func categorize(key []byte) string {
+	switch {
+	case bytes.Equal(key, chainmgr.ChainHeadKey), bytes.Equal(key, chainmgr.ChainHeightKey), bytes.Equal(key, chainmgr.ChainParamsKey), bytes.Equal(key, chainmgr.LightChainKey),
+		bytes.Equal(key, chainmgr.PrunedHeightKey):
+		return "chain state"
+	case bytes.HasPrefix(key, chainmgr.HeaderPrefix):
+		return "block headers"
+	case bytes.HasPrefix(key, chainmgr.BodyPrefix):
+		return "block bodies"
+	case bytes.HasPrefix(key, chainmgr.HeightIndexPrefix):
+		return "height index"
+	case bytes.HasPrefix(key, chainmgr.TxnIndexPrefix):
+		return "transaction index"
+	case bytes.HasPrefix(key, chainmgr.WorkIndexPrefix):
+		return "work index"
+	case bytes.HasPrefix(key, state.AccountPrefix):
+		return "accounts"
+	case bytes.HasPrefix(key, state.CoinPrefix):
+		return "utxo set"
+	case bytes.HasPrefix(key, state.CoinIndexPrefix):
+		return "utxo index"
+	case bytes.HasPrefix(key, state.TrieNodePrefix):
+		return "state trie"
+	default:
+		return "other"
+	}
+}
+
+// openDatabase opens the existing database in the local data directory
+func openDatabase(cfg *config.Config) (*db.Database, error) {
+	if !db.Exists(cfg.DataDir) {
+		return nil, fmt.Errorf("no database in data directory '%v'", cfg.DataDir)
+	}
+
+	return db.Open(cfg.DataDir)
+}
+
+// dbInspect prints the disk usage of the database and the number and size of its entries by category
+func dbInspect(_ context.Context, fs *flag.FlagSet, args []string) error {
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	counts := make(map[string]int)
+	sizes := make(map[string]int64)
+
+	if err := database.IterateKeys(func(key []byte, size int64) error {
+		category := categorize(key)
+		counts[category]++
+		sizes[category] += size
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("database iteration failed: %w", err)
+	}
+
+	lsm, vlog, err := db.Usage(cfg.DataDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Data Directory: %v\nLSM Tree: %v bytes\nValue Log: %v bytes\n\n", cfg.DataDir, lsm, vlog)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "CATEGORY\tENTRIES\tSIZE\t")
+	for _, category := range keyCategories {
+		fmt.Fprintf(writer, "%v\t%v\t%v\t\n", category, counts[category], sizes[category])
+	}
+
+	return writer.Flush()
+}
+
+// dbCompact compacts the database and prints the disk usage before and after compaction
+func dbCompact(_ context.Context, fs *flag.FlagSet, args []string) error {
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+
+	lsm, vlog, err := db.Usage(cfg.DataDir)
+	if err != nil {
+		_ = database.Close()
+		return err
+	}
+
+	if err := database.Compact(); err != nil {
+		_ = database.Close()
+		return err
+	}
+
+	// The database is closed before measuring so that discarded files are removed
+	if err := database.Close(); err != nil {
+		return err
+	}
+
+	compactLSM, compactVlog, err := db.Usage(cfg.DataDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Compacted %v from %v to %v bytes\n", cfg.DataDir, lsm+vlog, compactLSM+compactVlog)
+	return nil
+}
@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/hdwallet"
+	"github.com/manishmeganathan/essensio/jsonrpc"
+)
+
+var hdCommand = &Command{
+	Name:    "hd",
+	Summary: "Manage hierarchical deterministic wallets derived from a mnemonic",
+	Subcommands: []*Command{
+		{Name: "new", Summary: "Generate a new mnemonic", Action: hdNew},
+		{Name: "addresses", Summary: "List the addresses derived from a mnemonic", Action: hdAddresses},
+		{Name: "scan", Summary: "Scan the chain for the used addresses of a mnemonic", Action: hdScan},
+		{Name: "import", Summary: "Import a key derived from a mnemonic into the keystore", Action: hdImport},
+	},
+}
+
+// hdFlags are the flags that select the wallet derived from a mnemonic
+type hdFlags struct {
+	mnemonicFile   *string
+	passphraseFile *string
+	account        *uint
+}
+
+// walletFlags registers the flags that select a wallet on the FlagSet
+func walletFlags(fs *flag.FlagSet) *hdFlags {
+	return &hdFlags{
+		mnemonicFile:   fs.String("mnemonic-file", "", "file containing the mnemonic (prompted for if not given)"),
+		passphraseFile: fs.String("passphrase-file", "", "file containing the optional passphrase of the mnemonic"),
+		account:        fs.Uint("account", 0, "account of the derivation paths"),
+	}
+}
+
+// open returns the wallet for the mnemonic and passphrase of the flags
+func (flags *hdFlags) open() (*hdwallet.Wallet, error) {
+	mnemonic, err := readPassword(*flags.mnemonicFile, "mnemonic", false)
+	if err != nil {
+		return nil, err
+	}
+
+	var passphrase string
+	if *flags.passphraseFile != "" {
+		if passphrase, err = readPassword(*flags.passphraseFile, "passphrase", false); err != nil {
+			return nil, err
+		}
+	}
+
+	return hdwallet.New(mnemonic, passphrase, uint32(*flags.account))
+}
+
+// hdNew generates a new mnemonic and prints it with its first address
+func hdNew(_ context.Context, fs *flag.FlagSet, args []string) error {
+	words := fs.Int("words", 24, "number of words in the mnemonic (12, 15, 18, 21 or 24)")
+	passphraseFile := fs.String("passphrase-file", "", "file containing the optional passphrase of the mnemonic")
+
+	if _, err := loadConfig(fs, args); err != nil {
+		return err
+	}
+
+	if *words%3 != 0 {
+		return fmt.Errorf("-words must be 12, 15, 18, 21 or 24")
+	}
+
+	entropy, err := hdwallet.NewEntropy(*words / 3 * 32)
+	if err != nil {
+		return fmt.Errorf("-words must be 12, 15, 18, 21 or 24")
+	}
+
+	mnemonic, err := hdwallet.NewMnemonic(entropy)
+	if err != nil {
+		return err
+	}
+
+	var passphrase string
+	if *passphraseFile != "" {
+		if passphrase, err = readPassword(*passphraseFile, "passphrase", false); err != nil {
+			return err
+		}
+	}
+
+	wallet, err := hdwallet.New(mnemonic, passphrase, 0)
+	if err != nil {
+		return err
+	}
+
+	address, err := wallet.Address(0)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, "Write down the mnemonic and keep it safe, it is the only way to recover the wallet:")
+	fmt.Println(mnemonic)
+	fmt.Fprintf(os.Stderr, "First address (%v): %v\n", wallet.Path(0), address)
+
+	return nil
+}
+
+// hdAddresses prints the addresses derived from a mnemonic with their derivation paths
+func hdAddresses(_ context.Context, fs *flag.FlagSet, args []string) error {
+	flags := walletFlags(fs)
+	start := fs.Uint("start", 0, "index of the first address")
+	count := fs.Uint("count", 10, "number of addresses")
+
+	if _, err := loadConfig(fs, args); err != nil {
+		return err
+	}
+
+	wallet, err := flags.open()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer writer.Flush()
+
+	fmt.Fprintln(writer, "INDEX\tPATH\tADDRESS")
+	for index := uint32(*start); index < uint32(*start+*count); index++ {
+		address, err := wallet.Address(index)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(writer, "%v\t%v\t%v\n", index, wallet.Path(index), address)
+	}
+
+	return nil
+}
+
+// hdScan scans the chain for the used addresses of a mnemonic and prints them with the aggregate balance
+func hdScan(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	flags := walletFlags(fs)
+	gap := fs.Uint("gap", hdwallet.DefaultGapLimit, "number of consecutive unused addresses after which to stop")
+	remote := remoteFlag(fs)
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	wallet, err := flags.open()
+	if err != nil {
+		return err
+	}
+
+	chain, err := connect(ctx, cfg, *remote)
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+
+	state := func(ctx context.Context, address common.Address) (common.Amount, uint64, error) {
+		args := &jsonrpc.AccountArgs{Address: string(address)}
+
+		var balance jsonrpc.GetBalanceResult
+		if err := chain.Call(ctx, "API.GetBalance", args, &balance); err != nil {
+			return 0, 0, err
+		}
+
+		var nonce jsonrpc.GetNonceResult
+		if err := chain.Call(ctx, "API.GetNonce", args, &nonce); err != nil {
+			return 0, 0, err
+		}
+
+		return balance.Balance, nonce.Nonce, nil
+	}
+
+	result, err := wallet.Scan(ctx, state, uint32(*gap))
+	if err != nil {
+		return err
+	}
+
+	return printJSON(result)
+}
+
+// hdImport imports the key at an index of a mnemonic into the keystore
+func hdImport(_ context.Context, fs *flag.FlagSet, args []string) error {
+	flags := walletFlags(fs)
+	index := fs.Uint("index", 0, "index of the key to import")
+	passwordFile := passwordFileFlag(fs, "password-file", "password of the imported account")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	wallet, err := flags.open()
+	if err != nil {
+		return err
+	}
+
+	key, err := wallet.Key(uint32(*index))
+	if err != nil {
+		return err
+	}
+
+	keys, err := openKeystore(cfg)
+	if err != nil {
+		return err
+	}
+
+	password, err := readPassword(*passwordFile, "password", true)
+	if err != nil {
+		return err
+	}
+
+	address, err := keys.ImportKey(key.PrivateKey(), password)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(address)
+	return nil
+}
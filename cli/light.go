@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/client"
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/config"
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+	"github.com/manishmeganathan/essensio/jsonrpc"
+	"github.com/manishmeganathan/essensio/light"
+)
+
+var lightCommand = &Command{
+	Name:    "light",
+	Summary: "Follow the headers of a chain and verify transactions without the full chain",
+	Subcommands: []*Command{
+		{Name: "sync", Summary: "Download and validate the headers of a running node", Action: lightSync},
+		{Name: "info", Summary: "Show the head, height and work of the light chain", Action: lightInfo},
+		{Name: "verify", Summary: "Verify that a transaction is included in the light chain", Action: lightVerify},
+		{Name: "account", Summary: "Verify the balance and nonce of an address against the light chain", Action: lightAccount},
+	},
+}
+
+// lightChainInfo is the information of a light chain printed by 'light info'
+type lightChainInfo struct {
+	ChainID     uint64 `json:"chain_id"`
+	ChainHead   string `json:"chain_head"`
+	ChainHeight uint64 `json:"chain_height"`
+	TotalWork   string `json:"total_work"`
+}
+
+// lightPayment is a transaction verified by 'light verify'
+type lightPayment struct {
+	jsonrpc.BlockTransaction
+
+	BlockHash     string `json:"block_hash"`
+	BlockHeight   uint64 `json:"block_height"`
+	Confirmations uint64 `json:"confirmations"`
+}
+
+// lightAccountState is the state of an address verified by 'light account'
+type lightAccountState struct {
+	Address     string        `json:"address"`
+	Balance     common.Amount `json:"balance"`
+	Nonce       uint64        `json:"nonce"`
+	BlockHash   string        `json:"block_hash"`
+	BlockHeight uint64        `json:"block_height"`
+	StateRoot   string        `json:"state_root"`
+}
+
+// openLightChain opens the light chain in the local data directory, which is created if it does not exist.
+// The light chain trusts the genesis hash of the configuration, which is required.
+func openLightChain(cfg *config.Config) (*chainmgr.HeaderChain, error) {
+	if cfg.Chain.Genesis == "" {
+		return nil, fmt.Errorf("chain.genesis is required: set it to the 'genesis_hash' of GetChainInfo from a trusted node")
+	}
+
+	genesis, err := common.HexToHash(cfg.Chain.Genesis)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chain.genesis: %w", err)
+	}
+
+	chain, err := chainmgr.NewHeaderChain(cfg.DataDir, cfg.Chain.ID, genesis)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open light chain: %w", err)
+	}
+
+	return chain, nil
+}
+
+// lightClient returns a client for the running node at the remote URL, which is required
+func lightClient(cfg *config.Config, remote string) (*client.Client, error) {
+	if remote == "" {
+		return nil, fmt.Errorf("-remote is required to reach a full node")
+	}
+
+	return client.New(remote, client.WithPaths(cfg.RPC.Path, cfg.RPC.WSPath)), nil
+}
+
+// lightSync downloads the headers of a running node into the light chain
+func lightSync(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	remote := remoteFlag(fs)
+	follow := fs.Bool("follow", false, "keep following new chain heads until interrupted")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	node, err := lightClient(cfg, *remote)
+	if err != nil {
+		return err
+	}
+	defer node.Close()
+
+	chain, err := openLightChain(cfg)
+	if err != nil {
+		return err
+	}
+	defer chain.Stop()
+
+	report := func(added int) {
+		head, height := chain.Tip()
+		fmt.Printf("Added %v headers up to chain head %v at height %v\n", added, head.Hex(), height-1)
+	}
+
+	if *follow {
+		return light.Follow(ctx, chain, node, report)
+	}
+
+	added, err := light.Sync(ctx, chain, node)
+	if err != nil {
+		return err
+	}
+
+	report(added)
+	return nil
+}
+
+// lightInfo prints the information of the light chain
+func lightInfo(_ context.Context, fs *flag.FlagSet, args []string) error {
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	chain, err := openLightChain(cfg)
+	if err != nil {
+		return err
+	}
+	defer chain.Stop()
+
+	work, err := chain.TotalWork()
+	if err != nil {
+		return err
+	}
+
+	head, height := chain.Tip()
+	return printJSON(lightChainInfo{
+		ChainID:     chain.ChainID(),
+		ChainHead:   head.Hex(),
+		ChainHeight: uint64(height),
+		TotalWork:   common.HexEncode(work.Bytes()),
+	})
+}
+
+// lightVerify verifies the inclusion of a transaction served by a running node against the light chain
+func lightVerify(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	remote := remoteFlag(fs)
+	hash := fs.String("hash", "", "hash of the transaction")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	txnhash, err := common.HexToHash(*hash)
+	if err != nil {
+		return fmt.Errorf("invalid transaction hash '%v': %w", *hash, err)
+	}
+
+	node, err := lightClient(cfg, *remote)
+	if err != nil {
+		return err
+	}
+	defer node.Close()
+
+	chain, err := openLightChain(cfg)
+	if err != nil {
+		return err
+	}
+	defer chain.Stop()
+
+	payment, err := light.VerifyTransaction(ctx, chain, node, txnhash)
+	if err != nil {
+		return err
+	}
+
+	transaction, err := jsonrpc.NewBlockTransaction(payment.Transaction)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(lightPayment{
+		BlockTransaction: transaction,
+		BlockHash:        payment.Header.BlockHash.Hex(),
+		BlockHeight:      uint64(payment.Header.BlockHeight),
+		Confirmations:    uint64(payment.Confirmations),
+	})
+}
+
+// lightAccount verifies the state of an address served by a running node against the light chain
+func lightAccount(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	remote := remoteFlag(fs)
+	address := fs.String("address", "", "address of the account")
+	var height optionalUint64
+	fs.Var(&height, "height", "height of the block after which the account is verified (default the chain head)")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if err := common.Address(*address).Validate(); err != nil {
+		return err
+	}
+
+	node, err := lightClient(cfg, *remote)
+	if err != nil {
+		return err
+	}
+	defer node.Close()
+
+	chain, err := openLightChain(cfg)
+	if err != nil {
+		return err
+	}
+	defer chain.Stop()
+
+	account, err := light.VerifyAccount(ctx, chain, node, common.Address(*address), height.value)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(lightAccountState{
+		Address:     *address,
+		Balance:     account.Account.Balance,
+		Nonce:       account.Account.Nonce,
+		BlockHash:   account.Header.BlockHash.Hex(),
+		BlockHeight: uint64(account.Header.BlockHeight),
+		StateRoot:   account.Header.StateRoot.Hex(),
+	})
+}
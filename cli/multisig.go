@@ -0,0 +1,270 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/jsonrpc"
+	"github.com/manishmeganathan/essensio/keystore"
+)
+
+var multisigCommand = &Command{
+	Name:    "multisig",
+	Summary: "Create multisig accounts and collect the signatures of their transactions",
+	Subcommands: []*Command{
+		{Name: "create", Summary: "Print the address and lock script of an m-of-n multisig account", Action: multisigCreate},
+		{Name: "propose", Summary: "Write an unsigned transaction from a multisig account to a file", Action: multisigPropose},
+		{Name: "sign", Summary: "Sign the transaction in a file with a participant in the keystore", Action: multisigSign},
+		{Name: "combine", Summary: "Combine the signatures of copies of a transaction into a file", Action: multisigCombine},
+		{Name: "submit", Summary: "Mine a block with the transaction in a file once it has enough signatures", Action: multisigSubmit},
+	},
+}
+
+// readPartial reads the partially signed transaction in the given file and verifies its signatures
+func readPartial(path string) (*core.PartialTransaction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("partial transaction read failed: %w", err)
+	}
+
+	var partial jsonrpc.PartialTransaction
+	if err := json.Unmarshal(data, &partial); err != nil {
+		return nil, fmt.Errorf("partial transaction decode failed: %w", err)
+	}
+
+	return partial.Partial()
+}
+
+// writePartial writes the partially signed transaction to the given file and prints how many participants have signed it
+func writePartial(path string, partial *core.PartialTransaction) error {
+	encoded, err := jsonrpc.NewPartialTransaction(partial)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(encoded, "", "  ")
+	if err != nil {
+		return fmt.Errorf("partial transaction encode failed: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("partial transaction write failed: %w", err)
+	}
+
+	fmt.Printf("Wrote %v with %v of %v signatures (%v required)\n", path,
+		partial.Signed(), len(partial.Multisig.PublicKeys), partial.Multisig.Threshold)
+	return nil
+}
+
+// multisigCreate prints the address and lock script of the multisig account with the given threshold and public keys
+func multisigCreate(_ context.Context, fs *flag.FlagSet, args []string) error {
+	threshold := fs.Int("threshold", 0, "number of signatures required to spend from the account")
+	keys := fs.String("public-keys", "", "comma separated public keys of the participants (see 'wallet public-key')")
+
+	if _, err := loadConfig(fs, args); err != nil {
+		return err
+	}
+
+	var publicKeys [][]byte
+	for _, key := range strings.Split(*keys, ",") {
+		publicKey, err := common.HexDecode(strings.TrimSpace(key))
+		if err != nil {
+			return fmt.Errorf("invalid public key '%v': %w", key, err)
+		}
+
+		publicKeys = append(publicKeys, publicKey)
+	}
+
+	multisig, err := core.NewMultisig(*threshold, publicKeys)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(jsonrpc.NewMultisigAccount(multisig))
+}
+
+// multisigPropose writes an unsigned transaction from a multisig account to a file for its participants to sign
+func multisigPropose(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	lock := fs.String("script", "", "hex encoded lock script of the multisig account (see 'wallet multisig create')")
+	to := fs.String("to", "", "address of the receiver")
+	var value, fee common.Amount
+	fs.Var(&value, "value", "amount of tokens to send, such as '1.25 Essence' (in Nubs without a unit)")
+	fs.Var(&fee, "fee", "fee paid to the miner, such as '300 Pith' (in Nubs without a unit)")
+	path := fs.String("file", "", "path of the file to write the transaction to")
+	remote := remoteFlag(fs)
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *to == "" || value == 0 || *path == "" {
+		return fmt.Errorf("-to, -file and a non-zero -value are required")
+	}
+
+	chain, err := connect(ctx, cfg, *remote)
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+
+	request := &jsonrpc.CreatePartialTransactionArgs{Script: *lock, To: *to, Value: value, Fee: fee}
+
+	var result jsonrpc.PartialTransaction
+	if err := chain.Call(ctx, "API.CreatePartialTransaction", request, &result); err != nil {
+		return err
+	}
+
+	partial, err := result.Partial()
+	if err != nil {
+		return err
+	}
+
+	return writePartial(*path, partial)
+}
+
+// multisigSign signs the transaction in a file with the key of a participant in the keystore and writes it back.
+// The transaction is signed locally with the password of the participant, unless the node is asked to sign it
+// with the participant unlocked in its own keystore.
+func multisigSign(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	path := fs.String("file", "", "path of the file with the transaction")
+	address := fs.String("address", "", "address of the signing participant")
+	nodeSign := fs.Bool("node-sign", false, "have the node sign with the participant unlocked in its keystore (requires -remote)")
+	passwordFile := passwordFileFlag(fs, "password-file", "password of the signing participant")
+	remote := remoteFlag(fs)
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *nodeSign && *remote == "" {
+		return fmt.Errorf("-node-sign requires -remote")
+	}
+
+	partial, err := readPartial(*path)
+	if err != nil {
+		return err
+	}
+
+	if *nodeSign {
+		chain, err := connect(ctx, cfg, *remote)
+		if err != nil {
+			return err
+		}
+		defer chain.Close()
+
+		encoded, err := jsonrpc.NewPartialTransaction(partial)
+		if err != nil {
+			return err
+		}
+
+		var result jsonrpc.PartialTransaction
+		if err := chain.Call(ctx, "API.SignPartialTransaction", &jsonrpc.SignPartialTransactionArgs{Partial: encoded, Address: *address}, &result); err != nil {
+			return err
+		}
+
+		if partial, err = result.Partial(); err != nil {
+			return err
+		}
+
+		return writePartial(*path, partial)
+	}
+
+	keys, err := openKeystore(cfg)
+	if err != nil {
+		return err
+	}
+
+	if !keys.Has(common.Address(*address)) {
+		return fmt.Errorf("%w: %v", keystore.ErrAccountNotFound, *address)
+	}
+
+	password, err := readPassword(*passwordFile, "password", false)
+	if err != nil {
+		return err
+	}
+
+	if err := keys.SignPartialTransactionWithPassword(partial, common.Address(*address), password); err != nil {
+		return err
+	}
+
+	return writePartial(*path, partial)
+}
+
+// multisigCombine combines the signatures of copies of the same transaction that were signed separately
+func multisigCombine(_ context.Context, fs *flag.FlagSet, args []string) error {
+	files := fs.String("files", "", "comma separated paths of the files with copies of the transaction")
+	path := fs.String("file", "", "path of the file to write the combined transaction to")
+
+	if _, err := loadConfig(fs, args); err != nil {
+		return err
+	}
+
+	if *files == "" || *path == "" {
+		return fmt.Errorf("-files and -file are required")
+	}
+
+	var combined *core.PartialTransaction
+	for _, file := range strings.Split(*files, ",") {
+		partial, err := readPartial(file)
+		if err != nil {
+			return fmt.Errorf("%v: %w", file, err)
+		}
+
+		if combined == nil {
+			combined = partial
+			continue
+		}
+
+		if err := combined.Combine(partial); err != nil {
+			return fmt.Errorf("%v: %w", file, err)
+		}
+	}
+
+	return writePartial(*path, combined)
+}
+
+// multisigSubmit mines a block with the transaction in a file, which must be signed by a threshold of participants
+func multisigSubmit(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	path := fs.String("file", "", "path of the file with the transaction")
+	remote := remoteFlag(fs)
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	partial, err := readPartial(*path)
+	if err != nil {
+		return err
+	}
+
+	if !partial.Complete() {
+		return fmt.Errorf("%w: has %v of %v", core.ErrIncompleteMultisig, partial.Signed(), partial.Multisig.Threshold)
+	}
+
+	encoded, err := jsonrpc.NewPartialTransaction(partial)
+	if err != nil {
+		return err
+	}
+
+	chain, err := connect(ctx, cfg, *remote)
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+
+	var result jsonrpc.AddBlockResult
+	if err := chain.Call(ctx, "API.SubmitPartialTransaction", &jsonrpc.SubmitPartialTransactionArgs{Partial: encoded}, &result); err != nil {
+		return err
+	}
+
+	return printJSON(result)
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/node"
+)
+
+var nodeCommand = &Command{
+	Name:    "node",
+	Summary: "Run an Essensio node",
+	Subcommands: []*Command{
+		{Name: "run", Summary: "Serve the JSON-RPC API for the chain in the data directory", Action: nodeRun},
+	},
+}
+
+// nodeRun starts a node and runs it until it is shut down by a signal
+func nodeRun(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Effective Configuration:\n%v\n", cfg)
+
+	// Start the node
+	essensio, err := node.New(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to start node: %w", err)
+	}
+
+	// Run the node until it is shut down
+	if err := essensio.Run(ctx); err != nil {
+		return &exitError{ExitRuntime, fmt.Errorf("node failed: %w", err)}
+	}
+
+	return nil
+}
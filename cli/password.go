@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// passwordFileFlag registers a flag for a file that contains a password on the FlagSet
+func passwordFileFlag(fs *flag.FlagSet, name, usage string) *string {
+	return fs.String(name, "", fmt.Sprintf("file containing the %v (prompted for if not given)", usage))
+}
+
+// readPassword returns the password from the first line of the given file. If no file is given,
+// the password is prompted for on the terminal and is entered twice if confirm is set.
+func readPassword(file, prompt string, confirm bool) (string, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("password file read failed: %w", err)
+		}
+
+		line, _, _ := strings.Cut(string(data), "\n")
+		return strings.TrimSuffix(line, "\r"), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("cannot prompt for the %v without a terminal, use a password file", prompt)
+	}
+
+	fmt.Fprintf(os.Stderr, "Enter %v: ", prompt)
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("password read failed: %w", err)
+	}
+
+	if confirm {
+		fmt.Fprintf(os.Stderr, "Repeat %v: ", prompt)
+		repeated, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("password read failed: %w", err)
+		}
+
+		if string(repeated) != string(password) {
+			return "", fmt.Errorf("passwords do not match")
+		}
+	}
+
+	return string(password), nil
+}
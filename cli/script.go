@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core/script"
+)
+
+var scriptCommand = &Command{
+	Name:    "script",
+	Summary: "Assemble and decode the lock scripts of script addresses",
+	Subcommands: []*Command{
+		{Name: "address", Summary: "Assemble a lock script and print its address", Action: scriptAddress},
+		{Name: "decode", Summary: "Decode a hex encoded lock script and print its address", Action: scriptDecode},
+	},
+}
+
+// scriptResult is a lock script printed by the script commands
+type scriptResult struct {
+	Address  string `json:"address"`
+	Script   string `json:"script"`
+	Assembly string `json:"assembly"`
+}
+
+// newScriptResult converts a lock script into a scriptResult
+func newScriptResult(lock []byte) (scriptResult, error) {
+	assembly, err := script.Disassemble(lock)
+	if err != nil {
+		return scriptResult{}, err
+	}
+
+	return scriptResult{Address: string(script.Address(lock)), Script: common.HexEncode(lock), Assembly: assembly}, nil
+}
+
+// scriptAddress assembles a lock script and prints its address along with its hex encoding
+func scriptAddress(_ context.Context, fs *flag.FlagSet, args []string) error {
+	assembly := fs.String("script", "", "lock script, such as '0x<public key> CHECKSIG'")
+
+	if _, err := loadConfig(fs, args); err != nil {
+		return err
+	}
+
+	if *assembly == "" {
+		return fmt.Errorf("-script is required")
+	}
+
+	lock, err := script.Assemble(*assembly)
+	if err != nil {
+		return err
+	}
+
+	result, err := newScriptResult(lock)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(result)
+}
+
+// scriptDecode decodes a hex encoded lock script and prints its address along with its assembly
+func scriptDecode(_ context.Context, fs *flag.FlagSet, args []string) error {
+	encoded := fs.String("hex", "", "hex encoded lock script")
+
+	if _, err := loadConfig(fs, args); err != nil {
+		return err
+	}
+
+	lock, err := common.HexDecode(*encoded)
+	if err != nil {
+		return fmt.Errorf("invalid lock script: %w", err)
+	}
+
+	result, err := newScriptResult(lock)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(result)
+}
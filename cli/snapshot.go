@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/manishmeganathan/essensio/config"
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+)
+
+var snapshotCommand = &Command{
+	Name:    "snapshot",
+	Summary: "Create, list, verify and restore state snapshots in the data directory",
+	Subcommands: []*Command{
+		{Name: "create", Summary: "Create a state snapshot of the chain in the data directory", Action: snapshotCreate},
+		{Name: "list", Summary: "List the state snapshots in the data directory", Action: snapshotList},
+		{Name: "verify", Summary: "Verify the headers and account commitment of a state snapshot", Action: snapshotVerify},
+		{Name: "restore", Summary: "Initialize the chain in the data directory from a state snapshot", Action: snapshotRestore},
+	},
+}
+
+// snapshotResult is a state snapshot printed by the snapshot commands
+type snapshotResult struct {
+	Path        string `json:"path"`
+	ChainID     uint64 `json:"chain_id"`
+	ChainHeight uint64 `json:"chain_height"`
+	ChainHead   string `json:"chain_head"`
+	Commitment  string `json:"commitment"`
+	Accounts    int64  `json:"accounts"`
+	Outputs     int64  `json:"outputs"`
+}
+
+// newSnapshotResult converts the SnapshotInfo of the state snapshot at the given path into a snapshotResult
+func newSnapshotResult(path string, info *chainmgr.SnapshotInfo) snapshotResult {
+	return snapshotResult{
+		Path:        path,
+		ChainID:     info.Params.ChainID,
+		ChainHeight: uint64(info.Height),
+		ChainHead:   info.Head.Hex(),
+		Commitment:  info.Root.Hex(),
+		Accounts:    info.Accounts,
+		Outputs:     info.Coins,
+	}
+}
+
+// snapshotFile returns the path of the state snapshot given by the -file or -height flags
+func snapshotFile(cfg *config.Config, file string, height optionalUint64) (string, error) {
+	switch {
+	case file != "" && height.value != nil:
+		return "", fmt.Errorf("-file and -height cannot both be given")
+	case file != "":
+		return file, nil
+	case height.value != nil:
+		return chainmgr.SnapshotPath(cfg.DataDir, int64(*height.value)), nil
+	default:
+		return "", fmt.Errorf("-file or -height is required")
+	}
+}
+
+// openSnapshot opens the state snapshot at the given path and reads its SnapshotInfo
+func openSnapshot(path string) (*os.File, *chainmgr.SnapshotReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("snapshot file open failed: %w", err)
+	}
+
+	reader, err := chainmgr.NewSnapshotReader(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, fmt.Errorf("%v: %w", path, err)
+	}
+
+	return file, reader, nil
+}
+
+// snapshotCreate creates a state snapshot of the chain in the local data directory
+func snapshotCreate(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	chain, err := openChain(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer chain.Stop()
+
+	path, info, err := chain.CreateSnapshot()
+	if err != nil {
+		return err
+	}
+
+	return printJSON(newSnapshotResult(path, info))
+}
+
+// snapshotList prints the state snapshots in the local data directory
+func snapshotList(_ context.Context, fs *flag.FlagSet, args []string) error {
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	paths, err := chainmgr.ListSnapshots(cfg.DataDir)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "HEIGHT\tCHAIN HEAD\tCOMMITMENT\tACCOUNTS\tOUTPUTS\tFILE")
+	for _, path := range paths {
+		file, reader, err := openSnapshot(path)
+		if err != nil {
+			return err
+		}
+
+		info := reader.Info()
+		_ = file.Close()
+
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\t%v\n", info.Height, info.Head.Hex(), info.Root.Hex(), info.Accounts, info.Coins, filepath.Base(path))
+	}
+
+	return writer.Flush()
+}
+
+// snapshotVerify verifies a state snapshot
+func snapshotVerify(_ context.Context, fs *flag.FlagSet, args []string) error {
+	file := fs.String("file", "", "path of the snapshot to verify")
+	var height optionalUint64
+	fs.Var(&height, "height", "chain height of the snapshot in the data directory to verify")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	path, err := snapshotFile(cfg, *file, height)
+	if err != nil {
+		return err
+	}
+
+	snapshot, reader, err := openSnapshot(path)
+	if err != nil {
+		return err
+	}
+	defer snapshot.Close()
+
+	if err := reader.Verify(nil, nil, nil); err != nil {
+		return err
+	}
+
+	info := reader.Info()
+	fmt.Printf("Verified snapshot of %v blocks, %v accounts and %v unspent outputs with commitment %v\n", info.Height, info.Accounts, info.Coins, info.Root.Hex())
+	return nil
+}
+
+// snapshotRestore initializes the chain in the local data directory from a state snapshot
+func snapshotRestore(_ context.Context, fs *flag.FlagSet, args []string) error {
+	file := fs.String("file", "", "path of the snapshot to restore")
+	var height optionalUint64
+	fs.Var(&height, "height", "chain height of the snapshot in the data directory to restore")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	path, err := snapshotFile(cfg, *file, height)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("snapshot file open failed: %w", err)
+	}
+	defer snapshot.Close()
+
+	info, err := chainmgr.RestoreSnapshot(cfg.DataDir, snapshot)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(newSnapshotResult(path, info))
+}
@@ -0,0 +1,555 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/config"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/script"
+	"github.com/manishmeganathan/essensio/jsonrpc"
+	"github.com/manishmeganathan/essensio/keystore"
+)
+
+var walletCommand = &Command{
+	Name:    "wallet",
+	Summary: "Manage the accounts in the keystore directory",
+	Subcommands: []*Command{
+		{Name: "new", Summary: "Create a new account", Action: walletNew},
+		{Name: "list", Summary: "List the accounts in the keystore", Action: walletList},
+		{Name: "send", Summary: "Send tokens from an account in the keystore or a script address", Action: walletSend},
+		{Name: "public-key", Summary: "Print the public key of an account in the keystore", Action: walletPublicKey},
+		{Name: "import", Summary: "Import an account from an exported key file", Action: walletImport},
+		{Name: "export", Summary: "Export an account to an encrypted key file", Action: walletExport},
+		{Name: "passwd", Summary: "Change the password of an account", Action: walletPasswd},
+		{Name: "unlock", Summary: "Unlock an account in the keystore of a running node", Action: walletUnlock},
+		{Name: "lock", Summary: "Lock an account in the keystore of a running node", Action: walletLock},
+		hdCommand,
+		multisigCommand,
+	},
+}
+
+// openKeystore opens the keystore in the keystore directory
+func openKeystore(cfg *config.Config) (*keystore.Keystore, error) {
+	return keystore.New(cfg.KeystoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+}
+
+// walletNew creates a new account in the keystore and prints its address
+func walletNew(_ context.Context, fs *flag.FlagSet, args []string) error {
+	passwordFile := passwordFileFlag(fs, "password-file", "password of the account")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	keys, err := openKeystore(cfg)
+	if err != nil {
+		return err
+	}
+
+	password, err := readPassword(*passwordFile, "password", true)
+	if err != nil {
+		return err
+	}
+
+	address, err := keys.Create(password)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(address)
+	return nil
+}
+
+// walletList prints the accounts in the keystore, optionally with their balances and nonces
+func walletList(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	balances := fs.Bool("balances", false, "show the balance and nonce of each account")
+	remote := remoteFlag(fs)
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	keys, err := openKeystore(cfg)
+	if err != nil {
+		return err
+	}
+
+	addresses, err := keys.Accounts()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer writer.Flush()
+
+	if !*balances {
+		fmt.Fprintln(writer, "ADDRESS")
+		for _, address := range addresses {
+			fmt.Fprintln(writer, address)
+		}
+
+		return nil
+	}
+
+	chain, err := connect(ctx, cfg, *remote)
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+
+	fmt.Fprintln(writer, "ADDRESS\tBALANCE\tNONCE")
+	for _, address := range addresses {
+		args := &jsonrpc.AccountArgs{Address: string(address)}
+
+		var balance jsonrpc.GetBalanceResult
+		if err := chain.Call(ctx, "API.GetBalance", args, &balance); err != nil {
+			return err
+		}
+
+		var nonce jsonrpc.GetNonceResult
+		if err := chain.Call(ctx, "API.GetNonce", args, &nonce); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(writer, "%v\t%v\t%v\n", address, balance.Balance, nonce.Nonce)
+	}
+
+	return nil
+}
+
+// walletSend mines a block with a transaction from an account in the keystore. The transaction
+// is signed locally with the password of the account, unless the node is asked to sign it
+// with the account unlocked in its own keystore. A transaction from a script address instead
+// reveals its lock script along with a witness, whose signatures are made with the keystore.
+func walletSend(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	from := fs.String("from", "", "address of the sending account, or the script address of -script if not given")
+	to := fs.String("to", "", "address of the receiver")
+	var value, fee common.Amount
+	fs.Var(&value, "value", "amount of tokens to send, such as '1.25 Essence' (in Nubs without a unit)")
+	fs.Var(&fee, "fee", "fee paid to the miner, such as '300 Pith' (in Nubs without a unit)")
+	nodeSign := fs.Bool("node-sign", false, "have the node sign with the account unlocked in its keystore (requires -remote)")
+	lock := fs.String("script", "", "lock script of a sending script address")
+	witness := fs.String("witness", "", "witness that satisfies the lock script, in which 'sig:<address>' is signed by the keystore")
+	passwordFile := passwordFileFlag(fs, "password-file", "password of the sending or signing accounts")
+	remote := remoteFlag(fs)
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *to == "" || value == 0 {
+		return fmt.Errorf("-to and a non-zero -value are required")
+	}
+
+	if *nodeSign && (*remote == "" || *lock != "") {
+		return fmt.Errorf("-node-sign requires -remote and cannot be used with -script")
+	}
+
+	input := jsonrpc.TransactionInput{From: *from, To: *to, Value: value, Fee: fee}
+
+	chain, err := connect(ctx, cfg, *remote)
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+
+	switch {
+	case *lock != "":
+		if err := scriptInput(ctx, cfg, chain, &input, *lock, *witness, *passwordFile); err != nil {
+			return err
+		}
+
+	case !*nodeSign:
+		if err := signInput(ctx, cfg, chain, &input, *passwordFile); err != nil {
+			return err
+		}
+	}
+
+	request := &jsonrpc.AddBlockArgs{Transactions: []jsonrpc.TransactionInput{input}}
+
+	var result jsonrpc.AddBlockResult
+	if err := chain.Call(ctx, "API.AddBlock", request, &result); err != nil {
+		return err
+	}
+
+	return printJSON(result)
+}
+
+// signInput signs the transaction input with the key of its sender in the keystore (see newInputTransaction)
+func signInput(ctx context.Context, cfg *config.Config, chain backend, input *jsonrpc.TransactionInput, passwordFile string) error {
+	keys, err := openKeystore(cfg)
+	if err != nil {
+		return err
+	}
+
+	if !keys.Has(common.Address(input.From)) {
+		return fmt.Errorf("%w: %v", keystore.ErrAccountNotFound, input.From)
+	}
+
+	txn, err := newInputTransaction(ctx, chain, input)
+	if err != nil {
+		return err
+	}
+
+	password, err := readPassword(passwordFile, "password", false)
+	if err != nil {
+		return err
+	}
+
+	if err := keys.SignTransactionWithPassword(txn, password); err != nil {
+		return err
+	}
+
+	setInput(input, txn)
+	input.PublicKey = common.HexEncode(txn.PublicKey)
+	input.Signature = common.HexEncode(txn.Signature)
+
+	return nil
+}
+
+// scriptInput sets the assembled lock script and witness on the transaction input from the address of the lock
+// script (see newInputTransaction). Each 'sig:<address>' in the witness is replaced with the signature of the key
+// for that address in the keystore, which are all decrypted with the same password.
+func scriptInput(ctx context.Context, cfg *config.Config, chain backend, input *jsonrpc.TransactionInput, lock, witness, passwordFile string) error {
+	lockScript, err := script.Assemble(lock)
+	if err != nil {
+		return fmt.Errorf("lock script: %w", err)
+	}
+
+	address := script.Address(lockScript)
+	if input.From == "" {
+		input.From = string(address)
+	} else if common.Address(input.From) != address {
+		return fmt.Errorf("lock script belongs to '%v', not the sender '%v'", address, input.From)
+	}
+
+	txn, err := newInputTransaction(ctx, chain, input)
+	if err != nil {
+		return err
+	}
+
+	txn.Script = lockScript
+
+	// Replace the signatures in the witness, which sign the transaction with its lock script
+	tokens := strings.Fields(witness)
+	var keys *keystore.Keystore
+	var password string
+
+	for idx, token := range tokens {
+		if !strings.HasPrefix(token, "sig:") {
+			continue
+		}
+
+		signer := strings.TrimPrefix(token, "sig:")
+
+		if keys == nil {
+			if keys, err = openKeystore(cfg); err != nil {
+				return err
+			}
+
+			if password, err = readPassword(passwordFile, "password", false); err != nil {
+				return err
+			}
+		}
+
+		signature, err := keys.SignScriptWithPassword(txn, common.Address(signer), password)
+		if err != nil {
+			return fmt.Errorf("signature of '%v': %w", signer, err)
+		}
+
+		tokens[idx] = common.HexEncode(signature)
+	}
+
+	if txn.Witness, err = script.Assemble(strings.Join(tokens, " ")); err != nil {
+		return fmt.Errorf("witness: %w", err)
+	}
+
+	setInput(input, txn)
+	input.Script = common.HexEncode(txn.Script)
+	input.Witness = common.HexEncode(txn.Witness)
+
+	return nil
+}
+
+// newInputTransaction returns the unsigned core.Transaction of the transaction input for the chain ID of the node
+// with the next nonce of the sender. On a chain with the UTXO ledger, the transaction instead spends unspent outputs
+// of the sender selected by core.NewTransfer.
+func newInputTransaction(ctx context.Context, chain backend, input *jsonrpc.TransactionInput) (*core.Transaction, error) {
+	var info jsonrpc.GetChainInfoResult
+	if err := chain.Call(ctx, "API.GetChainInfo", &jsonrpc.GetChainInfoArgs{}, &info); err != nil {
+		return nil, err
+	}
+
+	if info.Ledger == core.LedgerUTXO {
+		var unspent jsonrpc.GetUnspentOutputsResult
+		if err := chain.Call(ctx, "API.GetUnspentOutputs", &jsonrpc.GetUnspentOutputsArgs{Address: input.From}, &unspent); err != nil {
+			return nil, err
+		}
+
+		coins, err := unspent.Coins()
+		if err != nil {
+			return nil, err
+		}
+
+		return core.NewTransfer(info.ChainID, common.Address(input.From), common.Address(input.To), input.Value, input.Fee, coins)
+	}
+
+	var nonce jsonrpc.GetNonceResult
+	if err := chain.Call(ctx, "API.GetNonce", &jsonrpc.AccountArgs{Address: input.From}, &nonce); err != nil {
+		return nil, err
+	}
+
+	return core.NewTransaction(info.ChainID, common.Address(input.From), common.Address(input.To), nonce.Nonce, input.Value, input.Fee), nil
+}
+
+// setInput sets the chain ID and nonce of the core.Transaction on the transaction input.
+// The input of a Transaction on a chain with the UTXO ledger is replaced by its inputs and outputs.
+func setInput(input *jsonrpc.TransactionInput, txn *core.Transaction) {
+	if txn.IsUTXO() {
+		input.To, input.Value = "", 0
+		input.Inputs = jsonrpc.NewTxInputs(txn.Inputs)
+		input.Outputs = jsonrpc.NewTxOutputs(txn.Outputs)
+	} else {
+		input.Nonce = &txn.Nonce
+	}
+
+	input.ChainID = &txn.ChainID
+}
+
+// walletPublicKey prints the public key of an account in the keystore, which is needed to lock scripts to it
+func walletPublicKey(_ context.Context, fs *flag.FlagSet, args []string) error {
+	address := fs.String("address", "", "address of the account")
+	passwordFile := passwordFileFlag(fs, "password-file", "password of the account")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	keys, err := openKeystore(cfg)
+	if err != nil {
+		return err
+	}
+
+	if !keys.Has(common.Address(*address)) {
+		return fmt.Errorf("%w: %v", keystore.ErrAccountNotFound, *address)
+	}
+
+	password, err := readPassword(*passwordFile, "password", false)
+	if err != nil {
+		return err
+	}
+
+	publicKey, err := keys.PublicKey(common.Address(*address), password)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(common.HexEncode(publicKey))
+	return nil
+}
+
+// walletImport imports an account from an exported key file into the keystore
+func walletImport(_ context.Context, fs *flag.FlagSet, args []string) error {
+	path := fs.String("file", "", "path of the exported key file")
+	passwordFile := passwordFileFlag(fs, "password-file", "password of the exported key file")
+	newPasswordFile := passwordFileFlag(fs, "new-password-file", "password of the imported account")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *path == "" {
+		return fmt.Errorf("-file is required")
+	}
+
+	keyJSON, err := os.ReadFile(*path)
+	if err != nil {
+		return fmt.Errorf("key file read failed: %w", err)
+	}
+
+	keys, err := openKeystore(cfg)
+	if err != nil {
+		return err
+	}
+
+	password, err := readPassword(*passwordFile, "password of the key file", false)
+	if err != nil {
+		return err
+	}
+
+	newPassword, err := readPassword(*newPasswordFile, "new password", true)
+	if err != nil {
+		return err
+	}
+
+	address, err := keys.Import(keyJSON, password, newPassword)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(address)
+	return nil
+}
+
+// walletExport writes an account in the keystore to a key file encrypted with a new password
+func walletExport(_ context.Context, fs *flag.FlagSet, args []string) error {
+	address := fs.String("address", "", "address of the account")
+	path := fs.String("file", "", "path of the key file to export to")
+	passwordFile := passwordFileFlag(fs, "password-file", "password of the account")
+	newPasswordFile := passwordFileFlag(fs, "new-password-file", "password of the exported key file")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *path == "" {
+		return fmt.Errorf("-file is required")
+	}
+
+	keys, err := openKeystore(cfg)
+	if err != nil {
+		return err
+	}
+
+	if !keys.Has(common.Address(*address)) {
+		return fmt.Errorf("%w: %v", keystore.ErrAccountNotFound, *address)
+	}
+
+	password, err := readPassword(*passwordFile, "password", false)
+	if err != nil {
+		return err
+	}
+
+	newPassword, err := readPassword(*newPasswordFile, "password of the key file", true)
+	if err != nil {
+		return err
+	}
+
+	keyJSON, err := keys.Export(common.Address(*address), password, newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(*path, keyJSON, 0o600); err != nil {
+		return fmt.Errorf("key file write failed: %w", err)
+	}
+
+	fmt.Printf("Exported %v to %v\n", *address, *path)
+	return nil
+}
+
+// walletPasswd changes the password of an account in the keystore
+func walletPasswd(_ context.Context, fs *flag.FlagSet, args []string) error {
+	address := fs.String("address", "", "address of the account")
+	passwordFile := passwordFileFlag(fs, "password-file", "current password of the account")
+	newPasswordFile := passwordFileFlag(fs, "new-password-file", "new password of the account")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	keys, err := openKeystore(cfg)
+	if err != nil {
+		return err
+	}
+
+	if !keys.Has(common.Address(*address)) {
+		return fmt.Errorf("%w: %v", keystore.ErrAccountNotFound, *address)
+	}
+
+	password, err := readPassword(*passwordFile, "current password", false)
+	if err != nil {
+		return err
+	}
+
+	newPassword, err := readPassword(*newPasswordFile, "new password", true)
+	if err != nil {
+		return err
+	}
+
+	if err := keys.Update(common.Address(*address), password, newPassword); err != nil {
+		return err
+	}
+
+	fmt.Printf("Changed the password of %v\n", *address)
+	return nil
+}
+
+// walletUnlock unlocks an account in the keystore of a running node so that the node signs its transactions
+func walletUnlock(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	address := fs.String("address", "", "address of the account")
+	duration := fs.Duration("duration", 5*time.Minute, "time to keep the account unlocked, 0 until it is locked")
+	passwordFile := passwordFileFlag(fs, "password-file", "password of the account")
+	remote := remoteFlag(fs)
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *remote == "" {
+		return fmt.Errorf("-remote is required to unlock an account on a node")
+	}
+
+	password, err := readPassword(*passwordFile, "password", false)
+	if err != nil {
+		return err
+	}
+
+	chain, err := connect(ctx, cfg, *remote)
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+
+	request := &jsonrpc.UnlockAccountArgs{Address: *address, Password: password, Duration: uint64(duration.Seconds())}
+
+	var result jsonrpc.KeystoreAccount
+	if err := chain.Call(ctx, "API.UnlockAccount", request, &result); err != nil {
+		return err
+	}
+
+	return printJSON(result)
+}
+
+// walletLock locks an account in the keystore of a running node
+func walletLock(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	address := fs.String("address", "", "address of the account")
+	remote := remoteFlag(fs)
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *remote == "" {
+		return fmt.Errorf("-remote is required to lock an account on a node")
+	}
+
+	chain, err := connect(ctx, cfg, *remote)
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+
+	var result jsonrpc.KeystoreAccount
+	if err := chain.Call(ctx, "API.LockAccount", &jsonrpc.LockAccountArgs{Address: *address}, &result); err != nil {
+		return err
+	}
+
+	return printJSON(result)
+}
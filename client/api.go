@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+
+	"github.com/manishmeganathan/essensio/jsonrpc"
+)
+
+// AddBlock calls API.AddBlock, which mines a block with the given transactions.
+// It does not return until the block has been mined and committed.
+func (client *Client) AddBlock(ctx context.Context, args *jsonrpc.AddBlockArgs) (*jsonrpc.AddBlockResult, error) {
+	result := new(jsonrpc.AddBlockResult)
+	if err := client.Call(ctx, "API.AddBlock", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ShowChain calls API.ShowChain, which returns a page of blocks from the chain
+func (client *Client) ShowChain(ctx context.Context, args *jsonrpc.ShowChainArgs) (*jsonrpc.ShowChainResult, error) {
+	result := new(jsonrpc.ShowChainResult)
+	if err := client.Call(ctx, "API.ShowChain", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetBlockByHash calls API.GetBlockByHash, which returns the block with the given hash
+func (client *Client) GetBlockByHash(ctx context.Context, args *jsonrpc.GetBlockByHashArgs) (*jsonrpc.ChainBlock, error) {
+	result := new(jsonrpc.ChainBlock)
+	if err := client.Call(ctx, "API.GetBlockByHash", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetBlockByHeight calls API.GetBlockByHeight, which returns the block at the given height
+func (client *Client) GetBlockByHeight(ctx context.Context, args *jsonrpc.GetBlockByHeightArgs) (*jsonrpc.ChainBlock, error) {
+	result := new(jsonrpc.ChainBlock)
+	if err := client.Call(ctx, "API.GetBlockByHeight", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetLatestBlock calls API.GetLatestBlock, which returns the block at the chain head
+func (client *Client) GetLatestBlock(ctx context.Context, args *jsonrpc.GetLatestBlockArgs) (*jsonrpc.ChainBlock, error) {
+	result := new(jsonrpc.ChainBlock)
+	if err := client.Call(ctx, "API.GetLatestBlock", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetTransactionByHash calls API.GetTransactionByHash, which returns the transaction with the given hash
+func (client *Client) GetTransactionByHash(ctx context.Context, args *jsonrpc.GetTransactionByHashArgs) (*jsonrpc.GetTransactionByHashResult, error) {
+	result := new(jsonrpc.GetTransactionByHashResult)
+	if err := client.Call(ctx, "API.GetTransactionByHash", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetTransactionProof calls API.GetTransactionProof, which returns the transaction with the
+// given hash and the Merkle proof of its inclusion in the summary of its block
+func (client *Client) GetTransactionProof(ctx context.Context, args *jsonrpc.GetTransactionProofArgs) (*jsonrpc.GetTransactionProofResult, error) {
+	result := new(jsonrpc.GetTransactionProofResult)
+	if err := client.Call(ctx, "API.GetTransactionProof", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetAccountProof calls API.GetAccountProof, which returns the state of an address and its
+// proof against the state root of the chain head, or of the block given by the args
+func (client *Client) GetAccountProof(ctx context.Context, args *jsonrpc.AccountArgs) (*jsonrpc.GetAccountProofResult, error) {
+	result := new(jsonrpc.GetAccountProofResult)
+	if err := client.Call(ctx, "API.GetAccountProof", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetBalance calls API.GetBalance, which returns the balance of an address
+func (client *Client) GetBalance(ctx context.Context, args *jsonrpc.AccountArgs) (*jsonrpc.GetBalanceResult, error) {
+	result := new(jsonrpc.GetBalanceResult)
+	if err := client.Call(ctx, "API.GetBalance", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetNonce calls API.GetNonce, which returns the next nonce of an address
+func (client *Client) GetNonce(ctx context.Context, args *jsonrpc.AccountArgs) (*jsonrpc.GetNonceResult, error) {
+	result := new(jsonrpc.GetNonceResult)
+	if err := client.Call(ctx, "API.GetNonce", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetUnspentOutputs calls API.GetUnspentOutputs, which returns the unspent outputs locked to an address
+func (client *Client) GetUnspentOutputs(ctx context.Context, args *jsonrpc.GetUnspentOutputsArgs) (*jsonrpc.GetUnspentOutputsResult, error) {
+	result := new(jsonrpc.GetUnspentOutputsResult)
+	if err := client.Call(ctx, "API.GetUnspentOutputs", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetChainInfo calls API.GetChainInfo, which returns a summary of the chain
+func (client *Client) GetChainInfo(ctx context.Context) (*jsonrpc.GetChainInfoResult, error) {
+	result := new(jsonrpc.GetChainInfoResult)
+	if err := client.Call(ctx, "API.GetChainInfo", &jsonrpc.GetChainInfoArgs{}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetSupply calls API.GetSupply, which returns the circulating and maximum supply of the chain
+func (client *Client) GetSupply(ctx context.Context) (*jsonrpc.GetSupplyResult, error) {
+	result := new(jsonrpc.GetSupplyResult)
+	if err := client.Call(ctx, "API.GetSupply", &jsonrpc.GetSupplyArgs{}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListAccounts calls API.ListAccounts, which returns the accounts in the keystore of the node
+func (client *Client) ListAccounts(ctx context.Context) (*jsonrpc.ListAccountsResult, error) {
+	result := new(jsonrpc.ListAccountsResult)
+	if err := client.Call(ctx, "API.ListAccounts", &jsonrpc.ListAccountsArgs{}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UnlockAccount calls API.UnlockAccount, which unlocks an account in the keystore of the node
+// so that the node signs its transactions. The password is sent to the node, never the key.
+func (client *Client) UnlockAccount(ctx context.Context, args *jsonrpc.UnlockAccountArgs) (*jsonrpc.KeystoreAccount, error) {
+	result := new(jsonrpc.KeystoreAccount)
+	if err := client.Call(ctx, "API.UnlockAccount", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// LockAccount calls API.LockAccount, which locks an account in the keystore of the node
+func (client *Client) LockAccount(ctx context.Context, args *jsonrpc.LockAccountArgs) (*jsonrpc.KeystoreAccount, error) {
+	result := new(jsonrpc.KeystoreAccount)
+	if err := client.Call(ctx, "API.LockAccount", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CreateMultisig calls API.CreateMultisig, which returns the address and lock script of a multisig account
+func (client *Client) CreateMultisig(ctx context.Context, args *jsonrpc.CreateMultisigArgs) (*jsonrpc.MultisigAccount, error) {
+	result := new(jsonrpc.MultisigAccount)
+	if err := client.Call(ctx, "API.CreateMultisig", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CreatePartialTransaction calls API.CreatePartialTransaction, which returns an unsigned
+// transaction from a multisig account for its participants to sign
+func (client *Client) CreatePartialTransaction(ctx context.Context, args *jsonrpc.CreatePartialTransactionArgs) (*jsonrpc.PartialTransaction, error) {
+	result := new(jsonrpc.PartialTransaction)
+	if err := client.Call(ctx, "API.CreatePartialTransaction", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SignPartialTransaction calls API.SignPartialTransaction, which signs a transaction from a multisig
+// account with the key of a participant that is unlocked in the keystore of the node
+func (client *Client) SignPartialTransaction(ctx context.Context, args *jsonrpc.SignPartialTransactionArgs) (*jsonrpc.PartialTransaction, error) {
+	result := new(jsonrpc.PartialTransaction)
+	if err := client.Call(ctx, "API.SignPartialTransaction", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SubmitPartialTransaction calls API.SubmitPartialTransaction, which mines a block with a transaction
+// from a multisig account once it has been signed by a threshold of its participants
+func (client *Client) SubmitPartialTransaction(ctx context.Context, args *jsonrpc.SubmitPartialTransactionArgs) (*jsonrpc.AddBlockResult, error) {
+	result := new(jsonrpc.AddBlockResult)
+	if err := client.Call(ctx, "API.SubmitPartialTransaction", args, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,246 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/manishmeganathan/essensio/jsonrpc"
+)
+
+const (
+	// DefaultTimeout is the default timeout for a single HTTP round trip.
+	// It is generous because AddBlock does not return until the block is mined.
+	DefaultTimeout = 2 * time.Minute
+
+	// DefaultRPCPath is the default path of the JSON-RPC endpoint of a node
+	DefaultRPCPath = "/rpc"
+	// DefaultWSPath is the default path of the WebSocket endpoint of a node
+	DefaultWSPath = "/ws"
+)
+
+// Client is a client for the Essensio JSON-RPC API of a node.
+// Requests are sent over HTTP and subscriptions are made over a WebSocket
+// connection that is established when the first subscription is made.
+type Client struct {
+	// Represents the base URL of the node
+	baseURL string
+	// Represents the paths of the JSON-RPC and WebSocket endpoints
+	rpcPath, wsPath string
+
+	// Represents the HTTP client used for requests
+	httpClient *http.Client
+	// Represents the counter used for request IDs
+	nextID uint64
+
+	// Represents the WebSocket connection for subscriptions
+	mutex sync.Mutex
+	ws    *wsConn
+}
+
+// Option is a function that configures a Client
+type Option func(*Client)
+
+// WithTimeout sets the timeout for each HTTP round trip
+func WithTimeout(timeout time.Duration) Option {
+	return func(client *Client) { client.httpClient.Timeout = timeout }
+}
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(client *Client) { client.httpClient = httpClient }
+}
+
+// WithPaths sets the paths of the JSON-RPC and WebSocket endpoints
+func WithPaths(rpcPath, wsPath string) Option {
+	return func(client *Client) { client.rpcPath, client.wsPath = rpcPath, wsPath }
+}
+
+// New returns a new Client for the node at the given base URL (e.g. "http://localhost:8080")
+func New(url string, options ...Option) *Client {
+	client := &Client{
+		baseURL:    strings.TrimSuffix(url, "/"),
+		rpcPath:    DefaultRPCPath,
+		wsPath:     DefaultWSPath,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+	}
+
+	for _, option := range options {
+		option(client)
+	}
+
+	return client
+}
+
+// rpcURL returns the URL of the JSON-RPC endpoint
+func (client *Client) rpcURL() string {
+	return client.baseURL + client.rpcPath
+}
+
+// wsURL returns the URL of the WebSocket endpoint.
+// The scheme of the base URL is converted into its WebSocket equivalent.
+func (client *Client) wsURL() string {
+	switch {
+	case strings.HasPrefix(client.baseURL, "https://"):
+		return "wss://" + strings.TrimPrefix(client.baseURL, "https://") + client.wsPath
+	case strings.HasPrefix(client.baseURL, "http://"):
+		return "ws://" + strings.TrimPrefix(client.baseURL, "http://") + client.wsPath
+	default:
+		return client.baseURL + client.wsPath
+	}
+}
+
+// Close closes the WebSocket connection of the Client, ending all subscriptions
+func (client *Client) Close() {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	if client.ws != nil {
+		client.ws.close(ErrClientClosed)
+		client.ws = nil
+	}
+}
+
+// response is a JSON-RPC 2.0 response with an undecoded result
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result"`
+	Error   *jsonrpc.Error  `json:"error"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// newRequest returns a new JSON-RPC request for the given method and args
+func (client *Client) newRequest(method string, args any) (*jsonrpc.Request, error) {
+	params, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("params encode failed: %w", err)
+	}
+
+	id := atomic.AddUint64(&client.nextID, 1)
+	return &jsonrpc.Request{
+		JSONRPC: jsonrpc.Version,
+		Method:  method,
+		Params:  params,
+		ID:      json.RawMessage(fmt.Sprintf("%d", id)),
+	}, nil
+}
+
+// post sends the JSON encoding of body to the JSON-RPC endpoint and decodes the response into reply
+func (client *Client) post(ctx context.Context, body any, reply any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("request encode failed: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, client.rpcURL(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("http status %v: %s", resp.Status, bytes.TrimSpace(message))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(reply); err != nil {
+		return fmt.Errorf("response decode failed: %w", err)
+	}
+
+	return nil
+}
+
+// Call calls the given API method with args and decodes its result into reply.
+// The method is given in the "Service.Method" form (e.g. "API.GetBalance").
+// If the node returns an error, it is returned as a *jsonrpc.Error.
+func (client *Client) Call(ctx context.Context, method string, args, reply any) error {
+	request, err := client.newRequest(method, args)
+	if err != nil {
+		return err
+	}
+
+	var resp response
+	if err := client.post(ctx, request, &resp); err != nil {
+		return fmt.Errorf("call '%v' failed: %w", method, err)
+	}
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	if err := json.Unmarshal(resp.Result, reply); err != nil {
+		return fmt.Errorf("result decode failed: %w", err)
+	}
+
+	return nil
+}
+
+// BatchElem is a single call within a batch
+type BatchElem struct {
+	Method string
+	Args   any
+	// Reply is decoded from the result of the call
+	Reply any
+	// Error is set if the call failed
+	Error error
+}
+
+// BatchCall sends all the given calls in a single request.
+// An error is only returned if the batch as a whole fails,
+// errors of individual calls are set on their BatchElem.
+func (client *Client) BatchCall(ctx context.Context, batch []BatchElem) error {
+	requests := make([]*jsonrpc.Request, 0, len(batch))
+	elems := make(map[string]*BatchElem, len(batch))
+
+	for idx := range batch {
+		request, err := client.newRequest(batch[idx].Method, batch[idx].Args)
+		if err != nil {
+			return err
+		}
+
+		requests = append(requests, request)
+		elems[string(request.ID)] = &batch[idx]
+	}
+
+	var responses []response
+	if err := client.post(ctx, requests, &responses); err != nil {
+		return fmt.Errorf("batch call failed: %w", err)
+	}
+
+	for _, resp := range responses {
+		elem, ok := elems[string(resp.ID)]
+		if !ok {
+			continue
+		}
+		delete(elems, string(resp.ID))
+
+		if resp.Error != nil {
+			elem.Error = resp.Error
+			continue
+		}
+
+		if err := json.Unmarshal(resp.Result, elem.Reply); err != nil {
+			elem.Error = fmt.Errorf("result decode failed: %w", err)
+		}
+	}
+
+	// Every call must have a response
+	for _, elem := range elems {
+		elem.Error = fmt.Errorf("no response for call '%v'", elem.Method)
+	}
+
+	return nil
+}
@@ -0,0 +1,627 @@
+package client
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+	"github.com/manishmeganathan/essensio/jsonrpc"
+	"github.com/manishmeganathan/essensio/keystore"
+)
+
+const testPassword = "password"
+
+// testNode is a node that serves the Essensio API over HTTP from a chain in a temporary directory
+type testNode struct {
+	client *Client
+	keys   *keystore.Keystore
+	params core.ChainParams
+	// Represents the miner of the chain, which is an account in the keystore
+	miner common.Address
+}
+
+// newTestNode starts a testNode with the given ledger. The miner address of the chain is an
+// account in the keystore of the node, so that the account holds the reward of the genesis block.
+func newTestNode(t *testing.T, ledger string) *testNode {
+	t.Helper()
+
+	// The API logs every call it serves
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	dir := t.TempDir()
+	keys, err := keystore.New(filepath.Join(dir, "keystore"), keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatalf("keystore: %v", err)
+	}
+
+	miner, err := keys.Create(testPassword)
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	params := core.DefaultChainParams()
+	params.Ledger = ledger
+
+	chain, err := chainmgr.NewChainManager(context.Background(), chainmgr.Config{
+		DataDir:      filepath.Join(dir, "chain"),
+		MinerAddress: miner,
+		Difficulty:   1,
+		Params:       params,
+	})
+	if err != nil {
+		t.Fatalf("chain: %v", err)
+	}
+
+	api := jsonrpc.NewAPI(chain, keys)
+	server := jsonrpc.NewServer()
+	if err := server.RegisterService(api, ""); err != nil {
+		t.Fatalf("register api: %v", err)
+	}
+
+	wsServer := jsonrpc.NewWebSocketServer(api)
+	mux := http.NewServeMux()
+	mux.Handle("/", server)
+	mux.Handle(DefaultWSPath, wsServer)
+
+	httpServer := httptest.NewServer(mux)
+	client := New(httpServer.URL, WithPaths("/", DefaultWSPath), WithTimeout(time.Minute))
+
+	t.Cleanup(func() {
+		client.Close()
+		wsServer.Close()
+		httpServer.Close()
+		_ = chain.Stop()
+	})
+
+	return &testNode{client: client, keys: keys, params: params, miner: miner}
+}
+
+// unlock unlocks the account in the keystore of the node until it is locked
+func (node *testNode) unlock(t *testing.T, address common.Address) {
+	t.Helper()
+
+	account, err := node.client.UnlockAccount(context.Background(), &jsonrpc.UnlockAccountArgs{Address: string(address), Password: testPassword})
+	if err != nil {
+		t.Fatalf("UnlockAccount: %v", err)
+	}
+
+	if !account.Unlocked {
+		t.Fatalf("UnlockAccount: account '%v' is not unlocked", address)
+	}
+}
+
+// newAddress returns the address of a new random key
+func newAddress(t *testing.T) common.Address {
+	t.Helper()
+
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	return common.PublicKeyToAddress(publicKey)
+}
+
+// errorCode returns the code of a *jsonrpc.Error, or 0 if err is not one
+func errorCode(err error) int {
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code
+	}
+
+	return 0
+}
+
+func uint64Ptr(value uint64) *uint64 {
+	return &value
+}
+
+func TestChainQueries(t *testing.T) {
+	node := newTestNode(t, core.LedgerAccount)
+	ctx := context.Background()
+
+	info, err := node.client.GetChainInfo(ctx)
+	if err != nil {
+		t.Fatalf("GetChainInfo: %v", err)
+	}
+
+	// The chain height is the number of blocks in the chain
+	if info.ChainID != node.params.ChainID || info.ChainHeight != 1 || info.ChainHead != info.GenesisHash {
+		t.Fatalf("GetChainInfo: unexpected chain %+v", info)
+	}
+
+	latest, err := node.client.GetLatestBlock(ctx, &jsonrpc.GetLatestBlockArgs{})
+	if err != nil {
+		t.Fatalf("GetLatestBlock: %v", err)
+	}
+
+	if latest.BlockHash != info.ChainHead || latest.TxnCount != 1 {
+		t.Fatalf("GetLatestBlock: unexpected block %+v", latest)
+	}
+
+	byHeight, err := node.client.GetBlockByHeight(ctx, &jsonrpc.GetBlockByHeightArgs{Height: uint64Ptr(0)})
+	if err != nil {
+		t.Fatalf("GetBlockByHeight: %v", err)
+	}
+
+	byHash, err := node.client.GetBlockByHash(ctx, &jsonrpc.GetBlockByHashArgs{Hash: byHeight.BlockHash, HeadersOnly: true})
+	if err != nil {
+		t.Fatalf("GetBlockByHash: %v", err)
+	}
+
+	if byHash.BlockHash != latest.BlockHash || len(byHash.Transactions) != 0 {
+		t.Fatalf("GetBlockByHash: unexpected block %+v", byHash)
+	}
+
+	page, err := node.client.ShowChain(ctx, &jsonrpc.ShowChainArgs{})
+	if err != nil {
+		t.Fatalf("ShowChain: %v", err)
+	}
+
+	if len(page.Blocks) != 1 || page.Blocks[0].BlockHash != info.GenesisHash || page.Next != nil {
+		t.Fatalf("ShowChain: unexpected page %+v", page)
+	}
+
+	supply, err := node.client.GetSupply(ctx)
+	if err != nil {
+		t.Fatalf("GetSupply: %v", err)
+	}
+
+	if supply.CirculatingSupply != node.params.InitialReward || supply.InitialReward != node.params.InitialReward {
+		t.Fatalf("GetSupply: unexpected supply %+v", supply)
+	}
+
+	accounts, err := node.client.ListAccounts(ctx)
+	if err != nil {
+		t.Fatalf("ListAccounts: %v", err)
+	}
+
+	if len(accounts.Accounts) != 1 || accounts.Accounts[0].Address != string(node.miner) || accounts.Accounts[0].Unlocked {
+		t.Fatalf("ListAccounts: unexpected accounts %+v", accounts)
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	node := newTestNode(t, core.LedgerAccount)
+	ctx := context.Background()
+	to := newAddress(t)
+
+	node.unlock(t, node.miner)
+
+	added, err := node.client.AddBlock(ctx, &jsonrpc.AddBlockArgs{Transactions: []jsonrpc.TransactionInput{
+		{From: string(node.miner), To: string(to), Value: 100, Fee: 1},
+	}})
+	if err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+
+	if added.BlockHeight != 1 {
+		t.Fatalf("AddBlock: unexpected height %v", added.BlockHeight)
+	}
+
+	block, err := node.client.GetBlockByHash(ctx, &jsonrpc.GetBlockByHashArgs{Hash: added.BlockHash})
+	if err != nil {
+		t.Fatalf("GetBlockByHash: %v", err)
+	}
+
+	// The block begins with its coinbase
+	if block.Height != 1 || len(block.Transactions) != 2 {
+		t.Fatalf("GetBlockByHash: unexpected block %+v", block)
+	}
+
+	txnHash := block.Transactions[1].Hash
+	txn, err := node.client.GetTransactionByHash(ctx, &jsonrpc.GetTransactionByHashArgs{Hash: txnHash})
+	if err != nil {
+		t.Fatalf("GetTransactionByHash: %v", err)
+	}
+
+	if txn.From != string(node.miner) || txn.To != string(to) || txn.Value != 100 {
+		t.Fatalf("GetTransactionByHash: unexpected transaction %+v", txn)
+	}
+
+	proof, err := node.client.GetTransactionProof(ctx, &jsonrpc.GetTransactionProofArgs{Hash: txnHash})
+	if err != nil {
+		t.Fatalf("GetTransactionProof: %v", err)
+	}
+
+	if proof.Hash != txnHash {
+		t.Fatalf("GetTransactionProof: unexpected transaction %v", proof.Hash)
+	}
+
+	balance, err := node.client.GetBalance(ctx, &jsonrpc.AccountArgs{Address: string(to)})
+	if err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+
+	if balance.Balance != 100 {
+		t.Fatalf("GetBalance: expected 100, got %v", balance.Balance)
+	}
+
+	// The receiver had no balance after the genesis block
+	before, err := node.client.GetBalance(ctx, &jsonrpc.AccountArgs{Address: string(to), BlockHeight: uint64Ptr(0)})
+	if err != nil {
+		t.Fatalf("GetBalance at height 0: %v", err)
+	}
+
+	if before.Balance != 0 {
+		t.Fatalf("GetBalance at height 0: expected 0, got %v", before.Balance)
+	}
+
+	nonce, err := node.client.GetNonce(ctx, &jsonrpc.AccountArgs{Address: string(node.miner)})
+	if err != nil {
+		t.Fatalf("GetNonce: %v", err)
+	}
+
+	if nonce.Nonce != 1 {
+		t.Fatalf("GetNonce: expected 1, got %v", nonce.Nonce)
+	}
+
+	account, err := node.client.GetAccountProof(ctx, &jsonrpc.AccountArgs{Address: string(to)})
+	if err != nil {
+		t.Fatalf("GetAccountProof: %v", err)
+	}
+
+	if account.Balance != 100 || account.BlockHash != added.BlockHash || account.StateRoot != block.StateRoot {
+		t.Fatalf("GetAccountProof: unexpected proof %+v", account)
+	}
+
+	locked, err := node.client.LockAccount(ctx, &jsonrpc.LockAccountArgs{Address: string(node.miner)})
+	if err != nil {
+		t.Fatalf("LockAccount: %v", err)
+	}
+
+	if locked.Unlocked {
+		t.Fatalf("LockAccount: account is still unlocked")
+	}
+
+	// The node can no longer sign for the locked miner
+	_, err = node.client.AddBlock(ctx, &jsonrpc.AddBlockArgs{Transactions: []jsonrpc.TransactionInput{
+		{From: string(node.miner), To: string(to), Value: 100, Fee: 1},
+	}})
+	if code := errorCode(err); code != jsonrpc.ErrCodeRejected {
+		t.Fatalf("AddBlock with a locked sender: expected code %v, got %v (%v)", jsonrpc.ErrCodeRejected, code, err)
+	}
+}
+
+func TestUnspentOutputs(t *testing.T) {
+	node := newTestNode(t, core.LedgerUTXO)
+	ctx := context.Background()
+
+	unspent, err := node.client.GetUnspentOutputs(ctx, &jsonrpc.GetUnspentOutputsArgs{Address: string(node.miner)})
+	if err != nil {
+		t.Fatalf("GetUnspentOutputs: %v", err)
+	}
+
+	// The miner holds the output of the genesis coinbase
+	if len(unspent.Outputs) != 1 || unspent.Outputs[0].Value != node.params.InitialReward {
+		t.Fatalf("GetUnspentOutputs: unexpected outputs %+v", unspent.Outputs)
+	}
+
+	node.unlock(t, node.miner)
+
+	to := newAddress(t)
+	if _, err := node.client.AddBlock(ctx, &jsonrpc.AddBlockArgs{Transactions: []jsonrpc.TransactionInput{
+		{From: string(node.miner), To: string(to), Value: 100, Fee: 1},
+	}}); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+
+	unspent, err = node.client.GetUnspentOutputs(ctx, &jsonrpc.GetUnspentOutputsArgs{Address: string(to)})
+	if err != nil {
+		t.Fatalf("GetUnspentOutputs: %v", err)
+	}
+
+	if len(unspent.Outputs) != 1 || unspent.Outputs[0].Value != 100 {
+		t.Fatalf("GetUnspentOutputs: unexpected outputs %+v", unspent.Outputs)
+	}
+}
+
+func TestMultisigTransfer(t *testing.T) {
+	node := newTestNode(t, core.LedgerAccount)
+	ctx := context.Background()
+
+	// The multisig is 2-of-2 between the miner and a second account
+	second, err := node.keys.Create(testPassword)
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	var publicKeys []string
+	for _, address := range []common.Address{node.miner, second} {
+		publicKey, err := node.keys.PublicKey(address, testPassword)
+		if err != nil {
+			t.Fatalf("public key: %v", err)
+		}
+
+		publicKeys = append(publicKeys, common.HexEncode(publicKey))
+		node.unlock(t, address)
+	}
+
+	multisig, err := node.client.CreateMultisig(ctx, &jsonrpc.CreateMultisigArgs{Threshold: 2, PublicKeys: publicKeys})
+	if err != nil {
+		t.Fatalf("CreateMultisig: %v", err)
+	}
+
+	// Fund the multisig from the miner
+	if _, err := node.client.AddBlock(ctx, &jsonrpc.AddBlockArgs{Transactions: []jsonrpc.TransactionInput{
+		{From: string(node.miner), To: multisig.Address, Value: 1000, Fee: 1},
+	}}); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+
+	to := newAddress(t)
+	partial, err := node.client.CreatePartialTransaction(ctx, &jsonrpc.CreatePartialTransactionArgs{Script: multisig.Script, To: string(to), Value: 100, Fee: 1})
+	if err != nil {
+		t.Fatalf("CreatePartialTransaction: %v", err)
+	}
+
+	// A partial transaction without a threshold of signatures is rejected
+	_, err = node.client.SubmitPartialTransaction(ctx, &jsonrpc.SubmitPartialTransactionArgs{Partial: *partial})
+	if code := errorCode(err); code != jsonrpc.ErrCodeRejected {
+		t.Fatalf("SubmitPartialTransaction without signatures: expected code %v, got %v (%v)", jsonrpc.ErrCodeRejected, code, err)
+	}
+
+	for _, address := range []common.Address{node.miner, second} {
+		if partial, err = node.client.SignPartialTransaction(ctx, &jsonrpc.SignPartialTransactionArgs{Partial: *partial, Address: string(address)}); err != nil {
+			t.Fatalf("SignPartialTransaction: %v", err)
+		}
+	}
+
+	if _, err := node.client.SubmitPartialTransaction(ctx, &jsonrpc.SubmitPartialTransactionArgs{Partial: *partial}); err != nil {
+		t.Fatalf("SubmitPartialTransaction: %v", err)
+	}
+
+	balance, err := node.client.GetBalance(ctx, &jsonrpc.AccountArgs{Address: string(to)})
+	if err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+
+	if balance.Balance != 100 {
+		t.Fatalf("GetBalance: expected 100, got %v", balance.Balance)
+	}
+}
+
+func TestBatchCall(t *testing.T) {
+	node := newTestNode(t, core.LedgerAccount)
+	node.unlock(t, node.miner)
+
+	to := newAddress(t)
+	var (
+		added   jsonrpc.AddBlockResult
+		balance jsonrpc.GetBalanceResult
+		missing jsonrpc.ChainBlock
+		unknown jsonrpc.GetChainInfoResult
+	)
+
+	batch := []BatchElem{
+		{Method: "API.AddBlock", Args: &jsonrpc.AddBlockArgs{Transactions: []jsonrpc.TransactionInput{
+			{From: string(node.miner), To: string(to), Value: 100, Fee: 1},
+		}}, Reply: &added},
+		// The balance observes the block mined by the call before it
+		{Method: "API.GetBalance", Args: &jsonrpc.AccountArgs{Address: string(to)}, Reply: &balance},
+		{Method: "API.GetBlockByHeight", Args: &jsonrpc.GetBlockByHeightArgs{Height: uint64Ptr(10)}, Reply: &missing},
+		{Method: "API.Unknown", Args: &jsonrpc.GetChainInfoArgs{}, Reply: &unknown},
+	}
+
+	if err := node.client.BatchCall(context.Background(), batch); err != nil {
+		t.Fatalf("BatchCall: %v", err)
+	}
+
+	if batch[0].Error != nil || added.BlockHeight != 1 {
+		t.Fatalf("AddBlock: unexpected result %+v (%v)", added, batch[0].Error)
+	}
+
+	if batch[1].Error != nil || balance.Balance != 100 {
+		t.Fatalf("GetBalance: unexpected result %+v (%v)", balance, batch[1].Error)
+	}
+
+	if code := errorCode(batch[2].Error); code != jsonrpc.ErrCodeNotFound {
+		t.Fatalf("GetBlockByHeight: expected code %v, got %v (%v)", jsonrpc.ErrCodeNotFound, code, batch[2].Error)
+	}
+
+	if code := errorCode(batch[3].Error); code != jsonrpc.ErrCodeMethodNotFound {
+		t.Fatalf("Unknown: expected code %v, got %v (%v)", jsonrpc.ErrCodeMethodNotFound, code, batch[3].Error)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	node := newTestNode(t, core.LedgerAccount)
+	ctx := context.Background()
+
+	// An unlocked account without any balance cannot send a transfer
+	unfunded, err := node.keys.Create(testPassword)
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	node.unlock(t, unfunded)
+
+	tests := []struct {
+		name string
+		call func() error
+		code int
+	}{
+		{"unknown method", func() error {
+			return node.client.Call(ctx, "API.Unknown", &jsonrpc.GetChainInfoArgs{}, new(jsonrpc.GetChainInfoResult))
+		}, jsonrpc.ErrCodeMethodNotFound},
+		{"malformed params", func() error {
+			return node.client.Call(ctx, "API.GetBlockByHash", []int{1}, new(jsonrpc.ChainBlock))
+		}, jsonrpc.ErrCodeInvalidParams},
+		{"invalid hash", func() error {
+			_, err := node.client.GetBlockByHash(ctx, &jsonrpc.GetBlockByHashArgs{Hash: "not a hash"})
+			return err
+		}, jsonrpc.ErrCodeInvalidParams},
+		{"missing height", func() error {
+			_, err := node.client.GetBlockByHeight(ctx, &jsonrpc.GetBlockByHeightArgs{})
+			return err
+		}, jsonrpc.ErrCodeInvalidParams},
+		{"unknown block", func() error {
+			_, err := node.client.GetBlockByHash(ctx, &jsonrpc.GetBlockByHashArgs{Hash: common.Hash256([]byte("unknown")).Hex()})
+			return err
+		}, jsonrpc.ErrCodeNotFound},
+		{"unknown transaction", func() error {
+			_, err := node.client.GetTransactionByHash(ctx, &jsonrpc.GetTransactionByHashArgs{Hash: common.Hash256([]byte("unknown")).Hex()})
+			return err
+		}, jsonrpc.ErrCodeNotFound},
+		{"state after the head", func() error {
+			_, err := node.client.GetBalance(ctx, &jsonrpc.AccountArgs{Address: string(node.miner), BlockHeight: uint64Ptr(10)})
+			return err
+		}, jsonrpc.ErrCodeNotFound},
+		{"height range overflow", func() error {
+			_, err := node.client.ShowChain(ctx, &jsonrpc.ShowChainArgs{ToHeight: uint64Ptr(math.MaxUint64)})
+			return err
+		}, jsonrpc.ErrCodeInvalidParams},
+		{"no transactions", func() error {
+			_, err := node.client.AddBlock(ctx, &jsonrpc.AddBlockArgs{})
+			return err
+		}, jsonrpc.ErrCodeInvalidParams},
+		{"locked sender", func() error {
+			_, err := node.client.AddBlock(ctx, &jsonrpc.AddBlockArgs{Transactions: []jsonrpc.TransactionInput{
+				{From: string(node.miner), To: string(newAddress(t)), Value: 100, Fee: 1},
+			}})
+			return err
+		}, jsonrpc.ErrCodeRejected},
+		{"unfunded sender", func() error {
+			_, err := node.client.AddBlock(ctx, &jsonrpc.AddBlockArgs{Transactions: []jsonrpc.TransactionInput{
+				{From: string(unfunded), To: string(newAddress(t)), Value: 100, Fee: 1},
+			}})
+			return err
+		}, jsonrpc.ErrCodeRejected},
+		{"wrong password", func() error {
+			_, err := node.client.UnlockAccount(ctx, &jsonrpc.UnlockAccountArgs{Address: string(node.miner), Password: "wrong"})
+			return err
+		}, jsonrpc.ErrCodeRejected},
+		{"unknown account", func() error {
+			_, err := node.client.UnlockAccount(ctx, &jsonrpc.UnlockAccountArgs{Address: string(newAddress(t)), Password: testPassword})
+			return err
+		}, jsonrpc.ErrCodeNotFound},
+		{"invalid multisig", func() error {
+			_, err := node.client.CreateMultisig(ctx, &jsonrpc.CreateMultisigArgs{Threshold: 1})
+			return err
+		}, jsonrpc.ErrCodeInvalidParams},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.call()
+			if code := errorCode(err); code != test.code {
+				t.Fatalf("expected code %v, got %v (%v)", test.code, code, err)
+			}
+		})
+	}
+}
+
+func TestShowChainScanLimit(t *testing.T) {
+	node := newTestNode(t, core.LedgerAccount)
+	ctx := context.Background()
+	node.unlock(t, node.miner)
+
+	// Only the first block after the genesis involves the filtered address,
+	// which is followed by MaxScanBlocks blocks that do not involve it
+	filtered, to := newAddress(t), newAddress(t)
+	if _, err := node.client.AddBlock(ctx, &jsonrpc.AddBlockArgs{Transactions: []jsonrpc.TransactionInput{
+		{From: string(node.miner), To: string(filtered), Value: 1, Fee: 1},
+	}}); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+
+	for mined := uint64(0); mined < jsonrpc.MaxScanBlocks; mined += jsonrpc.MaxBatchSize {
+		batch := make([]BatchElem, jsonrpc.MaxBatchSize)
+		for idx := range batch {
+			batch[idx] = BatchElem{Method: "API.AddBlock", Args: &jsonrpc.AddBlockArgs{Transactions: []jsonrpc.TransactionInput{
+				{From: string(node.miner), To: string(to), Value: 1, Fee: 1},
+			}}, Reply: new(jsonrpc.AddBlockResult)}
+		}
+
+		if err := node.client.BatchCall(ctx, batch); err != nil {
+			t.Fatalf("BatchCall: %v", err)
+		}
+
+		for _, elem := range batch {
+			if elem.Error != nil {
+				t.Fatalf("AddBlock: %v", elem.Error)
+			}
+		}
+	}
+
+	// The first page stops after reading MaxScanBlocks blocks without a match
+	page, err := node.client.ShowChain(ctx, &jsonrpc.ShowChainArgs{Address: string(filtered)})
+	if err != nil {
+		t.Fatalf("ShowChain: %v", err)
+	}
+
+	if len(page.Blocks) != 0 || page.Next == nil || *page.Next != 1 {
+		t.Fatalf("ShowChain: expected an empty page with the next cursor at height 1, got %v blocks (next %v)", len(page.Blocks), page.Next)
+	}
+
+	// The scan continues from the cursor
+	page, err = node.client.ShowChain(ctx, &jsonrpc.ShowChainArgs{Address: string(filtered), Start: page.Next})
+	if err != nil {
+		t.Fatalf("ShowChain: %v", err)
+	}
+
+	if len(page.Blocks) != 1 || page.Blocks[0].Height != 1 || page.Next != nil {
+		t.Fatalf("ShowChain: expected the block at height 1, got %+v", page)
+	}
+}
+
+func TestSubscribePendingTransactions(t *testing.T) {
+	node := newTestNode(t, core.LedgerAccount)
+	ctx := context.Background()
+
+	pending := make(chan jsonrpc.BlockTransaction, 8)
+	sub, err := node.client.SubscribePendingTransactions(ctx, pending)
+	if err != nil {
+		t.Fatalf("SubscribePendingTransactions: %v", err)
+	}
+
+	defer sub.Unsubscribe()
+
+	// A transfer that is rejected by AddBlock is never published as pending
+	unfunded, err := node.keys.Create(testPassword)
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	node.unlock(t, unfunded)
+	node.unlock(t, node.miner)
+
+	to := newAddress(t)
+	if _, err := node.client.AddBlock(ctx, &jsonrpc.AddBlockArgs{Transactions: []jsonrpc.TransactionInput{
+		{From: string(unfunded), To: string(to), Value: 100, Fee: 1},
+	}}); errorCode(err) != jsonrpc.ErrCodeRejected {
+		t.Fatalf("AddBlock with an unfunded sender: expected code %v, got %v", jsonrpc.ErrCodeRejected, err)
+	}
+
+	if _, err := node.client.AddBlock(ctx, &jsonrpc.AddBlockArgs{Transactions: []jsonrpc.TransactionInput{
+		{From: string(node.miner), To: string(to), Value: 100, Fee: 1},
+	}}); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+
+	select {
+	case txn := <-pending:
+		if txn.From != string(node.miner) || txn.To != string(to) || txn.Value != 100 {
+			t.Fatalf("SubscribePendingTransactions: unexpected transaction %+v", txn)
+		}
+
+	case err := <-sub.Err():
+		t.Fatalf("SubscribePendingTransactions: %v", err)
+	case <-time.After(10 * time.Second):
+		t.Fatalf("SubscribePendingTransactions: no transaction received")
+	}
+}
@@ -0,0 +1,431 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/jsonrpc"
+)
+
+const (
+	// subscriptionBuffer is the number of notifications that can be queued for a Subscription
+	subscriptionBuffer = 256
+	// unsubscribeTimeout is the time allowed for the node to acknowledge an unsubscribe
+	unsubscribeTimeout = 5 * time.Second
+)
+
+var (
+	// ErrClientClosed is returned when the Client is closed while a call or subscription is active
+	ErrClientClosed = errors.New("client closed")
+	// ErrSubscriptionLagging is sent on a Subscription's error channel
+	// when it is dropped for not keeping up with its notifications
+	ErrSubscriptionLagging = errors.New("subscription dropped: consumer lagging")
+)
+
+// Subscription is a subscription to notifications from a node.
+// Notifications are decoded and delivered on the channel
+// given when subscribing until the Subscription ends.
+type Subscription struct {
+	// ID is the identifier of the subscription assigned by the node
+	ID string
+	// Kind is the kind of notifications of the subscription
+	Kind string
+
+	ws   *wsConn
+	raw  chan json.RawMessage
+	err  chan error
+	quit chan struct{}
+	once sync.Once
+}
+
+// Err returns a channel that receives the error that ended the Subscription,
+// if it did not end with Unsubscribe. The channel is closed when the Subscription ends.
+func (sub *Subscription) Err() <-chan error {
+	return sub.err
+}
+
+// Unsubscribe ends the Subscription and notifies the node
+func (sub *Subscription) Unsubscribe() {
+	if sub.end(nil) {
+		ctx, cancel := context.WithTimeout(context.Background(), unsubscribeTimeout)
+		defer cancel()
+
+		_, _ = sub.ws.call(ctx, "unsubscribe", jsonrpc.WSRequestParams{Subscription: sub.ID})
+	}
+}
+
+// end ends the Subscription with the given error.
+// Returns whether the Subscription was active.
+func (sub *Subscription) end(err error) (ended bool) {
+	sub.once.Do(func() {
+		sub.ws.removeSubscription(sub.ID)
+
+		if err != nil {
+			sub.err <- err
+		}
+
+		close(sub.quit)
+		close(sub.err)
+		ended = true
+	})
+
+	return ended
+}
+
+// deliver queues a notification for the Subscription without blocking.
+// The Subscription is ended if its queue is full.
+func (sub *Subscription) deliver(result json.RawMessage) {
+	select {
+	case sub.raw <- result:
+	case <-sub.quit:
+	default:
+		if sub.end(ErrSubscriptionLagging) {
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), unsubscribeTimeout)
+				defer cancel()
+
+				_, _ = sub.ws.call(ctx, "unsubscribe", jsonrpc.WSRequestParams{Subscription: sub.ID})
+			}()
+		}
+	}
+}
+
+// forward decodes queued notifications with the given function until the Subscription ends
+func (sub *Subscription) forward(decode func(json.RawMessage) error) {
+	for {
+		select {
+		case result := <-sub.raw:
+			if err := decode(result); err != nil {
+				sub.end(fmt.Errorf("notification decode failed: %w", err))
+				return
+			}
+
+		case <-sub.quit:
+			return
+		}
+	}
+}
+
+// SubscribeNewHeads subscribes to the header of every new chain head
+func (client *Client) SubscribeNewHeads(ctx context.Context, ch chan<- jsonrpc.ChainBlock) (*Subscription, error) {
+	return client.subscribe(ctx, jsonrpc.WSRequestParams{Kind: jsonrpc.SubNewHeads}, func(sub *Subscription, result json.RawMessage) error {
+		var header jsonrpc.ChainBlock
+		if err := json.Unmarshal(result, &header); err != nil {
+			return err
+		}
+
+		select {
+		case ch <- header:
+		case <-sub.quit:
+		}
+
+		return nil
+	})
+}
+
+// SubscribePendingTransactions subscribes to every transaction accepted for mining
+func (client *Client) SubscribePendingTransactions(ctx context.Context, ch chan<- jsonrpc.BlockTransaction) (*Subscription, error) {
+	return client.subscribe(ctx, jsonrpc.WSRequestParams{Kind: jsonrpc.SubPendingTransactions}, func(sub *Subscription, result json.RawMessage) error {
+		var txn jsonrpc.BlockTransaction
+		if err := json.Unmarshal(result, &txn); err != nil {
+			return err
+		}
+
+		select {
+		case ch <- txn:
+		case <-sub.quit:
+		}
+
+		return nil
+	})
+}
+
+// SubscribeReorgs subscribes to every replacement of the chain head by a non-descendant.
+// The node only extends its chain head until it can switch forks, so nothing is delivered yet.
+func (client *Client) SubscribeReorgs(ctx context.Context, ch chan<- jsonrpc.Reorg) (*Subscription, error) {
+	return client.subscribe(ctx, jsonrpc.WSRequestParams{Kind: jsonrpc.SubReorgs}, func(sub *Subscription, result json.RawMessage) error {
+		var reorg jsonrpc.Reorg
+		if err := json.Unmarshal(result, &reorg); err != nil {
+			return err
+		}
+
+		select {
+		case ch <- reorg:
+		case <-sub.quit:
+		}
+
+		return nil
+	})
+}
+
+// SubscribeAddressActivity subscribes to the transactions in new blocks that involve the given address
+func (client *Client) SubscribeAddressActivity(ctx context.Context, address common.Address, ch chan<- jsonrpc.AddressActivity) (*Subscription, error) {
+	params := jsonrpc.WSRequestParams{Kind: jsonrpc.SubAddressActivity, Address: string(address)}
+	return client.subscribe(ctx, params, func(sub *Subscription, result json.RawMessage) error {
+		var activity jsonrpc.AddressActivity
+		if err := json.Unmarshal(result, &activity); err != nil {
+			return err
+		}
+
+		select {
+		case ch <- activity:
+		case <-sub.quit:
+		}
+
+		return nil
+	})
+}
+
+// subscribe makes a subscription with the given params and forwards
+// its notifications with the given function until it ends
+func (client *Client) subscribe(ctx context.Context, params jsonrpc.WSRequestParams, forward func(*Subscription, json.RawMessage) error) (*Subscription, error) {
+	ws, err := client.websocket(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		Kind: params.Kind,
+		ws:   ws,
+		raw:  make(chan json.RawMessage, subscriptionBuffer),
+		err:  make(chan error, 1),
+		quit: make(chan struct{}),
+	}
+
+	// The subscription must be registered as soon as its ID is known
+	// so that no notification is missed, which happens in the read loop
+	if _, err := ws.callWith(ctx, "subscribe", params, func(result json.RawMessage) {
+		if json.Unmarshal(result, &sub.ID) == nil {
+			ws.addSubscription(sub)
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	if sub.ID == "" {
+		return nil, fmt.Errorf("node returned an invalid subscription id")
+	}
+
+	go sub.forward(func(result json.RawMessage) error { return forward(sub, result) })
+	return sub, nil
+}
+
+// websocket returns the WebSocket connection of the Client, dialing it if required
+func (client *Client) websocket(ctx context.Context) (*wsConn, error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	if client.ws != nil && !client.ws.closed() {
+		return client.ws, nil
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, client.wsURL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("websocket dial failed: %w", err)
+	}
+
+	client.ws = newWSConn(conn)
+	return client.ws, nil
+}
+
+// wsMessage is a message received over a WebSocket connection.
+// It is either a response to a request or a subscription notification.
+type wsMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *jsonrpc.Error  `json:"error"`
+
+	Method string               `json:"method"`
+	Params wsNotificationParams `json:"params"`
+}
+
+// wsNotificationParams are the params of a subscription notification with an undecoded result
+type wsNotificationParams struct {
+	Subscription string          `json:"subscription"`
+	Kind         string          `json:"kind"`
+	Result       json.RawMessage `json:"result"`
+}
+
+// wsPending is a request waiting for its response on a WebSocket connection
+type wsPending struct {
+	response chan *wsMessage
+	// onResult is called in the read loop before the response is delivered
+	onResult func(json.RawMessage)
+}
+
+// wsConn is a WebSocket connection to a node shared by all subscriptions of a Client
+type wsConn struct {
+	conn   *websocket.Conn
+	nextID uint64
+
+	writeMutex sync.Mutex
+
+	mutex   sync.Mutex
+	pending map[string]*wsPending
+	subs    map[string]*Subscription
+
+	done chan struct{}
+	err  error
+	once sync.Once
+}
+
+// newWSConn returns a new wsConn for the given connection and starts its read loop
+func newWSConn(conn *websocket.Conn) *wsConn {
+	ws := &wsConn{
+		conn:    conn,
+		pending: make(map[string]*wsPending),
+		subs:    make(map[string]*Subscription),
+		done:    make(chan struct{}),
+	}
+
+	go ws.readLoop()
+	return ws
+}
+
+// closed returns whether the connection has been closed
+func (ws *wsConn) closed() bool {
+	select {
+	case <-ws.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// close closes the connection and ends all its subscriptions with the given error
+func (ws *wsConn) close(err error) {
+	ws.once.Do(func() {
+		ws.err = err
+		close(ws.done)
+		_ = ws.conn.Close()
+
+		ws.mutex.Lock()
+		subs := make([]*Subscription, 0, len(ws.subs))
+		for _, sub := range ws.subs {
+			subs = append(subs, sub)
+		}
+		ws.mutex.Unlock()
+
+		for _, sub := range subs {
+			sub.end(err)
+		}
+	})
+}
+
+// addSubscription registers a Subscription on the connection
+func (ws *wsConn) addSubscription(sub *Subscription) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	ws.subs[sub.ID] = sub
+}
+
+// removeSubscription removes a Subscription from the connection
+func (ws *wsConn) removeSubscription(id string) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	delete(ws.subs, id)
+}
+
+// call sends a request over the connection and waits for its result
+func (ws *wsConn) call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	return ws.callWith(ctx, method, params, nil)
+}
+
+// callWith sends a request over the connection and waits for its result.
+// If onResult is not nil, it is called with the result from the read loop.
+func (ws *wsConn) callWith(ctx context.Context, method string, params any, onResult func(json.RawMessage)) (json.RawMessage, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("params encode failed: %w", err)
+	}
+
+	ws.mutex.Lock()
+	ws.nextID++
+	id := fmt.Sprintf("%d", ws.nextID)
+	pending := &wsPending{make(chan *wsMessage, 1), onResult}
+	ws.pending[id] = pending
+	ws.mutex.Unlock()
+
+	defer func() {
+		ws.mutex.Lock()
+		delete(ws.pending, id)
+		ws.mutex.Unlock()
+	}()
+
+	request := jsonrpc.Request{JSONRPC: jsonrpc.Version, Method: method, Params: data, ID: json.RawMessage(id)}
+
+	ws.writeMutex.Lock()
+	err = ws.conn.WriteJSON(request)
+	ws.writeMutex.Unlock()
+
+	if err != nil {
+		ws.close(err)
+		return nil, fmt.Errorf("websocket write failed: %w", err)
+	}
+
+	select {
+	case message := <-pending.response:
+		if message.Error != nil {
+			return nil, message.Error
+		}
+
+		return message.Result, nil
+
+	case <-ws.done:
+		return nil, ws.err
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// readLoop reads messages from the connection and dispatches them until it is closed
+func (ws *wsConn) readLoop() {
+	for {
+		_, data, err := ws.conn.ReadMessage()
+		if err != nil {
+			ws.close(fmt.Errorf("websocket read failed: %w", err))
+			return
+		}
+
+		var message wsMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			continue
+		}
+
+		// Dispatch notifications to their subscription
+		if message.Method == "subscription" {
+			ws.mutex.Lock()
+			sub, ok := ws.subs[message.Params.Subscription]
+			ws.mutex.Unlock()
+
+			if ok {
+				sub.deliver(message.Params.Result)
+			}
+
+			continue
+		}
+
+		// Dispatch responses to their pending request
+		ws.mutex.Lock()
+		pending, ok := ws.pending[string(message.ID)]
+		ws.mutex.Unlock()
+
+		if ok {
+			if message.Error == nil && pending.onResult != nil {
+				pending.onResult(message.Result)
+			}
+
+			pending.response <- &message
+		}
+	}
+}
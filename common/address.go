@@ -1,20 +1,54 @@
 package common
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AddressLength is the number of bytes of the public key hash in an Address
+const AddressLength = 20
+
+// ErrInvalidAddress is returned when an Address is not the hex encoding of a public key hash
+var ErrInvalidAddress = errors.New("invalid address")
+
 // Address represents the address for an Account
 // Placeholder for [20]byte type Addresses.
 type Address string
 
+// PublicKeyToAddress returns the Address of the given public key, which is
+// the hex encoding of the last AddressLength bytes of its SHA-256 hash.
+func PublicKeyToAddress(publicKey []byte) Address {
+	hash := Hash256(publicKey)
+	return Address(HexEncode(hash.Bytes()[HashLength-AddressLength:]))
+}
+
 // Bytes returns the byte representation of the Address
 func (addr Address) Bytes() []byte {
 	return []byte(addr)
 }
 
+// Validate returns ErrInvalidAddress if the Address is not a 0x prefixed lowercase hex encoding
+// of AddressLength bytes. Addresses are compared as strings, so the same bytes in uppercase
+// would be a different Address.
+func (addr Address) Validate() error {
+	data, err := HexDecode(string(addr))
+	if err != nil {
+		return fmt.Errorf("%w '%v': %v", ErrInvalidAddress, addr, err)
+	}
+
+	if len(data) != AddressLength {
+		return fmt.Errorf("%w '%v': expected %v bytes, got %v", ErrInvalidAddress, addr, AddressLength, len(data))
+	}
+
+	if string(addr) != strings.ToLower(string(addr)) {
+		return fmt.Errorf("%w '%v': must be lowercase", ErrInvalidAddress, addr)
+	}
+
+	return nil
+}
+
 // NullAddress returns a zero Address
 func NullAddress() Address {
 	return ""
 }
-
-// MinerAddress returns the Address to use for Coinbase Transactions
-func MinerAddress() Address {
-	return "manish"
-}
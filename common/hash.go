@@ -2,6 +2,7 @@ package common
 
 import (
 	"crypto/sha256"
+	"fmt"
 	"math/big"
 )
 
@@ -24,6 +25,21 @@ func BytesToHash(b []byte) (h Hash) {
 	return
 }
 
+// HexToHash converts a hex string with 0x prefix into a Hash.
+// Returns an error if the string is not valid hex or is not HashLength bytes long.
+func HexToHash(input string) (Hash, error) {
+	b, err := HexDecode(input)
+	if err != nil {
+		return NullHash(), err
+	}
+
+	if len(b) != HashLength {
+		return NullHash(), fmt.Errorf("hash must be %v bytes, got %v", HashLength, len(b))
+	}
+
+	return BytesToHash(b), nil
+}
+
 // NullHash returns a zero Hash
 func NullHash() Hash { return [32]byte{} }
 
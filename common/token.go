@@ -1,8 +1,17 @@
 package common
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
 const (
 	// Nub is the smallest unit of a token in the Essencio Blockchain
-	Nub uint64 = 1
+	Nub Amount = 1
 
 	// Pith is 1,000 Nub
 	Pith = 1000 * Nub
@@ -17,3 +26,201 @@ const (
 	// block reward in the Essencio Blockchain
 	Quintessence = 5 * Essence
 )
+
+var (
+	// ErrAmountOverflow is returned when the result of an Amount operation exceeds the maximum Amount
+	ErrAmountOverflow = errors.New("amount overflow")
+	// ErrAmountUnderflow is returned when an Amount is subtracted from a smaller Amount
+	ErrAmountUnderflow = errors.New("amount underflow")
+	// ErrInvalidAmount is returned when a string cannot be parsed into an Amount
+	ErrInvalidAmount = errors.New("invalid amount")
+)
+
+// units are the named denominations of tokens, from largest to smallest
+var units = []struct {
+	name  string
+	value Amount
+}{
+	{"Quintessence", Quintessence},
+	{"Essence", Essence},
+	{"Esse", Esse},
+	{"Pith", Pith},
+	{"Nub", Nub},
+}
+
+// Amount is an amount of tokens in Nubs.
+// Its arithmetic methods return an error instead of silently wrapping around.
+type Amount uint64
+
+// Add returns the sum of the Amounts or ErrAmountOverflow if it exceeds the maximum Amount
+func (amount Amount) Add(other Amount) (Amount, error) {
+	sum, carry := bits.Add64(uint64(amount), uint64(other), 0)
+	if carry != 0 {
+		return 0, fmt.Errorf("%w: %v + %v", ErrAmountOverflow, amount, other)
+	}
+
+	return Amount(sum), nil
+}
+
+// Sub returns the difference of the Amounts or ErrAmountUnderflow if other is larger than the Amount
+func (amount Amount) Sub(other Amount) (Amount, error) {
+	if other > amount {
+		return 0, fmt.Errorf("%w: %v - %v", ErrAmountUnderflow, amount, other)
+	}
+
+	return amount - other, nil
+}
+
+// Mul returns the Amount multiplied by n or ErrAmountOverflow if it exceeds the maximum Amount
+func (amount Amount) Mul(n uint64) (Amount, error) {
+	hi, lo := bits.Mul64(uint64(amount), n)
+	if hi != 0 {
+		return 0, fmt.Errorf("%w: %v * %v", ErrAmountOverflow, amount, n)
+	}
+
+	return Amount(lo), nil
+}
+
+// ParseAmount parses an Amount from a decimal number followed by an optional unit, such as
+// "1.25 Essence", "300 Pith" or "1500". Units are case-insensitive and may be plural.
+// A number without a unit is in Nubs. The number cannot be more precise than a Nub.
+func ParseAmount(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+
+	// Split the number from the unit, which may or may not be separated by spaces
+	split := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if split == -1 {
+		split = len(s)
+	}
+
+	number, name := s[:split], strings.TrimSpace(s[split:])
+
+	denomination := Nub
+	if name != "" {
+		denomination = 0
+		for _, u := range units {
+			if strings.EqualFold(name, u.name) || strings.EqualFold(name, u.name+"s") {
+				denomination = u.value
+				break
+			}
+		}
+
+		if denomination == 0 {
+			return 0, fmt.Errorf("%w: unknown unit '%v'", ErrInvalidAmount, name)
+		}
+	}
+
+	// Trailing zeros of the fraction do not add precision
+	whole, fraction, _ := strings.Cut(number, ".")
+	fraction = strings.TrimRight(fraction, "0")
+
+	if (whole == "" && fraction == "") || strings.Contains(fraction, ".") {
+		return 0, fmt.Errorf("%w: '%v' is not a number", ErrInvalidAmount, s)
+	}
+
+	var amount Amount
+	if whole != "" {
+		value, err := strconv.ParseUint(whole, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: '%v' is too large", ErrInvalidAmount, s)
+		}
+
+		if amount, err = Amount(value).Mul(uint64(denomination)); err != nil {
+			return 0, fmt.Errorf("%w: '%v' is too large", ErrInvalidAmount, s)
+		}
+	}
+
+	if fraction != "" {
+		// The fraction of the unit must be a whole number of Nubs
+		value, err := strconv.ParseUint(fraction, 10, 64)
+		if err != nil || len(fraction) > 19 {
+			return 0, fmt.Errorf("%w: '%v' is more precise than a Nub", ErrInvalidAmount, s)
+		}
+
+		scale := uint64(1)
+		for range fraction {
+			scale *= 10
+		}
+
+		hi, lo := bits.Mul64(value, uint64(denomination))
+		nubs, remainder := bits.Div64(hi, lo, scale)
+		if remainder != 0 {
+			return 0, fmt.Errorf("%w: '%v' is more precise than a Nub", ErrInvalidAmount, s)
+		}
+
+		if amount, err = amount.Add(Amount(nubs)); err != nil {
+			return 0, fmt.Errorf("%w: '%v' is too large", ErrInvalidAmount, s)
+		}
+	}
+
+	return amount, nil
+}
+
+// String implements the Stringer interface for Amount. The Amount is formatted in the largest unit
+// that it is at least one of, such as "1.25 Essence". Quintessence is not used for formatting
+// because it is not a power of ten Nubs.
+func (amount Amount) String() string {
+	for _, u := range units {
+		if u.value == Quintessence || (amount < u.value && u.value != Nub) {
+			continue
+		}
+
+		whole, fraction := uint64(amount/u.value), uint64(amount%u.value)
+		if fraction == 0 {
+			return fmt.Sprintf("%v %v", whole, u.name)
+		}
+
+		// Format the fraction with a digit for every power of ten in the unit
+		decimals := len(strconv.FormatUint(uint64(u.value), 10)) - 1
+		digits := strings.TrimRight(fmt.Sprintf("%0*d", decimals, fraction), "0")
+
+		return fmt.Sprintf("%v.%v %v", whole, digits, u.name)
+	}
+
+	return fmt.Sprintf("%v Nub", uint64(amount))
+}
+
+// Set implements the flag.Value interface for Amount
+func (amount *Amount) Set(s string) error {
+	parsed, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+
+	*amount = parsed
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for Amount
+func (amount Amount) MarshalText() ([]byte, error) {
+	return []byte(amount.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for Amount
+func (amount *Amount) UnmarshalText(text []byte) error {
+	return amount.Set(string(text))
+}
+
+// MarshalJSON implements the json.Marshaler interface for Amount.
+// Amounts are encoded as a number of Nubs so that they are exact.
+func (amount Amount) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatUint(uint64(amount), 10)), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for Amount.
+// Amounts are decoded from a number of Nubs or a string with a unit such as "1.25 Essence".
+func (amount *Amount) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		// Decode a number of Nubs, which must be an integer
+		value, err := strconv.ParseUint(string(data), 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidAmount, string(data))
+		}
+
+		*amount = Amount(value)
+		return nil
+	}
+
+	return amount.Set(s)
+}
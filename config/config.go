@@ -0,0 +1,246 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/db"
+	"github.com/manishmeganathan/essensio/keystore"
+)
+
+// Config is the configuration of an Essensio node
+type Config struct {
+	// Represents the directory of the blockchain database
+	DataDir string `toml:"data_dir"`
+	// Represents the directory of the encrypted account keys
+	KeystoreDir string `toml:"keystore_dir"`
+
+	RPC     RPCConfig     `toml:"rpc"`
+	Miner   MinerConfig   `toml:"miner"`
+	Chain   ChainConfig   `toml:"chain"`
+	Storage StorageConfig `toml:"storage"`
+}
+
+// RPCConfig is the configuration of the RPC server of a node
+type RPCConfig struct {
+	// Represents the host interface the server listens on.
+	// It defaults to the loopback interface, and all interfaces are used if empty.
+	Host string `toml:"host"`
+	// Represents the port the server listens on
+	Port uint16 `toml:"port"`
+	// Represents the path of the JSON-RPC endpoint
+	Path string `toml:"path"`
+	// Represents the path of the WebSocket endpoint
+	WSPath string `toml:"ws_path"`
+	// Represents the time allowed for in-flight requests to complete on shutdown
+	ShutdownTimeout time.Duration `toml:"shutdown_timeout"`
+}
+
+// MinerConfig is the configuration of the block miner of a node
+type MinerConfig struct {
+	// Represents the address that receives the rewards for mining blocks
+	Address string `toml:"address"`
+	// Represents the Proof of Work difficulty for mining blocks
+	Difficulty uint8 `toml:"difficulty"`
+}
+
+// ChainConfig is the configuration of the consensus parameters of a new chain.
+// The parameters of an existing chain are fixed when its genesis block is created.
+type ChainConfig struct {
+	// Represents the chain ID, which transactions and blocks commit to
+	ID uint64 `toml:"id"`
+	// Represents the block subsidy of the first halving interval
+	InitialReward common.Amount `toml:"initial_reward"`
+	// Represents the number of blocks after which the block subsidy halves
+	HalvingInterval int64 `toml:"halving_interval"`
+	// Represents the ledger model, either "account" or "utxo"
+	Ledger string `toml:"ledger"`
+	// Represents the hash of the genesis block that a light client trusts.
+	// It is required to follow the headers of a chain and unused by full nodes.
+	Genesis string `toml:"genesis"`
+}
+
+// Storage modes of a node
+const (
+	// StorageArchive keeps the bodies of all blocks
+	StorageArchive = "archive"
+	// StoragePrune deletes the bodies of blocks deeper than the prune depth
+	StoragePrune = "prune"
+)
+
+// DefaultPruneDepth is the default number of recent blocks whose bodies are kept in the prune storage mode
+const DefaultPruneDepth = 1024
+
+// DefaultSnapshotRetain is the default number of periodic state snapshots that are kept
+const DefaultSnapshotRetain = 2
+
+// StorageConfig is the configuration of the block storage of a node
+type StorageConfig struct {
+	// Represents the storage mode, either StorageArchive or StoragePrune
+	Mode string `toml:"mode"`
+	// Represents the number of recent blocks whose bodies are kept in the prune storage mode
+	PruneDepth int64 `toml:"prune_depth"`
+	// Represents the number of blocks after which a state snapshot is created, never if zero
+	SnapshotInterval int64 `toml:"snapshot_interval"`
+	// Represents the number of periodic state snapshots that are kept, all if zero
+	SnapshotRetain int `toml:"snapshot_retain"`
+}
+
+// Default returns the default Config
+func Default() *Config {
+	return &Config{
+		DataDir:     db.Dir(),
+		KeystoreDir: keystore.Dir(),
+		RPC: RPCConfig{
+			Host:   "127.0.0.1",
+			Port:   8080,
+			Path:   "/rpc",
+			WSPath: "/ws",
+
+			ShutdownTimeout: 10 * time.Second,
+		},
+		Miner: MinerConfig{
+			Difficulty: core.BlockDifficulty,
+		},
+		Chain: ChainConfig{
+			ID:              core.ChainID,
+			InitialReward:   core.BlockReward,
+			HalvingInterval: core.DefaultHalvingInterval,
+			Ledger:          core.LedgerAccount,
+		},
+		Storage: StorageConfig{
+			Mode:           StorageArchive,
+			PruneDepth:     DefaultPruneDepth,
+			SnapshotRetain: DefaultSnapshotRetain,
+		},
+	}
+}
+
+// Validate checks that the Config is usable and normalizes its values.
+// Returns an error describing the first invalid setting.
+func (config *Config) Validate() error {
+	if config.DataDir == "" {
+		return fmt.Errorf("data_dir: must not be empty")
+	}
+
+	// Resolve the data directory into an absolute path
+	dir, err := filepath.Abs(config.DataDir)
+	if err != nil {
+		return fmt.Errorf("data_dir: %w", err)
+	}
+	config.DataDir = dir
+
+	if config.KeystoreDir == "" {
+		return fmt.Errorf("keystore_dir: must not be empty")
+	}
+
+	// Resolve the keystore directory into an absolute path
+	if config.KeystoreDir, err = filepath.Abs(config.KeystoreDir); err != nil {
+		return fmt.Errorf("keystore_dir: %w", err)
+	}
+
+	if config.RPC.Port == 0 {
+		return fmt.Errorf("rpc.port: must not be 0")
+	}
+
+	if !strings.HasPrefix(config.RPC.Path, "/") {
+		return fmt.Errorf("rpc.path: '%v' must begin with '/'", config.RPC.Path)
+	}
+
+	if !strings.HasPrefix(config.RPC.WSPath, "/") {
+		return fmt.Errorf("rpc.ws_path: '%v' must begin with '/'", config.RPC.WSPath)
+	}
+
+	if config.RPC.Path == config.RPC.WSPath {
+		return fmt.Errorf("rpc.ws_path: must differ from rpc.path '%v'", config.RPC.Path)
+	}
+
+	if config.RPC.ShutdownTimeout <= 0 {
+		return fmt.Errorf("rpc.shutdown_timeout: %v must be positive", config.RPC.ShutdownTimeout)
+	}
+
+	// The miner defaults to the first account of the keystore, so that its rewards can be spent
+	if config.Miner.Address == "" {
+		accounts, err := keystore.ListAccounts(config.KeystoreDir)
+		if err != nil {
+			return fmt.Errorf("miner.address: %w", err)
+		}
+
+		if len(accounts) > 0 {
+			config.Miner.Address = string(accounts[0])
+		}
+	}
+
+	// A node without a miner address can serve the chain but cannot mine blocks
+	if config.Miner.Address != "" {
+		if err := common.Address(config.Miner.Address).Validate(); err != nil {
+			return fmt.Errorf("miner.address: %w", err)
+		}
+	}
+
+	if config.Miner.Difficulty == 0 || config.Miner.Difficulty > MaxDifficulty {
+		return fmt.Errorf("miner.difficulty: %v must be between 1 and %v", config.Miner.Difficulty, MaxDifficulty)
+	}
+
+	params := core.ChainParams{
+		ChainID:         config.Chain.ID,
+		InitialReward:   config.Chain.InitialReward,
+		HalvingInterval: config.Chain.HalvingInterval,
+		Ledger:          config.Chain.Ledger,
+	}
+
+	if err := params.Validate(); err != nil {
+		return fmt.Errorf("chain: %w", err)
+	}
+
+	if config.Chain.Genesis != "" {
+		if _, err := common.HexToHash(config.Chain.Genesis); err != nil {
+			return fmt.Errorf("chain.genesis: %w", err)
+		}
+	}
+
+	if config.Storage.Mode != StorageArchive && config.Storage.Mode != StoragePrune {
+		return fmt.Errorf("storage.mode: '%v' must be '%v' or '%v'", config.Storage.Mode, StorageArchive, StoragePrune)
+	}
+
+	if config.Storage.PruneDepth <= 0 {
+		return fmt.Errorf("storage.prune_depth: %v must be positive", config.Storage.PruneDepth)
+	}
+
+	if config.Storage.SnapshotInterval < 0 {
+		return fmt.Errorf("storage.snapshot_interval: %v must not be negative", config.Storage.SnapshotInterval)
+	}
+
+	if config.Storage.SnapshotRetain < 0 {
+		return fmt.Errorf("storage.snapshot_retain: %v must not be negative", config.Storage.SnapshotRetain)
+	}
+
+	return nil
+}
+
+// MaxDifficulty is the maximum supported Proof of Work difficulty.
+// Blocks with a greater difficulty are not feasible to mine.
+const MaxDifficulty = 64
+
+// ListenAddr returns the address the RPC server listens on
+func (config *Config) ListenAddr() string {
+	return fmt.Sprintf("%v:%v", config.RPC.Host, config.RPC.Port)
+}
+
+// String implements the Stringer interface for Config.
+// Returns the Config encoded as TOML.
+func (config *Config) String() string {
+	var buffer bytes.Buffer
+	if err := toml.NewEncoder(&buffer).Encode(config); err != nil {
+		return fmt.Sprintf("config encode failed: %v", err)
+	}
+
+	return buffer.String()
+}
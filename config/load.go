@@ -0,0 +1,236 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// EnvPrefix is the prefix of all environment variables that configure a node
+const EnvPrefix = "ESSENSIO_"
+
+// EnvConfigFile is the environment variable that specifies the path of the config file
+const EnvConfigFile = EnvPrefix + "CONFIG"
+
+// setting is a single configurable value of a Config.
+// Its key is the TOML key of the setting, from which the
+// environment variable and command-line flag are derived.
+type setting struct {
+	key   string
+	usage string
+	get   func(*Config) string
+	set   func(*Config, string) error
+}
+
+// env returns the environment variable for the setting (e.g. ESSENSIO_RPC_PORT)
+func (s setting) env() string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer(".", "_").Replace(s.key))
+}
+
+// flag returns the command-line flag for the setting (e.g. rpc-port)
+func (s setting) flag() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s.key)
+}
+
+// settings is the list of all the settings of a Config
+var settings = []setting{
+	{
+		key: "data_dir", usage: "directory of the blockchain database",
+		get: func(c *Config) string { return c.DataDir },
+		set: func(c *Config, v string) error { c.DataDir = v; return nil },
+	},
+	{
+		key: "keystore_dir", usage: "directory of the encrypted account keys",
+		get: func(c *Config) string { return c.KeystoreDir },
+		set: func(c *Config, v string) error { c.KeystoreDir = v; return nil },
+	},
+	{
+		key: "rpc.host", usage: "host interface of the RPC server",
+		get: func(c *Config) string { return c.RPC.Host },
+		set: func(c *Config, v string) error { c.RPC.Host = v; return nil },
+	},
+	{
+		key: "rpc.port", usage: "port of the RPC server",
+		get: func(c *Config) string { return strconv.FormatUint(uint64(c.RPC.Port), 10) },
+		set: func(c *Config, v string) error {
+			port, err := strconv.ParseUint(v, 10, 16)
+			c.RPC.Port = uint16(port)
+			return err
+		},
+	},
+	{
+		key: "rpc.path", usage: "path of the JSON-RPC endpoint",
+		get: func(c *Config) string { return c.RPC.Path },
+		set: func(c *Config, v string) error { c.RPC.Path = v; return nil },
+	},
+	{
+		key: "rpc.ws_path", usage: "path of the WebSocket endpoint",
+		get: func(c *Config) string { return c.RPC.WSPath },
+		set: func(c *Config, v string) error { c.RPC.WSPath = v; return nil },
+	},
+	{
+		key: "rpc.shutdown_timeout", usage: "time allowed for in-flight requests to complete on shutdown",
+		get: func(c *Config) string { return c.RPC.ShutdownTimeout.String() },
+		set: func(c *Config, v string) error {
+			timeout, err := time.ParseDuration(v)
+			c.RPC.ShutdownTimeout = timeout
+			return err
+		},
+	},
+	{
+		key: "miner.address", usage: "address that receives mining rewards (defaults to the first keystore account)",
+		get: func(c *Config) string { return c.Miner.Address },
+		set: func(c *Config, v string) error { c.Miner.Address = v; return nil },
+	},
+	{
+		key: "miner.difficulty", usage: "proof of work difficulty for mining blocks",
+		get: func(c *Config) string { return strconv.FormatUint(uint64(c.Miner.Difficulty), 10) },
+		set: func(c *Config, v string) error {
+			difficulty, err := strconv.ParseUint(v, 10, 8)
+			c.Miner.Difficulty = uint8(difficulty)
+			return err
+		},
+	},
+	{
+		key: "chain.id", usage: "chain ID of a new chain, which its transactions and blocks commit to",
+		get: func(c *Config) string { return strconv.FormatUint(c.Chain.ID, 10) },
+		set: func(c *Config, v string) error {
+			id, err := strconv.ParseUint(v, 10, 64)
+			c.Chain.ID = id
+			return err
+		},
+	},
+	{
+		key: "chain.initial_reward", usage: "block subsidy of a new chain before its first halving",
+		get: func(c *Config) string { return c.Chain.InitialReward.String() },
+		set: func(c *Config, v string) error { return c.Chain.InitialReward.Set(v) },
+	},
+	{
+		key: "chain.halving_interval", usage: "number of blocks after which the block subsidy of a new chain halves",
+		get: func(c *Config) string { return strconv.FormatInt(c.Chain.HalvingInterval, 10) },
+		set: func(c *Config, v string) error {
+			interval, err := strconv.ParseInt(v, 10, 64)
+			c.Chain.HalvingInterval = interval
+			return err
+		},
+	},
+	{
+		key: "chain.ledger", usage: "ledger model of a new chain, either 'account' for account balances or 'utxo' for unspent outputs",
+		get: func(c *Config) string { return c.Chain.Ledger },
+		set: func(c *Config, v string) error { c.Chain.Ledger = v; return nil },
+	},
+	{
+		key: "chain.genesis", usage: "hash of the genesis block trusted by a light client, from the 'genesis_hash' of GetChainInfo",
+		get: func(c *Config) string { return c.Chain.Genesis },
+		set: func(c *Config, v string) error { c.Chain.Genesis = v; return nil },
+	},
+	{
+		key: "storage.mode", usage: "storage mode, either 'archive' to keep all block bodies or 'prune' to delete old block bodies",
+		get: func(c *Config) string { return c.Storage.Mode },
+		set: func(c *Config, v string) error { c.Storage.Mode = v; return nil },
+	},
+	{
+		key: "storage.prune_depth", usage: "number of recent blocks whose bodies are kept in the prune storage mode",
+		get: func(c *Config) string { return strconv.FormatInt(c.Storage.PruneDepth, 10) },
+		set: func(c *Config, v string) error {
+			depth, err := strconv.ParseInt(v, 10, 64)
+			c.Storage.PruneDepth = depth
+			return err
+		},
+	},
+	{
+		key: "storage.snapshot_interval", usage: "number of blocks after which a state snapshot is created (0 disables periodic snapshots)",
+		get: func(c *Config) string { return strconv.FormatInt(c.Storage.SnapshotInterval, 10) },
+		set: func(c *Config, v string) error {
+			interval, err := strconv.ParseInt(v, 10, 64)
+			c.Storage.SnapshotInterval = interval
+			return err
+		},
+	},
+	{
+		key: "storage.snapshot_retain", usage: "number of periodic state snapshots that are kept (0 keeps all)",
+		get: func(c *Config) string { return strconv.Itoa(c.Storage.SnapshotRetain) },
+		set: func(c *Config, v string) error {
+			retain, err := strconv.Atoi(v)
+			c.Storage.SnapshotRetain = retain
+			return err
+		},
+	},
+}
+
+// Load registers the configuration flags on the given FlagSet, parses the given
+// arguments and returns the resulting Config. Settings are applied in order of
+// increasing precedence from the defaults, the config file (given by the -config
+// flag or the ESSENSIO_CONFIG environment variable), environment variables
+// and finally command-line flags. The Config is validated before it is returned.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	config := Default()
+
+	// Register a flag for every setting
+	configFile := fs.String("config", os.Getenv(EnvConfigFile), "path of the TOML config file")
+	flags := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		flags[s.flag()] = fs.String(s.flag(), "", fmt.Sprintf("%v (env %v, default %q)", s.usage, s.env(), s.get(config)))
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// Apply the config file
+	if *configFile != "" {
+		if err := config.loadFile(*configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	// Apply environment variables
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env()); ok {
+			if err := s.set(config, value); err != nil {
+				return nil, fmt.Errorf("invalid value '%v' for %v: %w", value, s.env(), err)
+			}
+		}
+	}
+
+	// Apply the flags that were set
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if f.Name == s.flag() && err == nil {
+				if seterr := s.set(config, *flags[s.flag()]); seterr != nil {
+					err = fmt.Errorf("invalid value '%v' for -%v: %w", *flags[s.flag()], s.flag(), seterr)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return config, nil
+}
+
+// loadFile decodes the TOML config file at the given path into the Config.
+// Returns an error if the file contains keys that are not settings.
+func (config *Config) loadFile(path string) error {
+	metadata, err := toml.DecodeFile(path, config)
+	if err != nil {
+		return fmt.Errorf("config file '%v' decode failed: %w", path, err)
+	}
+
+	if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+		return fmt.Errorf("config file '%v' has unknown keys: %v", path, undecoded)
+	}
+
+	return nil
+}
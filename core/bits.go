@@ -0,0 +1,80 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrInvalidBits is returned when the compact encoding of a target is negative, zero or overflows
+var ErrInvalidBits = errors.New("invalid compact target")
+
+// maxTarget is the exclusive upper bound of a target, 2^256
+var maxTarget = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// TargetToBits returns the compact 32-bit encoding of a target. The most significant byte of the
+// encoding is the length of the target in bytes and the lower three bytes are its most significant
+// bytes. The encoding is exact for targets whose significant bits fit into the three bytes, which
+// includes every target generated for a difficulty.
+func TargetToBits(target *big.Int) uint32 {
+	size := uint32(len(target.Bytes()))
+
+	var mantissa uint32
+	if size <= 3 {
+		mantissa = uint32(target.Uint64()) << (8 * (3 - size))
+	} else {
+		mantissa = uint32(new(big.Int).Rsh(target, uint(8*(size-3))).Uint64())
+	}
+
+	// The high bit of the mantissa is a sign bit, so a mantissa
+	// that would set it is shifted into an additional byte
+	if mantissa&0x00800000 != 0 {
+		mantissa >>= 8
+		size++
+	}
+
+	return size<<24 | mantissa
+}
+
+// BitsToTarget returns the target for the given compact encoding.
+// Returns ErrInvalidBits if the target is negative, zero or not less than 2^256.
+func BitsToTarget(bits uint32) (*big.Int, error) {
+	size, mantissa := bits>>24, bits&0x007fffff
+
+	if bits&0x00800000 != 0 {
+		return nil, fmt.Errorf("%w: 0x%08x is negative", ErrInvalidBits, bits)
+	}
+
+	target := big.NewInt(int64(mantissa))
+	if size <= 3 {
+		target.Rsh(target, uint(8*(3-size)))
+	} else {
+		target.Lsh(target, uint(8*(size-3)))
+	}
+
+	if target.Sign() == 0 {
+		return nil, fmt.Errorf("%w: 0x%08x is zero", ErrInvalidBits, bits)
+	}
+
+	if target.Cmp(maxTarget) >= 0 {
+		return nil, fmt.Errorf("%w: 0x%08x overflows", ErrInvalidBits, bits)
+	}
+
+	return target, nil
+}
+
+// DifficultyToBits returns the compact encoding of the target for the given difficulty
+func DifficultyToBits(difficulty uint8) uint32 {
+	return TargetToBits(GenerateTarget(difficulty))
+}
+
+// Work returns the expected number of hashes required to mine a Block with the given compact
+// target, which is 2^256 / (target + 1). Returns zero if the compact target is invalid.
+func Work(bits uint32) *big.Int {
+	target, err := BitsToTarget(bits)
+	if err != nil {
+		return new(big.Int)
+	}
+
+	return new(big.Int).Div(maxTarget, target.Add(target, big.NewInt(1)))
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// hexInt returns the big.Int of the given hex string without a prefix
+func hexInt(t *testing.T, s string) *big.Int {
+	t.Helper()
+
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		t.Fatalf("invalid hex integer '%v'", s)
+	}
+
+	return n
+}
+
+func TestBitsToTarget(t *testing.T) {
+	tests := []struct {
+		bits   uint32
+		target string
+	}{
+		{0x01123456, "12"},
+		{0x02123456, "1234"},
+		{0x03123456, "123456"},
+		{0x04123456, "12345600"},
+		{0x05009234, "92340000"},
+		{0x1b0404cb, "404cb000000000000000000000000000000000000000000000000"},
+		{0x1d00ffff, "ffff0000000000000000000000000000000000000000000000000000"},
+		{0x207fffff, "7fffff0000000000000000000000000000000000000000000000000000000000"},
+		{0x21008000, "8000000000000000000000000000000000000000000000000000000000000000"},
+	}
+
+	for _, test := range tests {
+		target, err := BitsToTarget(test.bits)
+		if err != nil {
+			t.Fatalf("BitsToTarget(0x%08x): %v", test.bits, err)
+		}
+
+		if expected := hexInt(t, test.target); target.Cmp(expected) != 0 {
+			t.Errorf("BitsToTarget(0x%08x): expected %x, got %x", test.bits, expected, target)
+		}
+
+		// The target round trips through its own encoding, which may differ from
+		// the given encoding in the mantissa bytes that are shifted out
+		if decoded, err := BitsToTarget(TargetToBits(target)); err != nil || decoded.Cmp(target) != 0 {
+			t.Errorf("BitsToTarget(TargetToBits(%x)): got %x (%v)", target, decoded, err)
+		}
+	}
+}
+
+func TestBitsToTargetInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		bits uint32
+	}{
+		{"zero", 0x00000000},
+		{"zero mantissa", 0x1d000000},
+		{"shifted out", 0x01003456},
+		{"negative", 0x04923456},
+		{"negative small", 0x01fedcba},
+		{"overflow", 0x21010000},
+		{"overflow large size", 0xff123456},
+	}
+
+	for _, test := range tests {
+		if _, err := BitsToTarget(test.bits); !errors.Is(err, ErrInvalidBits) {
+			t.Errorf("%v: BitsToTarget(0x%08x): expected ErrInvalidBits, got %v", test.name, test.bits, err)
+		}
+	}
+}
+
+func TestTargetToBits(t *testing.T) {
+	tests := []struct {
+		target string
+		bits   uint32
+	}{
+		{"1", 0x01010000},
+		{"7f", 0x017f0000},
+		// The high bit of the mantissa is a sign bit
+		{"80", 0x02008000},
+		{"123456", 0x03123456},
+		{"12345600", 0x04123456},
+		// Bytes beyond the three most significant bytes are truncated
+		{"123456789a", 0x05123456},
+		{"ffff0000000000000000000000000000000000000000000000000000", 0x1d00ffff},
+	}
+
+	for _, test := range tests {
+		if bits := TargetToBits(hexInt(t, test.target)); bits != test.bits {
+			t.Errorf("TargetToBits(%v): expected 0x%08x, got 0x%08x", test.target, test.bits, bits)
+		}
+	}
+}
+
+func TestDifficultyBitsRoundTrip(t *testing.T) {
+	for difficulty := 1; difficulty <= 255; difficulty++ {
+		target := GenerateTarget(uint8(difficulty))
+		bits := DifficultyToBits(uint8(difficulty))
+
+		decoded, err := BitsToTarget(bits)
+		if err != nil {
+			t.Fatalf("difficulty %v: BitsToTarget(0x%08x): %v", difficulty, bits, err)
+		}
+
+		// The target of a difficulty is a power of two, which is encoded exactly
+		if decoded.Cmp(target) != 0 {
+			t.Fatalf("difficulty %v: expected target %x, got %x", difficulty, target, decoded)
+		}
+
+		// The work of a target 2^(256-d) is 2^256 / (2^(256-d) + 1), which rounds down to 2^d - 1 while d is at most 128
+		if difficulty > 128 {
+			continue
+		}
+
+		expected := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(difficulty)), big.NewInt(1))
+		if work := Work(bits); work.Cmp(expected) != 0 {
+			t.Fatalf("difficulty %v: expected work %v, got %v", difficulty, expected, work)
+		}
+	}
+}
+
+func TestWork(t *testing.T) {
+	tests := []struct {
+		bits uint32
+		work string
+	}{
+		// The work of the Bitcoin genesis block
+		{0x1d00ffff, "100010001"},
+		{0x207fffff, "2"},
+		{0x21008000, "1"},
+		// The work of an invalid target is zero
+		{0x04923456, "0"},
+		{0x21010000, "0"},
+	}
+
+	for _, test := range tests {
+		if work := Work(test.bits); work.Cmp(hexInt(t, test.work)) != 0 {
+			t.Errorf("Work(0x%08x): expected 0x%v, got 0x%x", test.bits, test.work, work)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package core
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -8,6 +10,21 @@ import (
 	"github.com/manishmeganathan/essensio/common"
 )
 
+var (
+	// ErrInvalidBlockHash is returned when the hash of a Block does not match its header
+	ErrInvalidBlockHash = errors.New("block hash does not match header")
+	// ErrInvalidProofOfWork is returned when the hash of a Block does not meet its target
+	ErrInvalidProofOfWork = errors.New("block hash does not meet target")
+	// ErrInvalidSummary is returned when the summary of a Block does not match its transactions
+	ErrInvalidSummary = errors.New("block summary does not match transactions")
+	// ErrInvalidStateRoot is returned when the state root of a Block does not match the chain state after it
+	ErrInvalidStateRoot = errors.New("block state root does not match chain state")
+	// ErrWrongChain is returned when a Block or Transaction belongs to a chain with a different chain ID
+	ErrWrongChain = errors.New("wrong chain id")
+	// ErrInvalidCoinbase is returned when a Block does not begin with a single valid coinbase transaction
+	ErrInvalidCoinbase = errors.New("invalid coinbase transaction")
+)
+
 // Block is a struct that represents a Block of data in the BlockChain
 type Block struct {
 	BlockHeader
@@ -34,9 +51,10 @@ func (block *Block) String() string {
 	return s.String()
 }
 
-// NewBlock generates a new Block for a given set of Transactions,
-// the hash of the previous block and the block height
-func NewBlock(txns Transactions, priori common.Hash, height int64) (*Block, error) {
+// NewBlock generates a new Block on the given chain for a given set of Transactions, the hash of the
+// previous block, the state root after the Transactions, the block height and the compact target.
+// Mining is abandoned if the context is cancelled.
+func NewBlock(ctx context.Context, chainID uint64, txns Transactions, priori, stateRoot common.Hash, height int64, bits uint32) (*Block, error) {
 	block := &Block{
 		BlockTxns:   txns,
 		BlockHeight: height,
@@ -48,19 +66,112 @@ func NewBlock(txns Transactions, priori common.Hash, height int64) (*Block, erro
 		return nil, fmt.Errorf("failed to generate transaction summary: %w", err)
 	}
 
-	// Create a BlockHeader with the priori and summary
-	header := NewBlockHeader(priori, summary)
+	// Create a BlockHeader with the priori, summary and state root
+	header := NewBlockHeader(chainID, priori, summary, stateRoot, bits)
 	block.BlockHeader = header
 
 	// Mine the Block & set the block hash
-	block.BlockHash = block.BlockHeader.Mint()
+	if block.BlockHash, err = block.BlockHeader.Mint(ctx); err != nil {
+		return nil, err
+	}
 
 	return block, nil
 }
 
-// GenesisBlock returns a Block that represents a Genesis Block with just a Coinbase Transaction.
-func GenesisBlock() (*Block, error) {
-	return NewBlock(Transactions{newCoinbaseTransaction(common.MinerAddress())}, common.NullHash(), 0)
+// GenesisCoinbase returns the Coinbase Transaction of a Genesis Block
+// that pays the subsidy of the chain to the given miner address
+func GenesisCoinbase(miner common.Address, params ChainParams) *Transaction {
+	return NewCoinbaseTransaction(params.ChainID, miner, 0, params.Subsidy(0))
+}
+
+// GenesisBlock returns a Block that represents a Genesis Block with just the GenesisCoinbase of the given
+// miner address, mined at the given difficulty. The target of the Genesis Block is the target of every Block on its chain.
+// The state root must be the root of the chain state after the coinbase.
+func GenesisBlock(ctx context.Context, miner common.Address, params ChainParams, stateRoot common.Hash, difficulty uint8) (*Block, error) {
+	coinbase := GenesisCoinbase(miner, params)
+	return NewBlock(ctx, params.ChainID, Transactions{coinbase}, common.NullHash(), stateRoot, 0, DifficultyToBits(difficulty))
+}
+
+// Verify checks the integrity of the Block independently of the chain it belongs to.
+// The header of the Block must be valid (see Header.Verify) and its summary must match
+// the transactions of the Block, which must all be sane and have the chain ID of the Block.
+// The coinbase of the Block depends on its chain and is checked by VerifyCoinbase.
+func (block *Block) Verify() error {
+	if err := block.Header().Verify(); err != nil {
+		return err
+	}
+
+	summary, err := GenerateSummary(block.BlockTxns)
+	if err != nil {
+		return fmt.Errorf("failed to generate transaction summary: %w", err)
+	}
+
+	if summary != block.Summary {
+		return ErrInvalidSummary
+	}
+
+	for idx, txn := range block.BlockTxns {
+		if txn.ChainID != block.ChainID {
+			return fmt.Errorf("transaction %v: %w: expected %v, got %v", idx, ErrWrongChain, block.ChainID, txn.ChainID)
+		}
+
+		if err := txn.CheckSanity(); err != nil {
+			return fmt.Errorf("transaction %v: %w", idx, err)
+		}
+	}
+
+	return nil
+}
+
+// VerifyCoinbase checks that the first transaction of the Block is its only coinbase transaction,
+// that its nonce is the height of the Block and that its value is the given subsidy and the fees
+// of the other transactions of the Block.
+func (block *Block) VerifyCoinbase(subsidy common.Amount) error {
+	if len(block.BlockTxns) == 0 || !block.BlockTxns[0].IsCoinbase() {
+		return fmt.Errorf("%w: block has no coinbase", ErrInvalidCoinbase)
+	}
+
+	for idx, txn := range block.BlockTxns[1:] {
+		if txn.IsCoinbase() {
+			return fmt.Errorf("%w: transaction %v is a second coinbase", ErrInvalidCoinbase, idx+1)
+		}
+	}
+
+	coinbase := block.BlockTxns[0]
+	if coinbase.Nonce != uint64(block.BlockHeight) {
+		return fmt.Errorf("%w: expected nonce %v, got %v", ErrInvalidCoinbase, block.BlockHeight, coinbase.Nonce)
+	}
+
+	if coinbase.Fee != 0 {
+		return fmt.Errorf("%w: coinbase has a fee", ErrInvalidCoinbase)
+	}
+
+	fees, err := block.BlockTxns.Fees()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCoinbase, err)
+	}
+
+	reward, err := subsidy.Add(fees)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCoinbase, err)
+	}
+
+	if coinbase.Value != reward {
+		return fmt.Errorf("%w: expected value %v, got %v", ErrInvalidCoinbase, reward, coinbase.Value)
+	}
+
+	return nil
+}
+
+// Header returns the Header of the Block, which omits its Transactions
+func (block *Block) Header() *Header {
+	return &Header{block.BlockHeader, block.BlockHeight, block.BlockHash, block.TxnCount()}
+}
+
+// AssembleBlock returns the Block with the given Header and Transactions.
+// The Transactions are not checked against the summary of the Header.
+func AssembleBlock(header *Header, txns Transactions) *Block {
+	return &Block{header.BlockHeader, txns, header.BlockHeight, header.BlockHash}
 }
 
 // TxnCount returns the number of Transaction items in the Block
@@ -0,0 +1,34 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+// The hash of a Block is computed over the canonical encoding of its header rather than its gob encoding.
+// This is a consensus change: the Proof of Work of a Block is over its hash, so chains whose blocks were
+// hashed from their gob encoding fail verification and must be reset (see the README on Block hashes).
+
+// Hash returns the SHA-256 hash of the BlockHeader's canonical encoding.
+// The hash of a mined BlockHeader is the hash of its Block.
+func (header *BlockHeader) Hash() common.Hash {
+	return common.Hash256(header.canonical())
+}
+
+// canonical returns the canonical encoding of the BlockHeader, which is its fields in order
+// with integers in big endian. Unlike its gob encoding, it does not depend
+// on the types previously encoded by the process, so it can be hashed.
+func (header *BlockHeader) canonical() []byte {
+	var buffer bytes.Buffer
+	_ = binary.Write(&buffer, binary.BigEndian, header.ChainID)
+	buffer.Write(header.Priori.Bytes())
+	buffer.Write(header.Summary.Bytes())
+	buffer.Write(header.StateRoot.Bytes())
+	_ = binary.Write(&buffer, binary.BigEndian, header.Timestamp)
+	_ = binary.Write(&buffer, binary.BigEndian, header.Bits)
+	_ = binary.Write(&buffer, binary.BigEndian, header.Nonce)
+
+	return buffer.Bytes()
+}
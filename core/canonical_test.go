@@ -0,0 +1,44 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+func TestBlockHeaderHash(t *testing.T) {
+	header := BlockHeader{
+		ChainID:   ChainID,
+		Priori:    common.Hash256([]byte("priori")),
+		Summary:   common.Hash256([]byte("summary")),
+		StateRoot: common.Hash256([]byte("state")),
+		Timestamp: 1700000000,
+		Bits:      0x1f00ffff,
+		Nonce:     42,
+	}
+
+	// The encoding has a fixed size, so no two headers share it
+	if size := len(header.canonical()); size != 124 {
+		t.Fatalf("canonical: expected 124 bytes, got %v", size)
+	}
+
+	// The hash commits to every field of the header
+	modified := []func(*BlockHeader){
+		func(h *BlockHeader) { h.ChainID++ },
+		func(h *BlockHeader) { h.Priori = common.Hash256([]byte("other")) },
+		func(h *BlockHeader) { h.Summary = common.Hash256([]byte("other")) },
+		func(h *BlockHeader) { h.StateRoot = common.Hash256([]byte("other")) },
+		func(h *BlockHeader) { h.Timestamp++ },
+		func(h *BlockHeader) { h.Bits++ },
+		func(h *BlockHeader) { h.Nonce++ },
+	}
+
+	for idx, modify := range modified {
+		other := header
+		modify(&other)
+
+		if other.Hash() == header.Hash() {
+			t.Errorf("Hash: expected a different hash after modifying field %v", idx)
+		}
+	}
+}
@@ -1,8 +1,6 @@
 package chainmgr
 
 import (
-	"fmt"
-
 	"github.com/manishmeganathan/essensio/common"
 	"github.com/manishmeganathan/essensio/core"
 	"github.com/manishmeganathan/essensio/db"
@@ -13,28 +11,22 @@ import (
 type ChainIterator struct {
 	// Represents the hash of the current Block on the iterator
 	cursor common.Hash
-	// Represents the database containing all Block data indexed by their hash
-	database *db.Database
+	// Represents the chain whose Blocks are iterated over
+	chain *ChainManager
 }
 
 // NewIterator constructs a new ChainIterator for the BlockChain.
 func (chain *ChainManager) NewIterator() *ChainIterator {
-	return &ChainIterator{chain.Head, chain.db}
+	return &ChainIterator{chain.Head(), chain}
 }
 
 // Next returns the next Block in the ChainIterator.
-// Returns an error if a Block is not found or is invalid.
+// Returns an error if a Block is not found, is invalid or its body has been pruned.
 func (iter *ChainIterator) Next() (*core.Block, error) {
 	// Find the Block with hash represented by the iterator cursor
-	data, err := iter.database.GetEntry(iter.cursor.Bytes())
+	block, err := iter.chain.GetBlockByHash(iter.cursor)
 	if err != nil {
-		return nil, fmt.Errorf("cannot finding block '%x': %w", iter.cursor, err)
-	}
-
-	// Create a new Block and deserialize the block data into it
-	block := new(core.Block)
-	if err := block.Deserialize(data); err != nil {
-		return nil, fmt.Errorf("block deserialize failed: %w", err)
+		return nil, err
 	}
 
 	// Update the iterator cursor to the hash of the previous Block
@@ -48,3 +40,38 @@ func (iter *ChainIterator) Done() bool {
 	// If the cursor hash is null, the ChainIterator is done
 	return iter.cursor == common.NullHash()
 }
+
+// HeaderIterator is a struct that can iterate over the Header of each
+// Block in a blockchain without reading the Transactions of the Blocks.
+type HeaderIterator struct {
+	// Represents the hash of the current Block on the iterator
+	cursor common.Hash
+	// Represents the database containing all Block headers indexed by their hash
+	database *db.Database
+}
+
+// NewHeaderIterator constructs a new HeaderIterator for the BlockChain.
+func (chain *ChainManager) NewHeaderIterator() *HeaderIterator {
+	return &HeaderIterator{chain.Head(), chain.db}
+}
+
+// Next returns the next Header in the HeaderIterator.
+// Returns an error if a Header is not found or is invalid.
+func (iter *HeaderIterator) Next() (*core.Header, error) {
+	// Find the Header with hash represented by the iterator cursor
+	header, err := readHeader(iter.database, iter.cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the iterator cursor to the hash of the previous Block
+	iter.cursor = header.Priori
+	return header, nil
+}
+
+// Done returns whether the HeaderIterator has
+// reached the Genesis Block of the chain.
+func (iter *HeaderIterator) Done() bool {
+	// If the cursor hash is null, the HeaderIterator is done
+	return iter.cursor == common.NullHash()
+}
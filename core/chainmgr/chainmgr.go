@@ -1,98 +1,384 @@
 package chainmgr
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"math"
+	"sync"
 
 	"github.com/manishmeganathan/essensio/common"
 	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/state"
 	"github.com/manishmeganathan/essensio/db"
 )
 
 var (
-	ChainHeadKey   = []byte("state-chainhead")
-	ChainHeightKey = []byte("state-chainheight")
+	ChainHeadKey    = []byte("state-chainhead")
+	ChainHeightKey  = []byte("state-chainheight")
+	ChainParamsKey  = []byte("state-chainparams")
+	PrunedHeightKey = []byte("state-prunedheight")
+	StateRootKey    = []byte("state-stateroot")
 )
 
+var (
+	// ErrChainStopped is returned when a Block is added to a ChainManager that has been stopped
+	ErrChainStopped = errors.New("chain manager stopped")
+	// ErrNoMiner is returned when a Block is mined by a ChainManager without a valid miner address
+	ErrNoMiner = errors.New("no valid miner address")
+)
+
+// Config contains the settings of a ChainManager
+type Config struct {
+	// Represents the directory of the blockchain database
+	DataDir string
+	// Represents the address that receives the rewards for mining Blocks.
+	// Blocks cannot be mined if it is not a valid address.
+	MinerAddress common.Address
+	// Represents the Proof of Work difficulty of a new chain, which fixes the target of its Genesis Block.
+	// Blocks are mined at the target of the Genesis Block of an existing chain.
+	Difficulty uint8
+	// Represents the Genesis Block to initialize a new chain with.
+	// A Genesis Block is mined for the miner address if nil.
+	Genesis *core.Block
+	// Represents the consensus parameters to initialize a new chain with.
+	// The parameters of an existing chain are loaded from its database.
+	Params core.ChainParams
+	// Represents the number of recent Blocks whose bodies are kept.
+	// The bodies of all Blocks are kept if zero, which is the archive mode.
+	PruneDepth int64
+	// Represents the number of Blocks after which a state snapshot is created.
+	// State snapshots are only created on demand if zero.
+	SnapshotInterval int64
+	// Represents the number of periodic state snapshots that are kept.
+	// All state snapshots are kept if zero.
+	SnapshotRetain int
+}
+
 // ChainManager represents a blockchain as a set of Blocks
 type ChainManager struct {
+	// Represents the settings of the chain
+	config Config
+
 	// Represents the database of blockchain data
 	// This contains the state and blocks of the blockchain
 	db *db.Database
 
 	// Represents the hash of the last Block
-	Head common.Hash
+	head common.Hash
 	// Represents the Height of the chain. Last block Height+1
-	Height int64
+	height int64
+	// Represents the state root of the chain head
+	stateRoot common.Hash
+	// Represents the height of the lowest Block whose body is kept.
+	// The bodies of all Blocks below it have been pruned.
+	pruned int64
+	// Represents the compact Proof of Work target of the chain, which is the target of its Genesis Block.
+	// It is fixed once the chain is loaded or initialized.
+	bits uint32
+
+	// Represents the lock that serializes the addition of Blocks
+	mutex sync.Mutex
+	// Represents the lock that guards the chain head, height, state root and pruned height,
+	// so that they can be read while a Block is being added
+	tipMutex sync.RWMutex
+	// Represents the feed of chain Events for subscribers
+	events feed
+
+	// Represents the signal to abandon any Block being mined
+	quit     chan struct{}
+	quitOnce sync.Once
+	// Represents whether the database has been closed
+	stopped bool
 }
 
 // String implements the Stringer interface for BlockChain
 func (chain *ChainManager) String() string {
-	return fmt.Sprintf("Chain Head: %x || Chain Height: %v", chain.Head, chain.Height)
+	head, height := chain.Tip()
+	return fmt.Sprintf("Chain Head: %x || Chain Height: %v", head, height)
 }
 
-// AddBlock generates and appends a Block to the chain for a given string data.
-// The generated block is stored in the database. Any error that occurs is returned.
-func (chain *ChainManager) AddBlock(txns core.Transactions) error {
-	// Create a new Block with the given transactions
-	block, err := core.NewBlock(txns, chain.Head, chain.Height)
+// Head returns the hash of the Block at the chain head
+func (chain *ChainManager) Head() common.Hash {
+	chain.tipMutex.RLock()
+	defer chain.tipMutex.RUnlock()
+
+	return chain.head
+}
+
+// Height returns the height of the chain, which is the number of Blocks in it
+func (chain *ChainManager) Height() int64 {
+	chain.tipMutex.RLock()
+	defer chain.tipMutex.RUnlock()
+
+	return chain.height
+}
+
+// Tip returns the hash of the Block at the chain head and the height of the chain.
+// They are read together, so that the head is always the Block at height-1.
+func (chain *ChainManager) Tip() (common.Hash, int64) {
+	chain.tipMutex.RLock()
+	defer chain.tipMutex.RUnlock()
+
+	return chain.head, chain.height
+}
+
+// currentRoot returns the state root of the chain head
+func (chain *ChainManager) currentRoot() common.Hash {
+	chain.tipMutex.RLock()
+	defer chain.tipMutex.RUnlock()
+
+	return chain.stateRoot
+}
+
+// AddBlock generates and appends a Block to the chain for a given set of transactions.
+// The Block begins with a coinbase that pays the subsidy and the transaction fees to the miner.
+// The transactions are applied onto the chain state and are rejected if any of them are invalid.
+// The generated block is stored in the database and returned. Any error that occurs is returned.
+// Mining is abandoned if the context is cancelled or the ChainManager is stopped.
+func (chain *ChainManager) AddBlock(ctx context.Context, txns core.Transactions) (*core.Block, error) {
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+
+	// Reject new Blocks once the ChainManager is interrupted
+	select {
+	case <-chain.quit:
+		return nil, ErrChainStopped
+	default:
+	}
+
+	// Cancel the context if the ChainManager is interrupted
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-chain.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// The coinbase of the block must pay a valid address
+	if err := chain.config.MinerAddress.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoMiner, err)
+	}
+
+	// Only the block's own coinbase may mint tokens, transactions must be sane,
+	// follow the ledger of the chain and transactions for other chains must not be replayed
+	for idx, txn := range txns {
+		if txn.IsCoinbase() {
+			return nil, fmt.Errorf("transaction %v: %w: coinbase transactions cannot be added", idx, core.ErrInvalidCoinbase)
+		}
+
+		if err := txn.CheckSanity(); err != nil {
+			return nil, fmt.Errorf("transaction %v: %w", idx, err)
+		}
+
+		if txn.ChainID != chain.config.Params.ChainID {
+			return nil, fmt.Errorf("transaction %v: %w: expected %v, got %v", idx, core.ErrWrongChain, chain.config.Params.ChainID, txn.ChainID)
+		}
+
+		if err := chain.config.Params.CheckLedger(txn); err != nil {
+			return nil, fmt.Errorf("transaction %v: %w", idx, err)
+		}
+	}
+
+	// Collect the subsidy and the fees of the transactions for the miner
+	subsidy := chain.config.Params.Subsidy(chain.height)
+	fees, err := txns.Fees()
 	if err != nil {
-		return fmt.Errorf("failed to generate block: %w", err)
+		return nil, fmt.Errorf("%w: %v", core.ErrInvalidCoinbase, err)
 	}
 
-	// Serialize the Block
-	blockData, err := block.Serialize()
+	reward, err := subsidy.Add(fees)
 	if err != nil {
-		return fmt.Errorf("block serialize failed: %w", err)
+		return nil, fmt.Errorf("%w: %v", core.ErrInvalidCoinbase, err)
 	}
 
-	// Add block to db
-	if err := chain.db.SetEntry(block.BlockHash.Bytes(), blockData); err != nil {
-		return fmt.Errorf("block store to db failed: %w", err)
+	// Apply the transactions onto the chain state
+	chainstate := chain.newState(chain.stateRoot)
+	if err := chainstate.ApplyTransactions(txns, chain.height); err != nil {
+		return nil, fmt.Errorf("state transition failed: %w", err)
+	}
+
+	// Pay the subsidy and fees to the miner with a coinbase at the start of the block
+	coinbase := core.NewCoinbaseTransaction(chain.config.Params.ChainID, chain.config.MinerAddress, chain.height, reward)
+	if err := chainstate.ApplyTransaction(coinbase, chain.height); err != nil {
+		return nil, fmt.Errorf("state transition failed: %w", err)
+	}
+
+	blocktxns := append(core.Transactions{coinbase}, txns...)
+
+	// Compute the state root after the block for its header
+	root, err := chainstate.Root()
+	if err != nil {
+		return nil, fmt.Errorf("state root computation failed: %w", err)
 	}
 
-	// Update the chain head with the new block hash and increment chain height
-	chain.Head = block.BlockHash
-	chain.Height++
+	// Notify subscribers of the transactions waiting to be mined, once they have passed the sanity,
+	// ledger and state checks. Transactions of a request that has been cancelled are never mined.
+	if ctx.Err() == nil {
+		chain.events.publish(PendingTxnsEvent{txns})
+	}
+
+	// Create a new Block with the coinbase and the given transactions
+	block, err := core.NewBlock(ctx, chain.config.Params.ChainID, blocktxns, chain.head, root, chain.height, chain.bits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate block: %w", err)
+	}
+
+	// Commit the block and its state to the db
+	if err := chain.commitBlock(block, chainstate); err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
+
+// commitBlock atomically stores the given Block, its index entries, the modified chain state and the new
+// chain head and height into the database, and deletes the bodies of the Blocks that fall below the prune
+// depth of the chain. The chain state below the kept Blocks is deleted periodically (see pruneState).
+func (chain *ChainManager) commitBlock(block *core.Block, chainstate *state.State) error {
+	batch := db.NewBatch()
+
+	// Add the block header and body to the batch
+	if err := storeBlock(batch, block); err != nil {
+		return err
+	}
+
+	// Add the work of the block to the cumulative work of its chain
+	work := block.Work()
+	if block.Priori != common.NullHash() {
+		priori, err := chain.GetChainWork(block.Priori)
+		if err != nil {
+			return fmt.Errorf("chain work retrieve failed: %w", err)
+		}
+
+		work.Add(work, priori)
+	}
+
+	// Add block to the indexes
+	if err := indexBlock(batch, block, work); err != nil {
+		return err
+	}
+
+	// Add modified accounts and state trie nodes to the batch
+	if err := chainstate.Commit(batch); err != nil {
+		return fmt.Errorf("chain state commit failed: %w", err)
+	}
 
-	// Sync the chain state into the DB
-	if err := chain.syncState(); err != nil {
+	// Prune the bodies of the blocks that fall below the prune depth
+	previous := chain.pruned
+	pruned, err := chain.pruneBodies(batch, block.BlockHeight+1, math.MaxInt64)
+	if err != nil {
+		return fmt.Errorf("block prune failed: %w", err)
+	}
+
+	// Set the block as the chain head, which is only
+	// written if the block and its state are written
+	if err := syncState(batch, block.BlockHash, block.BlockHeight+1); err != nil {
 		return fmt.Errorf("chain state sync failed: %w", err)
 	}
 
+	// Write the batch to the db
+	if err := chain.db.WriteBatch(batch); err != nil {
+		return fmt.Errorf("block store to db failed: %w", err)
+	}
+
+	// Update the chain head with the new block hash and set the chain height
+	oldHead := chain.head
+	chain.tipMutex.Lock()
+	chain.pruned = pruned
+	chain.head = block.BlockHash
+	chain.height = block.BlockHeight + 1
+	chain.stateRoot = block.StateRoot
+	chain.tipMutex.Unlock()
+
+	// Delete the chain state below the kept Blocks once the bodies of enough Blocks have been pruned.
+	// A failed prune is only logged, because the block has already been committed.
+	if pruned > previous && pruned%pruneStateInterval == 0 {
+		if err := chain.pruneState(); err != nil {
+			log.Println("State Prune Failed:", err)
+		}
+	}
+
+	// Notify subscribers of the new chain head.
+	// The genesis block does not replace a previous head.
+	if oldHead != common.NullHash() && block.Priori != oldHead {
+		chain.events.publish(ReorgEvent{oldHead, block.BlockHash})
+	}
+	chain.events.publish(NewHeadEvent{block})
+
+	// Create a periodic state snapshot if it is due
+	chain.autoSnapshot()
+
 	return nil
 }
 
-// NewChainManager returns a new BlockChain with an initialized
-// Genesis Block with the provided genesis data.
-func NewChainManager() (*ChainManager, error) {
+// newState returns the chain state with the given state root
+func (chain *ChainManager) newState(root common.Hash) *state.State {
+	return state.New(chain.db, root, chain.config.Params.Ledger)
+}
+
+// GetAccount returns the current state of the Account for the given address
+func (chain *ChainManager) GetAccount(address common.Address) (*state.Account, error) {
+	return chain.newState(chain.currentRoot()).GetAccount(address)
+}
+
+// GetCoins returns the current unspent outputs locked to the given address.
+// Only chains with the UTXO ledger have unspent outputs.
+func (chain *ChainManager) GetCoins(address common.Address) ([]core.Coin, error) {
+	return chain.newState(chain.currentRoot()).GetCoins(address)
+}
+
+// NewChainManager returns a new BlockChain for the given Config. If the database does not exist,
+// it is initialized with the Genesis Block of the Config or a newly mined Genesis Block,
+// which is abandoned if the context is cancelled.
+func NewChainManager(ctx context.Context, config Config) (*ChainManager, error) {
+	var err error
+
 	// Create a new ChainManager object
-	chain := new(ChainManager)
+	chain := &ChainManager{config: config, quit: make(chan struct{})}
 
-	// Check if the database already exists
-	if db.Exists() {
+	// Open the database
+	if chain.db, err = db.Open(config.DataDir); err != nil {
+		return nil, err
+	}
+
+	// Check if the database already contains a chain. The database of a light
+	// chain cannot be opened and a database without a chain head was never fully initialized.
+	if _, err = chain.db.GetEntry(LightChainKey); err == nil {
+		err = ErrLightChain
+
+	} else if !errors.Is(err, db.ErrKeyNotFound) {
+		err = fmt.Errorf("failed to check for light chain: %w", err)
+
+	} else if _, err = chain.db.GetEntry(ChainHeadKey); err == nil {
 		// Load blockchain state from database
-		if err := chain.load(); err != nil {
-			return nil, fmt.Errorf("failed to load existing blockchain: %w", err)
+		if err = chain.load(); err != nil {
+			err = fmt.Errorf("failed to load existing blockchain: %w", err)
 		}
 
-	} else {
+	} else if errors.Is(err, db.ErrKeyNotFound) {
 		// Initialize blockchain state and database
-		if err := chain.init(); err != nil {
-			return nil, fmt.Errorf("failed to initialize new blockchain: $%w", err)
+		if err = chain.init(ctx); err != nil {
+			err = fmt.Errorf("failed to initialize new blockchain: %w", err)
 		}
 	}
 
+	if err != nil {
+		_ = chain.db.Close()
+		return nil, err
+	}
+
 	return chain, nil
 }
 
 // load restarts a ChainManager from the database.
 // It updates its in-memory chain state chain information from the DB.
-func (chain *ChainManager) load() (err error) {
-	// Open the database
-	if chain.db, err = db.Open(); err != nil {
-		return err
-	}
-
+func (chain *ChainManager) load() error {
 	// Get the chain head and set it
 	head, err := chain.db.GetEntry(ChainHeadKey)
 	if err != nil {
@@ -112,74 +398,222 @@ func (chain *ChainManager) load() (err error) {
 	}
 
 	// Cast the object into an int64 and set it
-	chain.Height = *object.(*int64)
+	chain.height = *object.(*int64)
 	// Convert the head bytes into a Hash and set it
-	chain.Head = common.BytesToHash(head)
+	chain.head = common.BytesToHash(head)
+
+	// Get the chain parameters. Chains initialized before the parameters
+	// were stored keep the parameters of the Config.
+	params, err := chain.db.GetEntry(ChainParamsKey)
+	switch {
+	case err == nil:
+		object, err := common.GobDecode(params, new(core.ChainParams))
+		if err != nil {
+			return fmt.Errorf("error deserializing chain params: %w", err)
+		}
+
+		chain.config.Params = *object.(*core.ChainParams)
+
+	case !errors.Is(err, db.ErrKeyNotFound):
+		return fmt.Errorf("chain params retrieve failed: %w", err)
+	}
+
+	// Move the blocks into separate headers and bodies if they are stored whole
+	if err := chain.migrateBlocks(); err != nil {
+		return fmt.Errorf("block migration failed: %w", err)
+	}
+
+	// Get the state root from the header of the chain head. The state trie
+	// of a chain whose headers do not commit to their state is built instead.
+	header, err := chain.GetHeaderByHash(chain.head)
+	if err != nil {
+		return fmt.Errorf("chain head header retrieve failed: %w", err)
+	}
+
+	chain.stateRoot = header.StateRoot
+	if chain.stateRoot == common.NullHash() {
+		if chain.stateRoot, err = chain.migrateStateRoot(); err != nil {
+			return fmt.Errorf("state root migration failed: %w", err)
+		}
+	}
+
+	// Rebuild the chain indexes if they are missing
+	if err := chain.reindex(); err != nil {
+		return fmt.Errorf("chain reindex failed: %w", err)
+	}
+
+	// Every Block on the chain has the target of the Genesis Block
+	genesis, err := chain.GetHeaderByHeight(0)
+	if err != nil {
+		return fmt.Errorf("genesis header retrieve failed: %w", err)
+	}
+
+	chain.bits = genesis.Bits
+
+	// Prune the bodies of the blocks below the prune depth,
+	// which may have changed since the chain was last loaded
+	if err := chain.loadPruned(); err != nil {
+		return err
+	}
+
+	if err := chain.prune(); err != nil {
+		return err
+	}
 
 	return nil
 }
 
+// migrateStateRoot builds the state trie of a chain whose chain head has no state root from its Accounts
+// and unspent outputs, which is the case for databases created before block headers had a state root.
+// The root of the trie is recorded under StateRootKey, so that it is only built once, and it is the state
+// root of the chain head until a Block with a state root is added. Returns the state root of the chain head.
+func (chain *ChainManager) migrateStateRoot() (common.Hash, error) {
+	data, err := chain.db.GetEntry(StateRootKey)
+	if err == nil {
+		return common.BytesToHash(data), nil
+	} else if !errors.Is(err, db.ErrKeyNotFound) {
+		return common.NullHash(), fmt.Errorf("state root retrieve failed: %w", err)
+	}
+
+	batch := db.NewBatch()
+	root, err := state.BuildTrie(chain.db, batch)
+	if err != nil {
+		return common.NullHash(), fmt.Errorf("state trie build failed: %w", err)
+	}
+
+	batch.Set(StateRootKey, root.Bytes())
+	if err := chain.db.WriteBatch(batch); err != nil {
+		return common.NullHash(), fmt.Errorf("state trie store failed: %w", err)
+	}
+
+	return root, nil
+}
+
 // init initializes a new chain in the database.
 // It generates a Genesis Block and adds it to DB and updates all chain state data.
-func (chain *ChainManager) init() (err error) {
-	// Open the database
-	if chain.db, err = db.Open(); err != nil {
-		return err
+func (chain *ChainManager) init(ctx context.Context) error {
+	if err := chain.config.Params.Validate(); err != nil {
+		return fmt.Errorf("invalid chain params: %w", err)
 	}
 
-	fmt.Println(">>>> New Blockchain Initialization. Creating Genesis Block <<<<")
+	chainstate := chain.newState(common.NullHash())
 
-	// Create Genesis Block
-	genesisBlock, err := core.GenesisBlock()
-	if err != nil {
-		return fmt.Errorf("genesis block generation failed: %w", err)
+	genesisBlock := chain.config.Genesis
+	if genesisBlock == nil {
+		if err := chain.config.MinerAddress.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrNoMiner, err)
+		}
+
+		fmt.Println(">>>> New Blockchain Initialization. Creating Genesis Block <<<<")
+
+		// Apply the Genesis coinbase onto the empty chain state
+		if err := chainstate.ApplyTransaction(core.GenesisCoinbase(chain.config.MinerAddress, chain.config.Params), 0); err != nil {
+			return fmt.Errorf("genesis state transition failed: %w", err)
+		}
+
+		root, err := chainstate.Root()
+		if err != nil {
+			return fmt.Errorf("state root computation failed: %w", err)
+		}
+
+		// Create Genesis Block
+		if genesisBlock, err = core.GenesisBlock(ctx, chain.config.MinerAddress, chain.config.Params, root, chain.config.Difficulty); err != nil {
+			return fmt.Errorf("genesis block generation failed: %w", err)
+		}
+
+	} else {
+		if err := checkBlock(genesisBlock, common.NullHash(), 0, chain.config.Params, genesisBlock.Bits); err != nil {
+			return fmt.Errorf("invalid genesis block: %w", err)
+		}
+
+		// Apply the Genesis Block transactions onto the empty chain state
+		if err := chainstate.ApplyTransactions(genesisBlock.BlockTxns, 0); err != nil {
+			return fmt.Errorf("genesis state transition failed: %w", err)
+		}
+
+		if err := checkStateRoot(genesisBlock, chainstate); err != nil {
+			return fmt.Errorf("invalid genesis block: %w", err)
+		}
 	}
 
-	// Serialize the Genesis Block
-	genesisData, err := genesisBlock.Serialize()
+	// Every Block on the chain has the target of the Genesis Block
+	chain.bits = genesisBlock.Bits
+
+	// Store the chain parameters before the chain head,
+	// whose presence marks the chain as initialized
+	params, err := common.GobEncode(chain.config.Params)
 	if err != nil {
-		return fmt.Errorf("block serialize failed: %w", err)
+		return fmt.Errorf("chain params serialize failed: %w", err)
 	}
 
-	// Add Genesis Block to DB
-	if err := chain.db.SetEntry(genesisBlock.BlockHash.Bytes(), genesisData); err != nil {
-		return fmt.Errorf("genesis block store to db failed: %w", err)
+	if err := chain.db.SetEntry(ChainParamsKey, params); err != nil {
+		return fmt.Errorf("chain params store failed: %w", err)
 	}
 
-	// Set the chain height and head into struct
-	chain.Head, chain.Height = genesisBlock.BlockHash, 1
-
-	// Sync the chain state into the DB
-	if err := chain.syncState(); err != nil {
-		return fmt.Errorf("chain state sync failed: %w", err)
+	// Commit the Genesis Block and its state to the db
+	if err := chain.commitBlock(genesisBlock, chainstate); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-// Stop closes the ChainManager's database client
-func (chain *ChainManager) Stop() {
-	chain.db.Close()
+// Params returns the consensus parameters of the chain
+func (chain *ChainManager) Params() core.ChainParams {
+	return chain.config.Params
+}
+
+// Bits returns the compact Proof of Work target of the chain, which is the target of its Genesis Block
+func (chain *ChainManager) Bits() uint32 {
+	return chain.bits
+}
+
+// Difficulty returns the Proof of Work difficulty of the chain, which is derived from
+// the target of its Genesis Block. It is exact for targets generated for a difficulty.
+func (chain *ChainManager) Difficulty() uint8 {
+	target, err := core.BitsToTarget(chain.bits)
+	if err != nil {
+		return 0
+	}
+
+	return uint8(257 - target.BitLen())
+}
+
+// Interrupt abandons any Block being mined and rejects any new Blocks.
+// The database remains open for reads until Stop is called.
+func (chain *ChainManager) Interrupt() {
+	chain.quitOnce.Do(func() { close(chain.quit) })
 }
 
-// syncState updates the chain head and height values into the DB at keys
-// specified by the ChainHeadKey and ChainHeightKey respectively.
-func (chain *ChainManager) syncState() error {
-	// Sync chain head into the DB
-	if err := chain.db.SetEntry(ChainHeadKey, chain.Head.Bytes()); err != nil {
-		return fmt.Errorf("error syncing chain head: %w", err)
+// Stop interrupts the ChainManager, waits for any Block being mined to
+// be abandoned and then flushes and closes the ChainManager's database client.
+// It is safe to call Stop multiple times.
+func (chain *ChainManager) Stop() error {
+	chain.Interrupt()
+
+	// Wait for any Block being added to be abandoned
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+
+	if chain.stopped {
+		return nil
 	}
 
+	chain.stopped = true
+	return chain.db.Close()
+}
+
+// syncState adds the given chain head and height to the batch at the keys specified by the ChainHeadKey
+// and ChainHeightKey respectively, so that they are written atomically with the Block at the chain head.
+func syncState(batch *db.Batch, head common.Hash, height int64) error {
 	// Serialize the chain height
-	height, err := common.GobEncode(chain.Height)
+	data, err := common.GobEncode(height)
 	if err != nil {
 		return fmt.Errorf("error serializing chain height: %w", err)
 	}
 
-	// Sync the encoded height into the DB
-	if err := chain.db.SetEntry(ChainHeightKey, height); err != nil {
-		return fmt.Errorf("error syncing chain height: %w", err)
-	}
+	batch.Set(ChainHeadKey, head.Bytes())
+	batch.Set(ChainHeightKey, data)
 
 	return nil
 }
@@ -0,0 +1,109 @@
+package chainmgr
+
+import (
+	"sync"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+// Event is a notification published by the ChainManager.
+// It is one of NewHeadEvent, PendingTxnsEvent or ReorgEvent.
+type Event interface{}
+
+// NewHeadEvent is published when a Block is committed as the new chain head
+type NewHeadEvent struct {
+	Block *core.Block
+}
+
+// PendingTxnsEvent is published when a set of Transactions has been accepted by the
+// ChainManager and is waiting to be mined. It is only published once the Transactions
+// have passed the checks of AddBlock, so rejected Transactions are never published.
+type PendingTxnsEvent struct {
+	Txns core.Transactions
+}
+
+// ReorgEvent is published when the chain head is replaced by
+// a Block that does not extend the previous chain head.
+// Blocks are only committed on top of the chain head until the
+// ChainManager can switch forks, so it is not published yet.
+type ReorgEvent struct {
+	OldHead common.Hash
+	NewHead common.Hash
+}
+
+// Subscription is a stream of Events from a ChainManager.
+// Events are delivered on a buffered channel, and a subscriber that allows
+// the buffer to fill up is dropped and has its channel closed, so that
+// slow subscribers never stall block processing.
+type Subscription struct {
+	events chan Event
+	feed   *feed
+	once   sync.Once
+}
+
+// Events returns the channel on which Events are delivered.
+// The channel is closed once the Subscription ends.
+func (sub *Subscription) Events() <-chan Event {
+	return sub.events
+}
+
+// Unsubscribe ends the Subscription and closes its channel.
+// It is safe to call Unsubscribe multiple times.
+func (sub *Subscription) Unsubscribe() {
+	sub.feed.remove(sub)
+}
+
+// feed is a set of Subscriptions that Events are published to
+type feed struct {
+	mutex sync.Mutex
+	subs  map[*Subscription]struct{}
+}
+
+// subscribe adds a new Subscription with the given buffer size to the feed
+func (f *feed) subscribe(buffer int) *Subscription {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.subs == nil {
+		f.subs = make(map[*Subscription]struct{})
+	}
+
+	sub := &Subscription{events: make(chan Event, buffer), feed: f}
+	f.subs[sub] = struct{}{}
+
+	return sub
+}
+
+// remove removes the Subscription from the feed and closes its channel
+func (f *feed) remove(sub *Subscription) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	delete(f.subs, sub)
+	sub.once.Do(func() { close(sub.events) })
+}
+
+// publish delivers an Event to all Subscriptions without blocking.
+// Subscriptions whose buffer is full are removed from the feed.
+func (f *feed) publish(event Event) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for sub := range f.subs {
+		select {
+		case sub.events <- event:
+		default:
+			// The subscriber is lagging, drop it
+			delete(f.subs, sub)
+			sub.once.Do(func() { close(sub.events) })
+		}
+	}
+}
+
+// Subscribe returns a new Subscription to the Events published by the
+// ChainManager. buffer is the number of Events that can be queued for
+// the subscriber before it is considered to be lagging and is dropped.
+func (chain *ChainManager) Subscribe(buffer int) *Subscription {
+	return chain.events.subscribe(buffer)
+}
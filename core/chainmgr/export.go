@@ -0,0 +1,139 @@
+package chainmgr
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/manishmeganathan/essensio/core"
+)
+
+// ExportMagic identifies a stream of exported Blocks
+const ExportMagic = "essensio-blocks"
+
+// ErrConflictingBlock is returned when an imported Block differs from the Block at its height on the chain
+var ErrConflictingBlock = errors.New("block conflicts with the chain")
+
+// exportHeader is the first value of a stream of exported Blocks
+type exportHeader struct {
+	Magic  string
+	Params core.ChainParams
+}
+
+// BlockWriter writes Blocks into a stream that can be read by a BlockReader.
+// Blocks must be written in order of increasing height from the Genesis Block to be imported.
+type BlockWriter struct {
+	encoder *gob.Encoder
+}
+
+// NewBlockWriter returns a new BlockWriter that writes the Blocks of a chain with the given parameters
+// to w. The header of the stream, which includes the parameters, is written immediately.
+func NewBlockWriter(w io.Writer, params core.ChainParams) (*BlockWriter, error) {
+	encoder := gob.NewEncoder(w)
+	if err := encoder.Encode(exportHeader{ExportMagic, params}); err != nil {
+		return nil, fmt.Errorf("export header write failed: %w", err)
+	}
+
+	return &BlockWriter{encoder}, nil
+}
+
+// Write writes the given Block into the stream
+func (writer *BlockWriter) Write(block *core.Block) error {
+	if err := writer.encoder.Encode(block); err != nil {
+		return fmt.Errorf("block %v write failed: %w", block.BlockHeight, err)
+	}
+
+	return nil
+}
+
+// BlockReader reads Blocks from a stream written by a BlockWriter
+type BlockReader struct {
+	decoder *gob.Decoder
+	params  core.ChainParams
+}
+
+// NewBlockReader returns a new BlockReader that reads from r.
+// Returns an error if the stream does not begin with a valid header.
+func NewBlockReader(r io.Reader) (*BlockReader, error) {
+	decoder := gob.NewDecoder(r)
+
+	var header exportHeader
+	if err := decoder.Decode(&header); err != nil || header.Magic != ExportMagic {
+		return nil, fmt.Errorf("not a stream of exported blocks")
+	}
+
+	return &BlockReader{decoder, header.Params}, nil
+}
+
+// Params returns the consensus parameters of the chain of the exported Blocks
+func (reader *BlockReader) Params() core.ChainParams {
+	return reader.params
+}
+
+// Read returns the next Block from the stream.
+// Returns io.EOF when there are no more Blocks.
+func (reader *BlockReader) Read() (*core.Block, error) {
+	block := new(core.Block)
+	if err := reader.decoder.Decode(block); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+
+		return nil, fmt.Errorf("block read failed: %w", err)
+	}
+
+	return block, nil
+}
+
+// ImportBlock appends an exported Block to the chain after verifying that it is valid, that it has the
+// target of the Genesis Block of the chain and that it extends the chain head. Its transactions are applied onto the chain state, which must then match its state root.
+// A Block that is already on the chain is skipped, in which case false is returned.
+// Returns ErrConflictingBlock if a different Block exists at the height of the Block.
+func (chain *ChainManager) ImportBlock(block *core.Block) (bool, error) {
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+
+	// Reject new Blocks once the ChainManager is interrupted
+	select {
+	case <-chain.quit:
+		return false, ErrChainStopped
+	default:
+	}
+
+	// Skip the Block if it is already on the chain
+	if block.BlockHeight >= 0 && block.BlockHeight < chain.height {
+		existing, err := chain.GetHeaderByHeight(block.BlockHeight)
+		if err != nil {
+			return false, err
+		}
+
+		if existing.BlockHash != block.BlockHash {
+			return false, fmt.Errorf("%w: height %v has block %v, got %v", ErrConflictingBlock, block.BlockHeight, existing.BlockHash.Hex(), block.BlockHash.Hex())
+		}
+
+		return false, nil
+	}
+
+	if err := checkBlock(block, chain.head, chain.height, chain.config.Params, chain.bits); err != nil {
+		return false, err
+	}
+
+	// Apply the transactions onto the chain state
+	chainstate := chain.newState(chain.stateRoot)
+	if err := chainstate.ApplyTransactions(block.BlockTxns, block.BlockHeight); err != nil {
+		return false, fmt.Errorf("state transition failed: %w", err)
+	}
+
+	// The state root of the Block must commit to the chain state after its transactions
+	if err := checkStateRoot(block, chainstate); err != nil {
+		return false, err
+	}
+
+	// Commit the block and its state to the db
+	if err := chain.commitBlock(block, chainstate); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
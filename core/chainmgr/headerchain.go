@@ -0,0 +1,348 @@
+package chainmgr
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/state"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+// LightChainKey is the key of the chain ID of a HeaderChain.
+// Its presence marks a database as a HeaderChain rather than a full chain.
+var LightChainKey = []byte("state-lightchain")
+
+var (
+	// ErrLightChain is returned when a ChainManager is opened on the database of a HeaderChain
+	ErrLightChain = errors.New("data directory contains a light chain")
+	// ErrFullChain is returned when a HeaderChain is opened on the database of a ChainManager
+	ErrFullChain = errors.New("data directory contains a full chain")
+	// ErrWrongGenesis is returned when the Genesis Block of a HeaderChain is not its trusted genesis hash
+	ErrWrongGenesis = errors.New("genesis block does not match the trusted genesis hash")
+)
+
+// HeaderChain represents a blockchain as a set of Block headers without their Transactions or the chain state.
+// It is followed by light nodes, which trust the hash of the Genesis Block, validate the Proof of Work, target
+// and linkage of every other header and verify the inclusion of Transactions with Merkle proofs served by full nodes.
+type HeaderChain struct {
+	// Represents the identifier of the chain
+	chainID uint64
+	// Represents the trusted hash of the Genesis Block
+	genesis common.Hash
+	// Represents the compact Proof of Work target of the chain, which is the target of its Genesis Block.
+	// It is set once the Genesis Block header is added.
+	bits uint32
+	// Represents the database of the Block headers
+	db *db.Database
+
+	// Represents the hash of the last Block header
+	head common.Hash
+	// Represents the Height of the chain. Last block Height+1
+	height int64
+
+	// Represents the lock that serializes the addition of headers
+	mutex sync.Mutex
+	// Represents the lock that guards the chain head and height,
+	// so that they can be read while a header is being added
+	tipMutex sync.RWMutex
+}
+
+// NewHeaderChain returns the HeaderChain with the given chain ID and trusted genesis hash in the given directory.
+// A new HeaderChain without any headers is created if the database does not exist. Returns ErrWrongGenesis if
+// the genesis hash is null or the HeaderChain in the database has a different Genesis Block.
+func NewHeaderChain(dir string, chainID uint64, genesis common.Hash) (*HeaderChain, error) {
+	if genesis == common.NullHash() {
+		return nil, fmt.Errorf("%w: genesis hash is null", ErrWrongGenesis)
+	}
+
+	database, err := db.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := &HeaderChain{chainID: chainID, genesis: genesis, db: database}
+	if err := chain.load(); err != nil {
+		_ = database.Close()
+		return nil, fmt.Errorf("failed to load light chain: %w", err)
+	}
+
+	return chain, nil
+}
+
+// load restarts a HeaderChain from the database or marks an empty database as a HeaderChain
+func (chain *HeaderChain) load() error {
+	data, err := chain.db.GetEntry(LightChainKey)
+	if errors.Is(err, db.ErrKeyNotFound) {
+		// A database with a chain head but no chain ID belongs to a full chain
+		if _, err := chain.db.GetEntry(ChainHeadKey); err == nil {
+			return ErrFullChain
+		} else if !errors.Is(err, db.ErrKeyNotFound) {
+			return err
+		}
+
+		id, err := common.GobEncode(chain.chainID)
+		if err != nil {
+			return fmt.Errorf("chain id serialize failed: %w", err)
+		}
+
+		return chain.db.SetEntry(LightChainKey, id)
+	} else if err != nil {
+		return fmt.Errorf("chain id retrieve failed: %w", err)
+	}
+
+	object, err := common.GobDecode(data, new(uint64))
+	if err != nil {
+		return fmt.Errorf("error deserializing chain id: %w", err)
+	}
+
+	if id := *object.(*uint64); id != chain.chainID {
+		return fmt.Errorf("%w: expected %v, got %v", core.ErrWrongChain, chain.chainID, id)
+	}
+
+	// A HeaderChain without any headers has no chain head
+	head, err := chain.db.GetEntry(ChainHeadKey)
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("chain head retrieve failed: %w", err)
+	}
+
+	header, err := readHeader(chain.db, common.BytesToHash(head))
+	if err != nil {
+		return fmt.Errorf("chain head header retrieve failed: %w", err)
+	}
+
+	chain.head = header.BlockHash
+	chain.height = header.BlockHeight + 1
+
+	// The stored Genesis Block must be the trusted one and fixes the target of the chain
+	genesis, err := chain.GetHeaderByHeight(0)
+	if err != nil {
+		return fmt.Errorf("genesis header retrieve failed: %w", err)
+	}
+
+	if genesis.BlockHash != chain.genesis {
+		return fmt.Errorf("%w: expected %v, got %v", ErrWrongGenesis, chain.genesis.Hex(), genesis.BlockHash.Hex())
+	}
+
+	chain.bits = genesis.Bits
+
+	return nil
+}
+
+// ChainID returns the identifier of the chain
+func (chain *HeaderChain) ChainID() uint64 {
+	return chain.chainID
+}
+
+// Genesis returns the trusted hash of the Genesis Block of the chain
+func (chain *HeaderChain) Genesis() common.Hash {
+	return chain.genesis
+}
+
+// Head returns the hash of the header at the chain head
+func (chain *HeaderChain) Head() common.Hash {
+	chain.tipMutex.RLock()
+	defer chain.tipMutex.RUnlock()
+
+	return chain.head
+}
+
+// Height returns the height of the chain, which is the number of headers in it
+func (chain *HeaderChain) Height() int64 {
+	chain.tipMutex.RLock()
+	defer chain.tipMutex.RUnlock()
+
+	return chain.height
+}
+
+// Tip returns the hash of the header at the chain head and the height of the chain.
+// They are read together, so that the head is always the header at height-1.
+func (chain *HeaderChain) Tip() (common.Hash, int64) {
+	chain.tipMutex.RLock()
+	defer chain.tipMutex.RUnlock()
+
+	return chain.head, chain.height
+}
+
+// AddHeader appends the given Header to the chain after verifying that it is valid, that it
+// extends the chain head and that it has the chain ID of the chain and the target of the Genesis
+// Block. The header of the Genesis Block must have the trusted genesis hash. The cumulative work of
+// the chain is indexed for the Header, and the header, its index entries and the new chain
+// head are written to the database atomically.
+func (chain *HeaderChain) AddHeader(header *core.Header) error {
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+
+	// The Genesis Block is trusted by its hash and fixes the target of the chain
+	bits := chain.bits
+	if chain.height == 0 {
+		if header.BlockHash != chain.genesis {
+			return fmt.Errorf("%w: expected %v, got %v", ErrWrongGenesis, chain.genesis.Hex(), header.BlockHash.Hex())
+		}
+
+		bits = header.Bits
+	}
+
+	if err := checkHeader(header, chain.head, chain.height, chain.chainID, bits); err != nil {
+		return err
+	}
+
+	// Add the work of the header to the cumulative work of its chain
+	work := header.Work()
+	if header.Priori != common.NullHash() {
+		priori, err := chain.GetChainWork(header.Priori)
+		if err != nil {
+			return fmt.Errorf("chain work retrieve failed: %w", err)
+		}
+
+		work.Add(work, priori)
+	}
+
+	data, err := header.Serialize()
+	if err != nil {
+		return fmt.Errorf("block header serialize failed: %w", err)
+	}
+
+	batch := db.NewBatch()
+	batch.Set(headerKey(header.BlockHash), data)
+	batch.Set(heightKey(header.BlockHeight), header.BlockHash.Bytes())
+	batch.Set(workKey(header.BlockHash), work.Bytes())
+
+	if err := syncState(batch, header.BlockHash, header.BlockHeight+1); err != nil {
+		return err
+	}
+
+	if err := chain.db.WriteBatch(batch); err != nil {
+		return fmt.Errorf("block header store to db failed: %w", err)
+	}
+
+	chain.tipMutex.Lock()
+	chain.head = header.BlockHash
+	chain.height = header.BlockHeight + 1
+	chain.tipMutex.Unlock()
+
+	chain.bits = bits
+
+	return nil
+}
+
+// GetHeaderByHash returns the Header of the Block with the given hash.
+// Returns ErrBlockNotFound if no such Block exists.
+func (chain *HeaderChain) GetHeaderByHash(hash common.Hash) (*core.Header, error) {
+	return readHeader(chain.db, hash)
+}
+
+// GetHeaderByHeight returns the Header of the Block at the given height on the chain.
+// Returns ErrBlockNotFound if the height is beyond the chain head.
+func (chain *HeaderChain) GetHeaderByHeight(height int64) (*core.Header, error) {
+	if height < 0 || height >= chain.Height() {
+		return nil, fmt.Errorf("%w: height %v", ErrBlockNotFound, height)
+	}
+
+	// Find the hash of the Block at the height from the index
+	hash, err := chain.db.GetEntry(heightKey(height))
+	if err != nil {
+		return nil, fmt.Errorf("height index lookup for %v failed: %w", height, err)
+	}
+
+	return chain.GetHeaderByHash(common.BytesToHash(hash))
+}
+
+// GetChainWork returns the cumulative work of the chain up to and including the Block with the given hash.
+// Returns ErrBlockNotFound if no such Block exists on the chain.
+func (chain *HeaderChain) GetChainWork(hash common.Hash) (*big.Int, error) {
+	data, err := chain.db.GetEntry(workKey(hash))
+	if err != nil {
+		if errors.Is(err, db.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w: hash %v", ErrBlockNotFound, hash.Hex())
+		}
+
+		return nil, fmt.Errorf("work index lookup for '%x' failed: %w", hash, err)
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
+
+// TotalWork returns the cumulative work of the chain up to the chain head.
+// The work of a chain without any headers is zero.
+func (chain *HeaderChain) TotalWork() (*big.Int, error) {
+	head, height := chain.Tip()
+	if height == 0 {
+		return new(big.Int), nil
+	}
+
+	return chain.GetChainWork(head)
+}
+
+// canonicalHeader returns the Header of the Block with the given hash.
+// Returns ErrBlockNotFound if the Block is not on the chain.
+func (chain *HeaderChain) canonicalHeader(block common.Hash) (*core.Header, error) {
+	header, err := chain.GetHeaderByHash(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// A stored header that is not at its height on the chain is not canonical
+	canonical, err := chain.GetHeaderByHeight(header.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	if canonical.BlockHash != header.BlockHash {
+		return nil, fmt.Errorf("%w: hash %v is not on the chain", ErrBlockNotFound, block.Hex())
+	}
+
+	return header, nil
+}
+
+// VerifyTransaction checks that the given Transaction is included in the Block with the given hash
+// with the given MerkleProof, and that the Block is on the chain. It returns the Header of the Block.
+// Returns ErrBlockNotFound if the Block is not on the chain and core.ErrInvalidMerkleProof if the
+// proof does not prove the inclusion of the Transaction in the Block.
+func (chain *HeaderChain) VerifyTransaction(txn *core.Transaction, block common.Hash, proof core.MerkleProof) (*core.Header, error) {
+	header, err := chain.canonicalHeader(block)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := txn.Hash()
+	if err != nil {
+		return nil, fmt.Errorf("transaction hash failed: %w", err)
+	}
+
+	if err := proof.Verify(hash, header.Summary); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// VerifyAccount checks that the given address has the given Account in the chain state after the Block
+// with the given hash with the given AccountProof, and that the Block is on the chain. It returns the
+// Header of the Block. Returns ErrBlockNotFound if the Block is not on the chain and
+// state.ErrInvalidAccountProof if the proof does not prove the Account against the state root of the Block.
+func (chain *HeaderChain) VerifyAccount(address common.Address, account *state.Account, block common.Hash, proof *state.AccountProof) (*core.Header, error) {
+	header, err := chain.canonicalHeader(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := proof.Verify(address, account, header.StateRoot); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// Stop flushes and closes the HeaderChain's database client
+func (chain *HeaderChain) Stop() error {
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+
+	return chain.db.Close()
+}
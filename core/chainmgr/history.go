@@ -0,0 +1,80 @@
+package chainmgr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/state"
+)
+
+// ErrStateUnavailable is returned when the chain state after a Block is not in the database, which is
+// the case for the Blocks below the height of a restored state snapshot or below the prune depth
+var ErrStateUnavailable = errors.New("chain state unavailable")
+
+// stateTrieAt returns the state Trie after the Block with the given hash along with the Header of the Block.
+// The Trie is kept for every Block in the archive mode and only for the Blocks from the height below the
+// lowest Block whose body is kept in the prune mode. Returns ErrStateUnavailable for the other Blocks.
+func (chain *ChainManager) stateTrieAt(block common.Hash) (*state.Trie, *core.Header, error) {
+	header, err := chain.GetHeaderByHash(block)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := chain.checkState(header); err != nil {
+		return nil, nil, err
+	}
+
+	return state.NewTrie(chain.db, header.StateRoot), header, nil
+}
+
+// stateError converts an error from reading the state Trie after
+// the Block at the given height into ErrStateUnavailable if its nodes are missing
+func stateError(err error, height int64) error {
+	if errors.Is(err, state.ErrMissingTrieNode) {
+		return fmt.Errorf("%w: after block %v", ErrStateUnavailable, height)
+	}
+
+	return err
+}
+
+// GetAccountAt returns the state of the Account for the given address after the Block with the given hash
+// along with the Header of the Block. Returns ErrBlockNotFound if the Block is not on the chain and
+// ErrStateUnavailable if the chain state after the Block is not in the database.
+func (chain *ChainManager) GetAccountAt(address common.Address, block common.Hash) (*state.Account, *core.Header, error) {
+	trie, header, err := chain.stateTrieAt(block)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	account, err := trie.GetAccount(address)
+	if err != nil {
+		return nil, nil, stateError(err, header.BlockHeight)
+	}
+
+	return account, header, nil
+}
+
+// ProveAccount returns the state of the Account for the given address after the Block with the given hash
+// along with its AccountProof against the state root of the Header of the Block, which is also returned.
+// Returns ErrBlockNotFound if the Block is not on the chain and ErrStateUnavailable if the chain
+// state after the Block is not in the database.
+func (chain *ChainManager) ProveAccount(address common.Address, block common.Hash) (*state.Account, *state.AccountProof, *core.Header, error) {
+	trie, header, err := chain.stateTrieAt(block)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	account, err := trie.GetAccount(address)
+	if err != nil {
+		return nil, nil, nil, stateError(err, header.BlockHeight)
+	}
+
+	proof, err := trie.Prove(address)
+	if err != nil {
+		return nil, nil, nil, stateError(err, header.BlockHeight)
+	}
+
+	return account, proof, header, nil
+}
@@ -0,0 +1,182 @@
+package chainmgr
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+var (
+	// HeightIndexPrefix is the key prefix for the height index.
+	// The index maps the height of every Block on the chain to its hash.
+	HeightIndexPrefix = []byte("index-height-")
+	// TxnIndexPrefix is the key prefix for the transaction index. The index maps the
+	// hash of every Transaction on the chain to its Block hash and position in the Block.
+	TxnIndexPrefix = []byte("index-txn-")
+	// WorkIndexPrefix is the key prefix for the work index. The index maps the hash of every
+	// Block on the chain to the cumulative work of the chain up to and including the Block.
+	WorkIndexPrefix = []byte("index-work-")
+)
+
+var (
+	// ErrBlockNotFound is returned when a requested Block does not exist on the chain
+	ErrBlockNotFound = errors.New("block not found")
+	// ErrTransactionNotFound is returned when a requested Transaction does not exist on the chain
+	ErrTransactionNotFound = errors.New("transaction not found")
+)
+
+// heightKey returns the height index key for a given block height.
+// The height is encoded in big endian so that keys are ordered by height.
+func heightKey(height int64) []byte {
+	key := make([]byte, len(HeightIndexPrefix)+8)
+	copy(key, HeightIndexPrefix)
+	binary.BigEndian.PutUint64(key[len(HeightIndexPrefix):], uint64(height))
+
+	return key
+}
+
+// txnKey returns the transaction index key for a given transaction hash
+func txnKey(hash common.Hash) []byte {
+	return append(append([]byte{}, TxnIndexPrefix...), hash.Bytes()...)
+}
+
+// workKey returns the work index key for a given block hash
+func workKey(hash common.Hash) []byte {
+	return append(append([]byte{}, WorkIndexPrefix...), hash.Bytes()...)
+}
+
+// indexBlock adds the given Block to the height index with the cumulative work of the
+// chain up to the Block, and adds each of its Transactions to the transaction index.
+func indexBlock(batch *db.Batch, block *core.Block, work *big.Int) error {
+	batch.Set(heightKey(block.BlockHeight), block.BlockHash.Bytes())
+	batch.Set(workKey(block.BlockHash), work.Bytes())
+
+	for idx, txn := range block.BlockTxns {
+		hash, err := txn.Hash()
+		if err != nil {
+			return fmt.Errorf("error indexing transaction %v of block %v: %w", idx, block.BlockHeight, err)
+		}
+
+		// The index entry is the block hash followed by the big endian position of the transaction
+		location := make([]byte, common.HashLength+8)
+		copy(location, block.BlockHash.Bytes())
+		binary.BigEndian.PutUint64(location[common.HashLength:], uint64(idx))
+
+		batch.Set(txnKey(hash), location)
+	}
+
+	return nil
+}
+
+// reindex rebuilds the height, transaction and work indexes from the Blocks of the chain.
+// It is only performed if the indexes do not contain an entry for the chain head,
+// which is the case for databases created before the indexes were introduced.
+func (chain *ChainManager) reindex() error {
+	// Check if the chain head has already been indexed
+	indexed := true
+	for _, key := range [][]byte{heightKey(chain.height - 1), workKey(chain.head)} {
+		if _, err := chain.db.GetEntry(key); errors.Is(err, db.ErrKeyNotFound) {
+			indexed = false
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if indexed {
+		return nil
+	}
+
+	// Collect the hashes of the chain from the head, as the
+	// cumulative work is computed from the Genesis Block
+	hashes := make([]common.Hash, 0, chain.height)
+	for iterator := chain.NewHeaderIterator(); !iterator.Done(); {
+		header, err := iterator.Next()
+		if err != nil {
+			return fmt.Errorf("iterator error: %w", err)
+		}
+
+		hashes = append(hashes, header.BlockHash)
+	}
+
+	batch := db.NewBatch()
+	work := new(big.Int)
+
+	for idx := len(hashes) - 1; idx >= 0; idx-- {
+		block, err := chain.GetBlockByHash(hashes[idx])
+		if err != nil {
+			return err
+		}
+
+		work = new(big.Int).Add(work, block.Work())
+		if err := indexBlock(batch, block, work); err != nil {
+			return err
+		}
+	}
+
+	return chain.db.WriteBatch(batch)
+}
+
+// GetChainWork returns the cumulative work of the chain up to and including the Block with the given hash.
+// Returns ErrBlockNotFound if no such Block exists on the chain.
+func (chain *ChainManager) GetChainWork(hash common.Hash) (*big.Int, error) {
+	data, err := chain.db.GetEntry(workKey(hash))
+	if err != nil {
+		if errors.Is(err, db.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w: hash %v", ErrBlockNotFound, hash.Hex())
+		}
+
+		return nil, fmt.Errorf("work index lookup for '%x' failed: %w", hash, err)
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
+
+// TotalWork returns the cumulative work of the chain up to the chain head
+func (chain *ChainManager) TotalWork() (*big.Int, error) {
+	return chain.GetChainWork(chain.Head())
+}
+
+// CompareWork compares the cumulative work of a competing chain with the work of this chain.
+// Returns -1, 0 or +1 if the competing chain has less, equal or more work respectively.
+// The chain with the most work is the canonical chain, regardless of its height.
+func (chain *ChainManager) CompareWork(work *big.Int) (int, error) {
+	total, err := chain.TotalWork()
+	if err != nil {
+		return 0, err
+	}
+
+	return work.Cmp(total), nil
+}
+
+// GetTransaction returns the Transaction with the given hash along with
+// the Block that contains it and its position within that Block.
+// Returns ErrTransactionNotFound if no such Transaction exists.
+func (chain *ChainManager) GetTransaction(hash common.Hash) (*core.Transaction, *core.Block, int, error) {
+	// Find the location of the Transaction from the index
+	location, err := chain.db.GetEntry(txnKey(hash))
+	if err != nil {
+		if errors.Is(err, db.ErrKeyNotFound) {
+			return nil, nil, 0, fmt.Errorf("%w: hash %v", ErrTransactionNotFound, hash.Hex())
+		}
+
+		return nil, nil, 0, fmt.Errorf("transaction index lookup for '%x' failed: %w", hash, err)
+	}
+
+	// Get the Block that contains the Transaction
+	block, err := chain.GetBlockByHash(common.BytesToHash(location[:common.HashLength]))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	idx := int(binary.BigEndian.Uint64(location[common.HashLength:]))
+	if idx >= block.TxnCount() {
+		return nil, nil, 0, fmt.Errorf("transaction index for '%x' is corrupt", hash)
+	}
+
+	return block.BlockTxns[idx], block, idx, nil
+}
@@ -0,0 +1,173 @@
+package chainmgr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/state"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+// pruneBatchSize is the maximum number of Block bodies that are deleted in a single batch
+// when a chain catches up with its prune depth, which keeps the database transaction small
+const pruneBatchSize = 1000
+
+// pruneStateInterval is the number of Blocks whose bodies are pruned between deletions of the
+// unreachable state trie nodes, which amortizes the marking of the state tries that are kept
+const pruneStateInterval = 128
+
+// ErrBlockPruned is returned when the body of a requested Block has been pruned
+var ErrBlockPruned = errors.New("block body has been pruned")
+
+// PrunedHeight returns the height of the lowest Block whose body is kept.
+// The bodies of all Blocks below it have been pruned. It is zero in the archive mode.
+func (chain *ChainManager) PrunedHeight() int64 {
+	chain.tipMutex.RLock()
+	defer chain.tipMutex.RUnlock()
+
+	return chain.pruned
+}
+
+// checkPruned returns ErrBlockPruned if the body of the Block with the given Header has been pruned
+func (chain *ChainManager) checkPruned(header *core.Header) error {
+	if pruned := chain.PrunedHeight(); header.BlockHeight < pruned {
+		return fmt.Errorf("%w: block %v at height %v (bodies are kept from height %v)", ErrBlockPruned, header.BlockHash.Hex(), header.BlockHeight, pruned)
+	}
+
+	return nil
+}
+
+// stateHeight returns the height of the lowest Block whose chain state is kept. The chain state is kept after
+// the Block below the lowest Block whose body is kept and after every later Block, so that the kept Blocks can
+// be applied again. It is zero in the archive mode, unless the chain was restored from a state snapshot.
+func (chain *ChainManager) stateHeight() int64 {
+	if pruned := chain.PrunedHeight(); pruned > 0 {
+		return pruned - 1
+	}
+
+	return 0
+}
+
+// checkState returns ErrStateUnavailable if the chain state after the Block with the given Header has been
+// pruned or the Block was created before block headers had a state root, whose chain state was never stored
+func (chain *ChainManager) checkState(header *core.Header) error {
+	if header.StateRoot == common.NullHash() {
+		return fmt.Errorf("%w: block %v has no state root", ErrStateUnavailable, header.BlockHeight)
+	}
+
+	if height := chain.stateHeight(); header.BlockHeight < height {
+		return fmt.Errorf("%w: after block %v (state is kept from height %v)", ErrStateUnavailable, header.BlockHeight, height)
+	}
+
+	return nil
+}
+
+// pruneState deletes the state trie nodes that are not reachable from the state root of any Block whose
+// chain state is kept (see stateHeight), which removes the chain state after the Blocks below it.
+// The caller must hold the chain mutex or be loading the chain.
+func (chain *ChainManager) pruneState() error {
+	roots := make([]common.Hash, 0, chain.height-chain.stateHeight()+1)
+	for height := chain.stateHeight(); height < chain.height; height++ {
+		header, err := chain.GetHeaderByHeight(height)
+		if err != nil {
+			return fmt.Errorf("state root retrieve failed: %w", err)
+		}
+
+		roots = append(roots, header.StateRoot)
+	}
+
+	// The chain state of the chain head is always kept
+	roots = append(roots, chain.stateRoot)
+
+	if _, err := state.PruneTrie(chain.db, roots); err != nil {
+		return fmt.Errorf("state prune failed: %w", err)
+	}
+
+	return nil
+}
+
+// pruneBodies adds the deletion of the bodies of the Blocks deeper than the prune depth of a chain with the
+// given height to the batch, for at most limit Blocks from the lowest Block whose body is kept. Headers and
+// the indexes are retained. Returns the height of the lowest Block whose body is kept once the batch is written.
+func (chain *ChainManager) pruneBodies(batch *db.Batch, height, limit int64) (int64, error) {
+	if chain.config.PruneDepth <= 0 {
+		return chain.pruned, nil
+	}
+
+	target := height - chain.config.PruneDepth
+	if target-chain.pruned > limit {
+		target = chain.pruned + limit
+	}
+
+	if target <= chain.pruned {
+		return chain.pruned, nil
+	}
+
+	for height := chain.pruned; height < target; height++ {
+		hash, err := chain.db.GetEntry(heightKey(height))
+		if err != nil {
+			return 0, fmt.Errorf("height index lookup for %v failed: %w", height, err)
+		}
+
+		batch.Delete(bodyKey(common.BytesToHash(hash)))
+	}
+
+	pruned, err := common.GobEncode(target)
+	if err != nil {
+		return 0, fmt.Errorf("error serializing pruned height: %w", err)
+	}
+
+	batch.Set(PrunedHeightKey, pruned)
+	return target, nil
+}
+
+// prune deletes the bodies of all the Blocks deeper than the prune depth of the chain in batches,
+// and then the chain state below the kept Blocks. It catches up a chain that has just switched
+// to the prune mode or whose prune depth was reduced.
+func (chain *ChainManager) prune() error {
+	for {
+		batch := db.NewBatch()
+		pruned, err := chain.pruneBodies(batch, chain.height, pruneBatchSize)
+		if err != nil {
+			return err
+		}
+
+		if batch.Len() == 0 {
+			break
+		}
+
+		if err := chain.db.WriteBatch(batch); err != nil {
+			return fmt.Errorf("block prune failed: %w", err)
+		}
+
+		chain.pruned = pruned
+	}
+
+	// Delete the chain state below the kept Blocks, including any state left by an interrupted prune
+	if chain.config.PruneDepth > 0 && chain.pruned > 0 {
+		return chain.pruneState()
+	}
+
+	return nil
+}
+
+// loadPruned loads the height of the lowest Block whose body is kept from the database.
+// Chains that were never pruned keep the bodies of all their Blocks.
+func (chain *ChainManager) loadPruned() error {
+	data, err := chain.db.GetEntry(PrunedHeightKey)
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("pruned height retrieve failed: %w", err)
+	}
+
+	object, err := common.GobDecode(data, new(int64))
+	if err != nil {
+		return fmt.Errorf("error deserializing pruned height: %w", err)
+	}
+
+	chain.pruned = *object.(*int64)
+	return nil
+}
@@ -0,0 +1,518 @@
+package chainmgr
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/state"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+const (
+	// SnapshotMagic identifies a state snapshot
+	SnapshotMagic = "essensio-snapshot"
+	// SnapshotFolder is the folder in the data directory that contains the state snapshots
+	SnapshotFolder = "snapshots"
+	// SnapshotExt is the file extension of state snapshots
+	SnapshotExt = ".snap"
+)
+
+// restoreBatchSize is the maximum number of entries written
+// in a single batch when a state snapshot is restored
+const restoreBatchSize = 1000
+
+var (
+	// ErrInvalidSnapshot is returned when a state snapshot is malformed or does not match its commitment
+	ErrInvalidSnapshot = errors.New("invalid snapshot")
+	// ErrChainExists is returned when a state snapshot is restored into a data directory that already contains a chain
+	ErrChainExists = errors.New("data directory already contains a chain")
+)
+
+// SnapshotInfo describes a state snapshot. It is the first value of a snapshot stream, which
+// is followed by the Header of every Block on the chain from the Genesis Block, then by every
+// Account of the chain state in increasing order of address and then by every unspent output
+// of the chain state in increasing order of Outpoint.
+type SnapshotInfo struct {
+	// Represents the magic string that identifies a state snapshot
+	Magic string
+	// Represents the consensus parameters of the chain
+	Params core.ChainParams
+	// Represents the Height of the chain at the snapshot
+	Height int64
+	// Represents the hash of the chain head at the snapshot
+	Head common.Hash
+	// Represents the commitment hash of the Accounts of the chain state (see state.Commitment)
+	Root common.Hash
+	// Represents the number of Accounts of the chain state
+	Accounts int64
+	// Represents the number of unspent outputs of the chain state,
+	// which is zero for chains with the account ledger
+	Coins int64
+}
+
+// snapshotAccount is an Account of the chain state in a state snapshot
+type snapshotAccount struct {
+	Address common.Address
+	Account state.Account
+}
+
+// SnapshotDir returns the directory of the state snapshots in the given data directory
+func SnapshotDir(dataDir string) string {
+	return filepath.Join(dataDir, SnapshotFolder)
+}
+
+// SnapshotPath returns the path of the state snapshot of the chain at the given height in the given data directory
+func SnapshotPath(dataDir string, height int64) string {
+	return filepath.Join(SnapshotDir(dataDir), fmt.Sprintf("snapshot-%012d%v", height, SnapshotExt))
+}
+
+// ListSnapshots returns the paths of the state snapshots in the given data directory in increasing order of height
+func ListSnapshots(dataDir string) ([]string, error) {
+	entries, err := os.ReadDir(SnapshotDir(dataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("snapshot directory read failed: %w", err)
+	}
+
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), SnapshotExt) {
+			paths = append(paths, filepath.Join(SnapshotDir(dataDir), entry.Name()))
+		}
+	}
+
+	// The zero padded heights of the file names sort in order of height
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// WriteSnapshot writes a state snapshot of the chain at the chain head to w.
+// No Blocks are added to the chain while the snapshot is written.
+func (chain *ChainManager) WriteSnapshot(w io.Writer) (*SnapshotInfo, error) {
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+
+	return chain.writeSnapshot(w)
+}
+
+// writeSnapshot writes a state snapshot of the chain at the chain head to w.
+// The caller must hold the chain mutex.
+func (chain *ChainManager) writeSnapshot(w io.Writer) (*SnapshotInfo, error) {
+	if chain.stopped {
+		return nil, ErrChainStopped
+	}
+
+	// The commitment of the chain state is part of the snapshot info, which is written first
+	commitment, err := state.ComputeCommitment(chain.db)
+	if err != nil {
+		return nil, fmt.Errorf("state commitment failed: %w", err)
+	}
+
+	var coins int64
+	if err := state.IterateCoins(chain.db, func(*core.Coin) error {
+		coins++
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	info := &SnapshotInfo{
+		Magic:    SnapshotMagic,
+		Params:   chain.config.Params,
+		Height:   chain.height,
+		Head:     chain.head,
+		Root:     commitment.Sum(),
+		Accounts: commitment.Count(),
+		Coins:    coins,
+	}
+
+	encoder := gob.NewEncoder(w)
+	if err := encoder.Encode(info); err != nil {
+		return nil, fmt.Errorf("snapshot info write failed: %w", err)
+	}
+
+	for height := int64(0); height < chain.height; height++ {
+		header, err := chain.GetHeaderByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := encoder.Encode(header); err != nil {
+			return nil, fmt.Errorf("header %v write failed: %w", height, err)
+		}
+	}
+
+	if err := state.Iterate(chain.db, func(address common.Address, account *state.Account) error {
+		if err := encoder.Encode(snapshotAccount{address, *account}); err != nil {
+			return fmt.Errorf("account '%v' write failed: %w", address, err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := state.IterateCoins(chain.db, func(coin *core.Coin) error {
+		if err := encoder.Encode(coin); err != nil {
+			return fmt.Errorf("output %v write failed: %w", coin.Outpoint, err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// CreateSnapshot writes a state snapshot of the chain at the chain head into the snapshot directory of its
+// data directory (see SnapshotPath). The snapshot replaces any previous snapshot at the same height.
+// Returns the path of the snapshot and its SnapshotInfo.
+func (chain *ChainManager) CreateSnapshot() (string, *SnapshotInfo, error) {
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+
+	return chain.createSnapshot()
+}
+
+// createSnapshot writes a state snapshot of the chain into the snapshot directory.
+// The caller must hold the chain mutex.
+func (chain *ChainManager) createSnapshot() (string, *SnapshotInfo, error) {
+	if err := os.MkdirAll(SnapshotDir(chain.config.DataDir), 0o700); err != nil {
+		return "", nil, fmt.Errorf("snapshot directory create failed: %w", err)
+	}
+
+	// The snapshot is written to a temporary file that is renamed once
+	// complete, so that an interrupted snapshot never appears in the directory
+	path := SnapshotPath(chain.config.DataDir, chain.height)
+	file, err := os.CreateTemp(SnapshotDir(chain.config.DataDir), "snapshot-*.tmp")
+	if err != nil {
+		return "", nil, fmt.Errorf("snapshot file create failed: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	info, err := chain.writeSnapshot(file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("snapshot file close failed: %w", closeErr)
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return "", nil, fmt.Errorf("snapshot file rename failed: %w", err)
+	}
+
+	return path, info, nil
+}
+
+// autoSnapshot creates a state snapshot if the chain height is a multiple of the snapshot interval,
+// and removes the oldest snapshots beyond the number of retained snapshots. A failed snapshot is
+// only logged, because the Block that triggered it has already been committed. The caller must hold the chain mutex.
+func (chain *ChainManager) autoSnapshot() {
+	interval := chain.config.SnapshotInterval
+	if interval <= 0 || chain.height%interval != 0 {
+		return
+	}
+
+	path, _, err := chain.createSnapshot()
+	if err != nil {
+		log.Println("State Snapshot Failed:", err)
+		return
+	}
+
+	log.Printf("State Snapshot Created at Height %v: %v\n", chain.height, path)
+
+	paths, err := ListSnapshots(chain.config.DataDir)
+	if err != nil {
+		log.Println("State Snapshot Cleanup Failed:", err)
+		return
+	}
+
+	for len(paths) > chain.config.SnapshotRetain && chain.config.SnapshotRetain > 0 {
+		if err := os.Remove(paths[0]); err != nil {
+			log.Println("State Snapshot Cleanup Failed:", err)
+			return
+		}
+
+		paths = paths[1:]
+	}
+}
+
+// SnapshotReader reads a state snapshot written by WriteSnapshot
+type SnapshotReader struct {
+	decoder *gob.Decoder
+	info    SnapshotInfo
+}
+
+// NewSnapshotReader returns a new SnapshotReader that reads from r.
+// Returns ErrInvalidSnapshot if the stream does not begin with a valid SnapshotInfo.
+func NewSnapshotReader(r io.Reader) (*SnapshotReader, error) {
+	decoder := gob.NewDecoder(r)
+
+	var info SnapshotInfo
+	if err := decoder.Decode(&info); err != nil || info.Magic != SnapshotMagic {
+		return nil, fmt.Errorf("%w: not a state snapshot", ErrInvalidSnapshot)
+	}
+
+	return &SnapshotReader{decoder, info}, nil
+}
+
+// Info returns the SnapshotInfo of the state snapshot
+func (reader *SnapshotReader) Info() SnapshotInfo {
+	return reader.info
+}
+
+// Verify reads the rest of the state snapshot and verifies it. Every Header must be valid, linked to its
+// predecessor and have the chain ID of the snapshot and the target of the genesis header, and the last Header must be the chain head of the snapshot.
+// The Accounts must be in order of address and match the commitment of the snapshot, and the unspent outputs
+// must be in order of Outpoint. Together they must match the state root of the chain head. Each Header with the
+// cumulative work of the chain up to it, each Account and each unspent output are passed to the given functions,
+// which may be nil. Returns an error wrapping ErrInvalidSnapshot if the snapshot is invalid.
+func (reader *SnapshotReader) Verify(onHeader func(*core.Header, *big.Int) error, onAccount func(common.Address, *state.Account) error, onCoin func(*core.Coin) error) error {
+	info := reader.info
+	if err := info.Params.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+
+	if info.Height <= 0 {
+		return fmt.Errorf("%w: chain height %v must include the genesis block", ErrInvalidSnapshot, info.Height)
+	}
+
+	priori := common.NullHash()
+	work := new(big.Int)
+	// Every header has the target of the genesis header
+	var bits uint32
+
+	var header *core.Header
+	for height := int64(0); height < info.Height; height++ {
+		header = new(core.Header)
+		if err := reader.decoder.Decode(header); err != nil {
+			return fmt.Errorf("%w: header %v read failed: %v", ErrInvalidSnapshot, height, err)
+		}
+
+		if height == 0 {
+			bits = header.Bits
+		}
+
+		if err := checkHeader(header, priori, height, info.Params.ChainID, bits); err != nil {
+			return fmt.Errorf("%w: header %v: %v", ErrInvalidSnapshot, height, err)
+		}
+
+		work = new(big.Int).Add(work, header.Work())
+		if onHeader != nil {
+			if err := onHeader(header, work); err != nil {
+				return err
+			}
+		}
+
+		priori = header.BlockHash
+	}
+
+	if priori != info.Head {
+		return fmt.Errorf("%w: chain head %v does not match the header at height %v", ErrInvalidSnapshot, info.Head.Hex(), info.Height-1)
+	}
+
+	commitment := state.NewCommitment()
+	trie := state.NewTrie(nil, common.NullHash())
+
+	for idx := int64(0); idx < info.Accounts; idx++ {
+		var account snapshotAccount
+		if err := reader.decoder.Decode(&account); err != nil {
+			return fmt.Errorf("%w: account %v read failed: %v", ErrInvalidSnapshot, idx, err)
+		}
+
+		if err := commitment.Add(account.Address, &account.Account); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+
+		if err := trie.Update(account.Address, &account.Account); err != nil {
+			return err
+		}
+
+		if onAccount != nil {
+			if err := onAccount(account.Address, &account.Account); err != nil {
+				return err
+			}
+		}
+	}
+
+	if root := commitment.Sum(); root != info.Root {
+		return fmt.Errorf("%w: expected state commitment %v, got %v", ErrInvalidSnapshot, info.Root.Hex(), root.Hex())
+	}
+
+	if info.Coins > 0 && !info.Params.IsUTXO() {
+		return fmt.Errorf("%w: unspent outputs on a chain with the account ledger", ErrInvalidSnapshot)
+	}
+
+	var previous []byte
+	for idx := int64(0); idx < info.Coins; idx++ {
+		coin := new(core.Coin)
+		if err := reader.decoder.Decode(coin); err != nil {
+			return fmt.Errorf("%w: output %v read failed: %v", ErrInvalidSnapshot, idx, err)
+		}
+
+		outpoint := coin.Outpoint.Bytes()
+		if previous != nil && bytes.Compare(outpoint, previous) <= 0 {
+			return fmt.Errorf("%w: output %v is not in order of outpoint", ErrInvalidSnapshot, coin.Outpoint)
+		}
+
+		previous = outpoint
+
+		if err := trie.UpdateCoin(coin.Outpoint, &coin.Output); err != nil {
+			return err
+		}
+
+		if onCoin != nil {
+			if err := onCoin(coin); err != nil {
+				return err
+			}
+		}
+	}
+
+	if root := trie.Root(); root != header.StateRoot {
+		return fmt.Errorf("%w: expected state root %v, got %v", ErrInvalidSnapshot, header.StateRoot.Hex(), root.Hex())
+	}
+
+	// The snapshot must end after its unspent outputs
+	var extra core.Coin
+	if err := reader.decoder.Decode(&extra); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: unexpected data after %v accounts and %v outputs", ErrInvalidSnapshot, info.Accounts, info.Coins)
+	}
+
+	return nil
+}
+
+// RestoreSnapshot initializes a chain in the given data directory from the state snapshot read from r.
+// The snapshot is verified while it is restored. The chain has the headers of the snapshot and its chain
+// state, but none of the bodies of its Blocks, which are treated as pruned. Subsequent Blocks can be
+// imported into the chain. Returns ErrChainExists if the data directory already contains a chain.
+// A failed restore leaves a partially restored database, which must be removed before retrying.
+func RestoreSnapshot(dataDir string, r io.Reader) (*SnapshotInfo, error) {
+	reader, err := NewSnapshotReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := db.Open(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	for _, key := range [][]byte{ChainHeadKey, LightChainKey} {
+		if _, err := database.GetEntry(key); err == nil {
+			return nil, ErrChainExists
+		} else if !errors.Is(err, db.ErrKeyNotFound) {
+			return nil, err
+		}
+	}
+
+	// Write the headers, accounts, unspent outputs and the nodes of their state trie in batches as they are verified
+	batch := db.NewBatch()
+	trie := state.NewTrie(database, common.NullHash())
+
+	flush := func() error {
+		if batch.Len() < restoreBatchSize {
+			return nil
+		}
+
+		if err := trie.Commit(batch); err != nil {
+			return err
+		}
+
+		if err := database.WriteBatch(batch); err != nil {
+			return fmt.Errorf("snapshot restore failed: %w", err)
+		}
+
+		batch = db.NewBatch()
+		return nil
+	}
+
+	onHeader := func(header *core.Header, work *big.Int) error {
+		data, err := header.Serialize()
+		if err != nil {
+			return fmt.Errorf("block header serialize failed: %w", err)
+		}
+
+		batch.Set(headerKey(header.BlockHash), data)
+		batch.Set(heightKey(header.BlockHeight), header.BlockHash.Bytes())
+		batch.Set(workKey(header.BlockHash), work.Bytes())
+
+		return flush()
+	}
+
+	onAccount := func(address common.Address, account *state.Account) error {
+		if err := state.Restore(batch, address, account); err != nil {
+			return err
+		}
+
+		if err := trie.Update(address, account); err != nil {
+			return err
+		}
+
+		return flush()
+	}
+
+	onCoin := func(coin *core.Coin) error {
+		if err := state.RestoreCoin(batch, coin); err != nil {
+			return err
+		}
+
+		if err := trie.UpdateCoin(coin.Outpoint, &coin.Output); err != nil {
+			return err
+		}
+
+		return flush()
+	}
+
+	if err := reader.Verify(onHeader, onAccount, onCoin); err != nil {
+		return nil, err
+	}
+
+	info := reader.Info()
+
+	params, err := common.GobEncode(info.Params)
+	if err != nil {
+		return nil, fmt.Errorf("chain params serialize failed: %w", err)
+	}
+
+	height, err := common.GobEncode(info.Height)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing chain height: %w", err)
+	}
+
+	// The bodies of all the Blocks of the snapshot are missing,
+	// so they are pruned up to the height of the chain
+	batch.Set(ChainParamsKey, params)
+	batch.Set(ChainHeightKey, height)
+	batch.Set(PrunedHeightKey, height)
+
+	if err := trie.Commit(batch); err != nil {
+		return nil, err
+	}
+
+	if err := database.WriteBatch(batch); err != nil {
+		return nil, fmt.Errorf("snapshot restore failed: %w", err)
+	}
+
+	// The chain head is written last, because its presence marks the chain as initialized
+	if err := database.SetEntry(ChainHeadKey, info.Head.Bytes()); err != nil {
+		return nil, fmt.Errorf("snapshot restore failed: %w", err)
+	}
+
+	return &info, nil
+}
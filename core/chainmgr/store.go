@@ -0,0 +1,185 @@
+package chainmgr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+var (
+	// HeaderPrefix is the key prefix for Block headers.
+	// It maps the hash of every Block to its core.Header.
+	HeaderPrefix = []byte("block-header-")
+	// BodyPrefix is the key prefix for Block bodies.
+	// It maps the hash of every Block to its Transactions.
+	BodyPrefix = []byte("block-body-")
+)
+
+// headerKey returns the header key for a given block hash
+func headerKey(hash common.Hash) []byte {
+	return append(append([]byte{}, HeaderPrefix...), hash.Bytes()...)
+}
+
+// bodyKey returns the body key for a given block hash
+func bodyKey(hash common.Hash) []byte {
+	return append(append([]byte{}, BodyPrefix...), hash.Bytes()...)
+}
+
+// storeBlock adds the header and the body of the given Block to the batch.
+// They are stored separately so that headers can be read without the Transactions.
+func storeBlock(batch *db.Batch, block *core.Block) error {
+	header, err := block.Header().Serialize()
+	if err != nil {
+		return fmt.Errorf("block header serialize failed: %w", err)
+	}
+
+	body, err := common.GobEncode(block.BlockTxns)
+	if err != nil {
+		return fmt.Errorf("block body serialize failed: %w", err)
+	}
+
+	batch.Set(headerKey(block.BlockHash), header)
+	batch.Set(bodyKey(block.BlockHash), body)
+
+	return nil
+}
+
+// readHeader returns the Header of the Block with the given hash from the database.
+// Returns ErrBlockNotFound if no such Block exists.
+func readHeader(database *db.Database, hash common.Hash) (*core.Header, error) {
+	data, err := database.GetEntry(headerKey(hash))
+	if err != nil {
+		if errors.Is(err, db.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w: hash %v", ErrBlockNotFound, hash.Hex())
+		}
+
+		return nil, fmt.Errorf("cannot find block header '%x': %w", hash, err)
+	}
+
+	header := new(core.Header)
+	if err := header.Deserialize(data); err != nil {
+		return nil, fmt.Errorf("block header deserialize failed: %w", err)
+	}
+
+	return header, nil
+}
+
+// readBody returns the Transactions of the Block with the given hash from the database.
+// Returns ErrBlockNotFound if no such Block exists.
+func readBody(database *db.Database, hash common.Hash) (core.Transactions, error) {
+	data, err := database.GetEntry(bodyKey(hash))
+	if err != nil {
+		if errors.Is(err, db.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w: hash %v", ErrBlockNotFound, hash.Hex())
+		}
+
+		return nil, fmt.Errorf("cannot find block body '%x': %w", hash, err)
+	}
+
+	object, err := common.GobDecode(data, new(core.Transactions))
+	if err != nil {
+		return nil, fmt.Errorf("block body deserialize failed: %w", err)
+	}
+
+	return *object.(*core.Transactions), nil
+}
+
+// migrateBlocks moves the Blocks of the chain from their legacy storage, which is the whole
+// Block under its hash, into separate headers and bodies. It is only performed if the chain
+// head has no header, which is the case for databases created before headers were stored separately.
+func (chain *ChainManager) migrateBlocks() error {
+	if _, err := chain.db.GetEntry(headerKey(chain.head)); err == nil {
+		return nil
+	} else if !errors.Is(err, db.ErrKeyNotFound) {
+		return err
+	}
+
+	batch := db.NewBatch()
+	for cursor := chain.head; cursor != common.NullHash(); {
+		data, err := chain.db.GetEntry(cursor.Bytes())
+		if err != nil {
+			return fmt.Errorf("cannot find block '%x': %w", cursor, err)
+		}
+
+		block := new(core.Block)
+		if err := block.Deserialize(data); err != nil {
+			return fmt.Errorf("block deserialize failed: %w", err)
+		}
+
+		if err := storeBlock(batch, block); err != nil {
+			return err
+		}
+
+		batch.Delete(cursor.Bytes())
+		cursor = block.Priori
+	}
+
+	return chain.db.WriteBatch(batch)
+}
+
+// GetHeaderByHash returns the Header of the Block with the given hash without reading its Transactions.
+// Returns ErrBlockNotFound if no such Block exists.
+func (chain *ChainManager) GetHeaderByHash(hash common.Hash) (*core.Header, error) {
+	return readHeader(chain.db, hash)
+}
+
+// GetHeaderByHeight returns the Header of the Block at the given height on the chain without
+// reading its Transactions. Returns ErrBlockNotFound if the height is beyond the chain head.
+func (chain *ChainManager) GetHeaderByHeight(height int64) (*core.Header, error) {
+	hash, err := chain.GetHashByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return chain.GetHeaderByHash(hash)
+}
+
+// GetBlockByHash returns the Block with the given hash.
+// Returns ErrBlockNotFound if no such Block exists and ErrBlockPruned if its body has been pruned.
+func (chain *ChainManager) GetBlockByHash(hash common.Hash) (*core.Block, error) {
+	header, err := chain.GetHeaderByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := chain.checkPruned(header); err != nil {
+		return nil, err
+	}
+
+	txns, err := readBody(chain.db, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.AssembleBlock(header, txns), nil
+}
+
+// GetBlockByHeight returns the Block at the given height on the chain. Returns ErrBlockNotFound
+// if the height is beyond the chain head and ErrBlockPruned if the body of the Block has been pruned.
+func (chain *ChainManager) GetBlockByHeight(height int64) (*core.Block, error) {
+	hash, err := chain.GetHashByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return chain.GetBlockByHash(hash)
+}
+
+// GetHashByHeight returns the hash of the Block at the given height on the chain from the height index.
+// Returns ErrBlockNotFound if the height is beyond the chain head.
+func (chain *ChainManager) GetHashByHeight(height int64) (common.Hash, error) {
+	if height < 0 || height >= chain.Height() {
+		return common.NullHash(), fmt.Errorf("%w: height %v", ErrBlockNotFound, height)
+	}
+
+	// Find the hash of the Block at the height from the index
+	hash, err := chain.db.GetEntry(heightKey(height))
+	if err != nil {
+		return common.NullHash(), fmt.Errorf("height index lookup for %v failed: %w", height, err)
+	}
+
+	return common.BytesToHash(hash), nil
+}
@@ -0,0 +1,168 @@
+package chainmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/state"
+)
+
+var (
+	// ErrUnlinkedBlock is returned when a Block does not extend the chain it is checked against
+	ErrUnlinkedBlock = errors.New("block does not extend the chain")
+	// ErrWrongTarget is returned when a Block does not have the Proof of Work target of the chain
+	// it is checked against, which is the target of its Genesis Block
+	ErrWrongTarget = errors.New("block does not have the proof of work target of the chain")
+)
+
+// checkHeader verifies the integrity of the given Header and checks that it extends
+// a chain with the given head and height and has the given chain ID and compact target
+func checkHeader(header *core.Header, priori common.Hash, height int64, chainID uint64, bits uint32) error {
+	if header.ChainID != chainID {
+		return fmt.Errorf("%w: expected %v, got %v", core.ErrWrongChain, chainID, header.ChainID)
+	}
+
+	if header.Bits != bits {
+		return fmt.Errorf("%w: expected 0x%08x, got 0x%08x", ErrWrongTarget, bits, header.Bits)
+	}
+
+	if header.BlockHeight != height {
+		return fmt.Errorf("%w: expected height %v, got %v", ErrUnlinkedBlock, height, header.BlockHeight)
+	}
+
+	if header.Priori != priori {
+		return fmt.Errorf("%w: expected priori %v, got %v", ErrUnlinkedBlock, priori.Hex(), header.Priori.Hex())
+	}
+
+	return header.Verify()
+}
+
+// checkBlock verifies the integrity of the given Block, checks that it extends a chain with
+// the given head and height, that it has the chain ID of the chain parameters and the given
+// compact target, that its
+// transactions follow the ledger of the chain parameters and that its coinbase pays the
+// subsidy of the chain parameters.
+func checkBlock(block *core.Block, priori common.Hash, height int64, params core.ChainParams, bits uint32) error {
+	if err := checkHeader(block.Header(), priori, height, params.ChainID, bits); err != nil {
+		return err
+	}
+
+	if err := block.Verify(); err != nil {
+		return err
+	}
+
+	for idx, txn := range block.BlockTxns {
+		if err := params.CheckLedger(txn); err != nil {
+			return fmt.Errorf("transaction %v: %w", idx, err)
+		}
+	}
+
+	return block.VerifyCoinbase(params.Subsidy(height))
+}
+
+// checkStateRoot checks that the state root of the given Block is the root of the given chain state,
+// onto which the transactions of the Block have been applied
+func checkStateRoot(block *core.Block, chainstate *state.State) error {
+	root, err := chainstate.Root()
+	if err != nil {
+		return fmt.Errorf("state root computation failed: %w", err)
+	}
+
+	if root != block.StateRoot {
+		return fmt.Errorf("%w: expected %v, got %v", core.ErrInvalidStateRoot, root.Hex(), block.StateRoot.Hex())
+	}
+
+	return nil
+}
+
+// Verify checks the integrity of the chain from the Genesis Block to the chain head.
+// Every Block must be valid, have the target of the Genesis Block, be linked to its predecessor and be
+// indexed by its height and the cumulative work of the chain, and every Transaction must be indexed by
+// its position in its Block.
+// Only the headers of Blocks whose bodies have been pruned are checked. The Accounts and
+// unspent outputs of the chain state must match the state root of the chain head.
+// Returns an error describing the first problem that is found.
+func (chain *ChainManager) Verify(ctx context.Context) error {
+	priori := common.NullHash()
+	work := new(big.Int)
+
+	// The chain is verified up to the chain head when the verification begins
+	head, chainHeight := chain.Tip()
+	pruned, stateRoot := chain.PrunedHeight(), chain.currentRoot()
+
+	for height := int64(0); height < chainHeight; height++ {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("verification cancelled: %w", err)
+		}
+
+		header, err := chain.GetHeaderByHeight(height)
+		if err != nil {
+			return fmt.Errorf("block %v: %w", height, err)
+		}
+
+		// Check that the cumulative work of the chain is indexed for the block
+		work.Add(work, header.Work())
+		if indexed, err := chain.GetChainWork(header.BlockHash); err != nil {
+			return fmt.Errorf("block %v: %w", height, err)
+		} else if indexed.Cmp(work) != 0 {
+			return fmt.Errorf("block %v: indexed chain work %v, expected %v", height, indexed, work)
+		}
+
+		// Only the header of a pruned block can be checked
+		if height < pruned {
+			if err := checkHeader(header, priori, height, chain.config.Params.ChainID, chain.bits); err != nil {
+				return fmt.Errorf("block %v: %w", height, err)
+			}
+
+			priori = header.BlockHash
+			continue
+		}
+
+		block, err := chain.GetBlockByHash(header.BlockHash)
+		if err != nil {
+			return fmt.Errorf("block %v: %w", height, err)
+		}
+
+		if err := checkBlock(block, priori, height, chain.config.Params, chain.bits); err != nil {
+			return fmt.Errorf("block %v: %w", height, err)
+		}
+
+		// Check that each transaction is indexed at its position in the block
+		for idx, txn := range block.BlockTxns {
+			hash, err := txn.Hash()
+			if err != nil {
+				return fmt.Errorf("block %v: transaction %v: %w", height, idx, err)
+			}
+
+			_, indexed, position, err := chain.GetTransaction(hash)
+			if err != nil {
+				return fmt.Errorf("block %v: transaction %v: %w", height, idx, err)
+			}
+
+			if indexed.BlockHash != block.BlockHash || position != idx {
+				return fmt.Errorf("block %v: transaction %v: indexed at position %v of block %v", height, idx, position, indexed.BlockHeight)
+			}
+		}
+
+		priori = block.BlockHash
+	}
+
+	if priori != head {
+		return fmt.Errorf("chain head %v does not match the block at height %v", head.Hex(), chainHeight-1)
+	}
+
+	root, err := state.ComputeRoot(chain.db)
+	if err != nil {
+		return fmt.Errorf("state root computation failed: %w", err)
+	}
+
+	if root != stateRoot {
+		return fmt.Errorf("%w: chain head has %v, chain state has %v", core.ErrInvalidStateRoot, stateRoot.Hex(), root.Hex())
+	}
+
+	return nil
+}
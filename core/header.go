@@ -1,6 +1,7 @@
 package core
 
 import (
+	"fmt"
 	"math/big"
 	"time"
 
@@ -11,26 +12,80 @@ import (
 // of the block that are relevant to its cryptographic integrity.
 // The Block Hash is the hash of the Block Header.
 type BlockHeader struct {
+	// Identifier of the chain of the block
+	ChainID uint64
 	// Hash of the previous block
 	Priori common.Hash
 	// Hash of the all the data in the block
 	Summary common.Hash
+	// Root of the state trie after the block
+	StateRoot common.Hash
 	// Timestamp at the time of block creation
 	Timestamp int64
 
-	// Proof of Work Target Hash
-	Target *big.Int
+	// Compact encoding of the Proof of Work Target Hash
+	Bits uint32
 	// Proof of Work Nonce
 	Nonce int64
 }
 
-// NewBlockHeader returns a new BlockHeader for a given priori and summary hash
-func NewBlockHeader(priori, summary common.Hash) BlockHeader {
+// Header is a struct that represents the BlockHeader of a Block along with its position
+// on the chain and the number of its Transactions, but without the Transactions.
+type Header struct {
+	BlockHeader
+
+	// Number of blocks preceding the block
+	BlockHeight int64
+	// Hash of the block header
+	BlockHash common.Hash
+	// Number of transactions in the block
+	TxnCount int
+}
+
+// Serialize implements the common.Serializable interface for Header.
+// Converts the Header into a stream of bytes encoded using common.GobEncode.
+func (header *Header) Serialize() ([]byte, error) {
+	return common.GobEncode(header)
+}
+
+// Deserialize implements the common.Serializable interface for Header.
+// Converts the given data into Header and sets it the method's receiver using common.GobDecode.
+func (header *Header) Deserialize(data []byte) error {
+	// Decode the data into a *Header
+	object, err := common.GobDecode(data, new(Header))
+	if err != nil {
+		return err
+	}
+
+	// Cast the object into a *Header and
+	// set it to the method receiver
+	*header = *object.(*Header)
+	return nil
+}
+
+// Verify checks the integrity of the Header independently of the chain it belongs to.
+// The block hash must be the hash of the BlockHeader and meet its Proof of Work target.
+func (header *Header) Verify() error {
+	if hash := header.BlockHeader.Hash(); hash != header.BlockHash {
+		return fmt.Errorf("%w: expected %v, got %v", ErrInvalidBlockHash, hash.Hex(), header.BlockHash.Hex())
+	}
+
+	if !header.BlockHeader.Validate() {
+		return ErrInvalidProofOfWork
+	}
+
+	return nil
+}
+
+// NewBlockHeader returns a new BlockHeader for a given chain ID, priori, summary and state root hash and compact target
+func NewBlockHeader(chainID uint64, priori, summary, stateRoot common.Hash, bits uint32) BlockHeader {
 	return BlockHeader{
+		chainID,
 		priori,
 		summary,
+		stateRoot,
 		time.Now().Unix(),
-		GenerateTarget(),
+		bits,
 		0,
 	}
 }
@@ -41,6 +96,17 @@ func (header *BlockHeader) Serialize() ([]byte, error) {
 	return common.GobEncode(header)
 }
 
+// Target returns the Proof of Work Target Hash of the BlockHeader.
+// Returns ErrInvalidBits if the compact encoding of the target is invalid.
+func (header *BlockHeader) Target() (*big.Int, error) {
+	return BitsToTarget(header.Bits)
+}
+
+// Work returns the expected number of hashes required to mine the BlockHeader
+func (header *BlockHeader) Work() *big.Int {
+	return Work(header.Bits)
+}
+
 // Deserialize implements the common.Serializable interface for BlockHeader.
 // Converts the given data into BlockHeader and sets it the method's receiver using common.GobDecode.
 func (header *BlockHeader) Deserialize(data []byte) error {
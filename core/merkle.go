@@ -0,0 +1,123 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+// ErrInvalidMerkleProof is returned when a MerkleProof does not prove the inclusion of a Transaction in a Block
+var ErrInvalidMerkleProof = errors.New("invalid merkle proof")
+
+// MerkleStep is a step of a MerkleProof. It is the sibling of a node on the
+// path from a Transaction to the summary of its Block.
+type MerkleStep struct {
+	// Hash of the sibling node
+	Hash common.Hash
+	// Whether the sibling is the left node of the pair
+	Left bool
+}
+
+// MerkleProof is a proof that a Transaction is included in the Merkle tree of
+// the Transactions of a Block, whose root is the summary of the Block header.
+type MerkleProof []MerkleStep
+
+// merkleNode returns the parent of a pair of nodes of a Merkle tree. The nodes are prefixed
+// with a marker so that an interior node can never be mistaken for a Transaction hash.
+func merkleNode(left, right common.Hash) common.Hash {
+	data := make([]byte, 0, 1+2*common.HashLength)
+	data = append(data, 0x01)
+	data = append(data, left.Bytes()...)
+	data = append(data, right.Bytes()...)
+
+	return common.Hash256(data)
+}
+
+// merkleLevel returns the parents of the given level of a Merkle tree. The last
+// node of a level with an odd number of nodes is carried up to the next level.
+func merkleLevel(level []common.Hash) []common.Hash {
+	parents := make([]common.Hash, 0, (len(level)+1)/2)
+	for idx := 0; idx < len(level); idx += 2 {
+		if idx+1 == len(level) {
+			parents = append(parents, level[idx])
+		} else {
+			parents = append(parents, merkleNode(level[idx], level[idx+1]))
+		}
+	}
+
+	return parents
+}
+
+// txnHashes returns the hashes of the given Transactions, which are the leaves of their Merkle tree
+func txnHashes(txns Transactions) ([]common.Hash, error) {
+	hashes := make([]common.Hash, 0, len(txns))
+	for _, txn := range txns {
+		hash, err := txn.Hash()
+		if err != nil {
+			return nil, err
+		}
+
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil
+}
+
+// MerkleRoot returns the root of the Merkle tree of the given Transaction hashes.
+// The root of an empty tree is the hash of no data.
+func MerkleRoot(hashes []common.Hash) common.Hash {
+	if len(hashes) == 0 {
+		return common.Hash256(nil)
+	}
+
+	for len(hashes) > 1 {
+		hashes = merkleLevel(hashes)
+	}
+
+	return hashes[0]
+}
+
+// NewMerkleProof returns the MerkleProof of the Transaction at the given position in the given Transactions
+func NewMerkleProof(txns Transactions, idx int) (MerkleProof, error) {
+	if idx < 0 || idx >= len(txns) {
+		return nil, fmt.Errorf("transaction %v is out of range of %v transactions", idx, len(txns))
+	}
+
+	level, err := txnHashes(txns)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := make(MerkleProof, 0)
+	for len(level) > 1 {
+		// A node that is carried up has no sibling on its level
+		if sibling := idx ^ 1; sibling < len(level) {
+			proof = append(proof, MerkleStep{level[sibling], sibling < idx})
+		}
+
+		level = merkleLevel(level)
+		idx /= 2
+	}
+
+	return proof, nil
+}
+
+// Verify checks that the MerkleProof proves the inclusion of the Transaction
+// with the given hash in the Merkle tree with the given root.
+// Returns ErrInvalidMerkleProof if it does not.
+func (proof MerkleProof) Verify(hash, root common.Hash) error {
+	for _, step := range proof {
+		if step.Left {
+			hash = merkleNode(step.Hash, hash)
+		} else {
+			hash = merkleNode(hash, step.Hash)
+		}
+	}
+
+	if hash != root {
+		return fmt.Errorf("%w: expected root %v, got %v", ErrInvalidMerkleProof, root.Hex(), hash.Hex())
+	}
+
+	return nil
+}
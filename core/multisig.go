@@ -0,0 +1,263 @@
+package core
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core/script"
+)
+
+var (
+	// ErrInvalidMultisig is returned when a multisig account or its lock script is not valid
+	ErrInvalidMultisig = errors.New("invalid multisig")
+	// ErrNotParticipant is returned when a key that is not one of the keys of a multisig account signs for it
+	ErrNotParticipant = errors.New("key is not a participant of the multisig")
+	// ErrIncompleteMultisig is returned when a PartialTransaction has fewer signatures than the threshold of its account
+	ErrIncompleteMultisig = errors.New("multisig transaction does not have enough signatures")
+)
+
+// Multisig is an m-of-n multisig account, whose Transactions must be signed by a threshold
+// of its public keys. Its address is the address of its lock script (see Script).
+type Multisig struct {
+	// Represents the number of signatures required to spend from the account
+	Threshold int
+	// Represents the Ed25519 public keys of the participants in the order of the lock script
+	PublicKeys [][]byte
+}
+
+// NewMultisig returns the Multisig of the given threshold and public keys. The public keys are sorted,
+// so the address of the account does not depend on their order. Returns ErrInvalidMultisig if a public
+// key is not valid or is repeated, or if the threshold is not between 1 and the number of public keys.
+func NewMultisig(threshold int, publicKeys [][]byte) (*Multisig, error) {
+	sorted := make([][]byte, len(publicKeys))
+	copy(sorted, publicKeys)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+
+	multisig := &Multisig{Threshold: threshold, PublicKeys: sorted}
+	if err := multisig.validate(); err != nil {
+		return nil, err
+	}
+
+	return multisig, nil
+}
+
+// ParseMultisig returns the Multisig of the given lock script, which must be the lock script of a Multisig
+// (see Script). Returns ErrInvalidMultisig if it is not.
+func ParseMultisig(lock []byte) (*Multisig, error) {
+	instructions, err := script.Parse(lock)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMultisig, err)
+	}
+
+	// The script pushes the threshold, the public keys and their number before CHECKMULTISIG
+	if len(instructions) < 4 || instructions[len(instructions)-1].Opcode != script.OP_CHECKMULTISIG {
+		return nil, fmt.Errorf("%w: not a multisig lock script", ErrInvalidMultisig)
+	}
+
+	multisig := &Multisig{Threshold: pushedNumber(instructions[0])}
+	for _, instruction := range instructions[1 : len(instructions)-2] {
+		multisig.PublicKeys = append(multisig.PublicKeys, instruction.Data)
+	}
+
+	if err := multisig.validate(); err != nil {
+		return nil, err
+	}
+
+	// The lock script must be exactly the one of the Multisig, which has the same address
+	if !bytes.Equal(multisig.Script(), lock) {
+		return nil, fmt.Errorf("%w: not a multisig lock script", ErrInvalidMultisig)
+	}
+
+	return multisig, nil
+}
+
+// pushedNumber returns the number pushed by an Instruction (see script.Builder.AddInt),
+// or 0 if it does not push a number of at most 8 bytes
+func pushedNumber(instruction script.Instruction) int {
+	if instruction.Opcode >= script.OP_1 && instruction.Opcode <= script.OP_16 {
+		return int(instruction.Opcode-script.OP_1) + 1
+	}
+
+	if !instruction.IsPush() || len(instruction.Data) > 8 {
+		return 0
+	}
+
+	var number uint64
+	for _, b := range instruction.Data {
+		number = number<<8 | uint64(b)
+	}
+
+	return int(number)
+}
+
+// validate returns ErrInvalidMultisig if the Multisig does not have between 1 and script.MaxMultisigKeys
+// distinct Ed25519 public keys and a threshold between 1 and their number
+func (multisig *Multisig) validate() error {
+	count := len(multisig.PublicKeys)
+	if count == 0 || count > script.MaxMultisigKeys {
+		return fmt.Errorf("%w: must have between 1 and %v public keys, got %v", ErrInvalidMultisig, script.MaxMultisigKeys, count)
+	}
+
+	if multisig.Threshold < 1 || multisig.Threshold > count {
+		return fmt.Errorf("%w: threshold must be between 1 and %v, got %v", ErrInvalidMultisig, count, multisig.Threshold)
+	}
+
+	seen := make(map[string]bool, count)
+	for idx, publicKey := range multisig.PublicKeys {
+		if len(publicKey) != ed25519.PublicKeySize {
+			return fmt.Errorf("%w: public key %v has invalid length %v", ErrInvalidMultisig, idx, len(publicKey))
+		}
+
+		if seen[string(publicKey)] {
+			return fmt.Errorf("%w: public key %v is repeated", ErrInvalidMultisig, common.HexEncode(publicKey))
+		}
+
+		seen[string(publicKey)] = true
+	}
+
+	return nil
+}
+
+// Script returns the lock script of the Multisig, which is 'm <key 1> ... <key n> n CHECKMULTISIG'
+func (multisig *Multisig) Script() []byte {
+	builder := script.NewBuilder().AddInt(uint64(multisig.Threshold))
+	for _, publicKey := range multisig.PublicKeys {
+		builder.AddData(publicKey)
+	}
+
+	return builder.AddInt(uint64(len(multisig.PublicKeys))).AddOp(script.OP_CHECKMULTISIG).Script()
+}
+
+// Address returns the Address of the Multisig, which is the address of its lock script
+func (multisig *Multisig) Address() common.Address {
+	return script.Address(multisig.Script())
+}
+
+// index returns the position of the given public key in the Multisig, or -1 if it is not a participant
+func (multisig *Multisig) index(publicKey []byte) int {
+	for idx, participant := range multisig.PublicKeys {
+		if bytes.Equal(participant, publicKey) {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+// PartialTransaction is a Transaction from a Multisig account along with the signatures
+// of its participants collected so far. It is complete once it has a threshold of signatures.
+type PartialTransaction struct {
+	// Represents the Transaction, whose lock script is the script of the Multisig
+	Transaction *Transaction
+	// Represents the Multisig account of the sender
+	Multisig *Multisig
+	// Represents the signature of each public key of the Multisig, which is nil until it has signed
+	Signatures [][]byte
+}
+
+// NewPartialTransaction returns a PartialTransaction without signatures for the given unsigned Transaction from the
+// given Multisig account, and sets the lock script of the Transaction. Returns ErrInvalidMultisig if the sender of
+// the Transaction is not the Multisig account or the Transaction is already signed.
+func NewPartialTransaction(txn *Transaction, multisig *Multisig) (*PartialTransaction, error) {
+	if address := multisig.Address(); txn.From != address {
+		return nil, fmt.Errorf("%w: sender '%v' is not the multisig address '%v'", ErrInvalidMultisig, txn.From, address)
+	}
+
+	if len(txn.PublicKey) != 0 || len(txn.Signature) != 0 || len(txn.Witness) != 0 {
+		return nil, fmt.Errorf("%w: transaction is already signed", ErrInvalidMultisig)
+	}
+
+	txn.Script = multisig.Script()
+
+	return &PartialTransaction{Transaction: txn, Multisig: multisig, Signatures: make([][]byte, len(multisig.PublicKeys))}, nil
+}
+
+// Sign signs the PartialTransaction with the given Ed25519 private key of one of the participants.
+// Returns ErrNotParticipant if the key is not a participant of the Multisig account.
+func (partial *PartialTransaction) Sign(key ed25519.PrivateKey) error {
+	signature, err := partial.Transaction.SignScript(key)
+	if err != nil {
+		return err
+	}
+
+	return partial.AddSignature(key.Public().(ed25519.PublicKey), signature)
+}
+
+// AddSignature adds the signature of the given public key to the PartialTransaction. Returns ErrNotParticipant if
+// the public key is not a participant of the Multisig account and ErrInvalidSignature if the signature is not valid
+// for the Transaction under the public key. A participant that has already signed is signed again.
+func (partial *PartialTransaction) AddSignature(publicKey, signature []byte) error {
+	idx := partial.Multisig.index(publicKey)
+	if idx < 0 {
+		return fmt.Errorf("%w: %v", ErrNotParticipant, common.HexEncode(publicKey))
+	}
+
+	if !ed25519.Verify(publicKey, partial.Transaction.SigningPayload(), signature) {
+		return fmt.Errorf("%w: signature of %v", ErrInvalidSignature, common.HexEncode(publicKey))
+	}
+
+	partial.Signatures[idx] = signature
+	return nil
+}
+
+// Combine adds the signatures of another PartialTransaction for the same Transaction to the PartialTransaction.
+// Returns ErrInvalidMultisig if the other PartialTransaction is for a different Transaction.
+func (partial *PartialTransaction) Combine(other *PartialTransaction) error {
+	if !bytes.Equal(partial.Transaction.SigningPayload(), other.Transaction.SigningPayload()) {
+		return fmt.Errorf("%w: signatures are for a different transaction", ErrInvalidMultisig)
+	}
+
+	for idx, signature := range other.Signatures {
+		if signature == nil {
+			continue
+		}
+
+		if err := partial.AddSignature(other.Multisig.PublicKeys[idx], signature); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Signed returns the number of participants that have signed the PartialTransaction
+func (partial *PartialTransaction) Signed() int {
+	signed := 0
+	for _, signature := range partial.Signatures {
+		if signature != nil {
+			signed++
+		}
+	}
+
+	return signed
+}
+
+// Complete returns whether the PartialTransaction has a threshold of signatures
+func (partial *PartialTransaction) Complete() bool {
+	return partial.Signed() >= partial.Multisig.Threshold
+}
+
+// Finalize returns the Transaction with a witness that pushes the signatures of the first threshold participants
+// that have signed, in the order of the lock script. Returns ErrIncompleteMultisig if it is not complete.
+func (partial *PartialTransaction) Finalize() (*Transaction, error) {
+	if !partial.Complete() {
+		return nil, fmt.Errorf("%w: has %v of %v", ErrIncompleteMultisig, partial.Signed(), partial.Multisig.Threshold)
+	}
+
+	builder := script.NewBuilder()
+	for pushed, idx := 0, 0; pushed < partial.Multisig.Threshold; idx++ {
+		if partial.Signatures[idx] != nil {
+			builder.AddData(partial.Signatures[idx])
+			pushed++
+		}
+	}
+
+	txn := *partial.Transaction
+	txn.Witness = builder.Script()
+
+	return &txn, nil
+}
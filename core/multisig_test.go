@@ -0,0 +1,236 @@
+package core
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core/script"
+)
+
+// multisigKeys returns n deterministic Ed25519 private keys and their public keys
+func multisigKeys(n int) ([]ed25519.PrivateKey, [][]byte) {
+	keys := make([]ed25519.PrivateKey, n)
+	publicKeys := make([][]byte, n)
+	for idx := range keys {
+		seed := make([]byte, ed25519.SeedSize)
+		seed[0] = byte(idx + 1)
+		keys[idx] = ed25519.NewKeyFromSeed(seed)
+		publicKeys[idx] = keys[idx].Public().(ed25519.PublicKey)
+	}
+
+	return keys, publicKeys
+}
+
+func TestNewMultisigOrder(t *testing.T) {
+	_, publicKeys := multisigKeys(3)
+
+	orders := [][]int{{0, 1, 2}, {2, 1, 0}, {1, 2, 0}, {0, 2, 1}}
+	expected, err := NewMultisig(2, publicKeys)
+	if err != nil {
+		t.Fatalf("NewMultisig: %v", err)
+	}
+
+	for _, order := range orders {
+		permuted := make([][]byte, len(order))
+		for idx, key := range order {
+			permuted[idx] = publicKeys[key]
+		}
+
+		multisig, err := NewMultisig(2, permuted)
+		if err != nil {
+			t.Fatalf("NewMultisig(%v): %v", order, err)
+		}
+
+		if multisig.Address() != expected.Address() || !bytes.Equal(multisig.Script(), expected.Script()) {
+			t.Errorf("NewMultisig(%v): expected address %v, got %v", order, expected.Address(), multisig.Address())
+		}
+
+		// The public keys of the caller are not reordered
+		if !bytes.Equal(permuted[0], publicKeys[order[0]]) {
+			t.Errorf("NewMultisig(%v): reordered the given public keys", order)
+		}
+	}
+
+	for idx := 1; idx < len(expected.PublicKeys); idx++ {
+		if bytes.Compare(expected.PublicKeys[idx-1], expected.PublicKeys[idx]) >= 0 {
+			t.Fatalf("NewMultisig: public keys are not sorted")
+		}
+	}
+
+	// The threshold is part of the address
+	other, err := NewMultisig(3, publicKeys)
+	if err != nil {
+		t.Fatalf("NewMultisig: %v", err)
+	}
+
+	if other.Address() == expected.Address() {
+		t.Errorf("NewMultisig: expected a different address for another threshold")
+	}
+}
+
+func TestNewMultisigInvalid(t *testing.T) {
+	_, publicKeys := multisigKeys(script.MaxMultisigKeys + 1)
+
+	tests := []struct {
+		name       string
+		threshold  int
+		publicKeys [][]byte
+	}{
+		{"no keys", 1, nil},
+		{"zero threshold", 0, publicKeys[:2]},
+		{"threshold above keys", 3, publicKeys[:2]},
+		{"repeated key", 2, [][]byte{publicKeys[0], publicKeys[0]}},
+		{"invalid key", 1, [][]byte{publicKeys[0][:31]}},
+		{"too many keys", 1, publicKeys},
+	}
+
+	for _, test := range tests {
+		if _, err := NewMultisig(test.threshold, test.publicKeys); !errors.Is(err, ErrInvalidMultisig) {
+			t.Errorf("NewMultisig (%v): expected ErrInvalidMultisig, got %v", test.name, err)
+		}
+	}
+}
+
+func TestParseMultisig(t *testing.T) {
+	_, publicKeys := multisigKeys(script.MaxMultisigKeys)
+
+	for _, test := range []struct{ threshold, keys int }{{1, 1}, {2, 3}, {3, 3}, {7, script.MaxMultisigKeys}} {
+		multisig, err := NewMultisig(test.threshold, publicKeys[:test.keys])
+		if err != nil {
+			t.Fatalf("NewMultisig(%v of %v): %v", test.threshold, test.keys, err)
+		}
+
+		parsed, err := ParseMultisig(multisig.Script())
+		if err != nil {
+			t.Fatalf("ParseMultisig(%v of %v): %v", test.threshold, test.keys, err)
+		}
+
+		if parsed.Threshold != multisig.Threshold || parsed.Address() != multisig.Address() {
+			t.Errorf("ParseMultisig(%v of %v): expected %v, got %v", test.threshold, test.keys, multisig.Address(), parsed.Address())
+		}
+	}
+
+	// A script with unsorted keys keeps its order, so the Multisig has the address of the script
+	sorted, err := NewMultisig(1, publicKeys[:2])
+	if err != nil {
+		t.Fatalf("NewMultisig: %v", err)
+	}
+
+	unsorted := script.NewBuilder().AddInt(1).AddData(sorted.PublicKeys[1]).AddData(sorted.PublicKeys[0]).AddInt(2).AddOp(script.OP_CHECKMULTISIG).Script()
+	parsed, err := ParseMultisig(unsorted)
+	if err != nil {
+		t.Fatalf("ParseMultisig(unsorted): %v", err)
+	}
+
+	if parsed.Address() != script.Address(unsorted) || parsed.Address() == sorted.Address() {
+		t.Errorf("ParseMultisig(unsorted): expected %v, got %v", script.Address(unsorted), parsed.Address())
+	}
+
+	// Scripts that are not exactly the lock script of a Multisig are rejected
+	tests := []struct {
+		name string
+		lock []byte
+	}{
+		{"empty script", nil},
+		{"repeated key", script.NewBuilder().AddInt(1).AddData(publicKeys[0]).AddData(publicKeys[0]).AddInt(2).AddOp(script.OP_CHECKMULTISIG).Script()},
+		{"wrong key count", script.NewBuilder().AddInt(1).AddData(publicKeys[0]).AddInt(2).AddOp(script.OP_CHECKMULTISIG).Script()},
+		{"not CHECKMULTISIG", script.NewBuilder().AddInt(1).AddData(publicKeys[0]).AddInt(1).AddOp(script.OP_CHECKMULTISIGVERIFY).Script()},
+		{"trailing opcode", append(script.NewBuilder().AddInt(1).AddData(publicKeys[0]).AddInt(1).AddOp(script.OP_CHECKMULTISIG).Script(), byte(script.OP_DROP))},
+		{"invalid script", []byte{0x05}},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseMultisig(test.lock); !errors.Is(err, ErrInvalidMultisig) {
+			t.Errorf("ParseMultisig (%v): expected ErrInvalidMultisig, got %v", test.name, err)
+		}
+	}
+}
+
+func TestPartialTransaction(t *testing.T) {
+	keys, publicKeys := multisigKeys(3)
+	multisig, err := NewMultisig(2, publicKeys)
+	if err != nil {
+		t.Fatalf("NewMultisig: %v", err)
+	}
+
+	receiver := common.PublicKeyToAddress(publicKeys[0])
+	newPartial := func() *PartialTransaction {
+		partial, err := NewPartialTransaction(NewTransaction(ChainID, multisig.Address(), receiver, 0, 100, 1), multisig)
+		if err != nil {
+			t.Fatalf("NewPartialTransaction: %v", err)
+		}
+
+		return partial
+	}
+
+	partial := newPartial()
+	if _, err := partial.Finalize(); !errors.Is(err, ErrIncompleteMultisig) {
+		t.Fatalf("Finalize: expected ErrIncompleteMultisig, got %v", err)
+	}
+
+	// Signatures are checked against their participant as they are added
+	outsiders, outsiderKeys := multisigKeys(4)
+	if err := partial.AddSignature(outsiderKeys[3], ed25519.Sign(outsiders[3], partial.Transaction.SigningPayload())); !errors.Is(err, ErrNotParticipant) {
+		t.Errorf("AddSignature: expected ErrNotParticipant, got %v", err)
+	}
+
+	if err := partial.AddSignature(publicKeys[0], ed25519.Sign(keys[1], partial.Transaction.SigningPayload())); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("AddSignature: expected ErrInvalidSignature, got %v", err)
+	}
+
+	// Two participants sign copies of the transaction in parallel, which are combined
+	if err := partial.Sign(keys[2]); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	other := newPartial()
+	if err := other.Sign(keys[0]); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	if partial.Complete() {
+		t.Fatalf("Complete: expected an incomplete transaction with 1 of 2 signatures")
+	}
+
+	if err := partial.Combine(other); err != nil {
+		t.Fatalf("Combine: %v", err)
+	}
+
+	if partial.Signed() != 2 || !partial.Complete() {
+		t.Fatalf("Combine: expected 2 signatures, got %v", partial.Signed())
+	}
+
+	txn, err := partial.Finalize()
+	if err != nil {
+		t.Fatalf("Finalize: %v", err)
+	}
+
+	if err := txn.VerifySender(0); err != nil {
+		t.Fatalf("VerifySender: %v", err)
+	}
+
+	// The witness only satisfies the lock script for the signed transaction
+	tampered := *txn
+	tampered.Value = 1000
+	if err := tampered.VerifySender(0); !errors.Is(err, script.ErrScriptFailed) {
+		t.Errorf("VerifySender: expected ErrScriptFailed for a tampered transaction, got %v", err)
+	}
+
+	// A partial transaction for another transaction cannot be combined
+	different, err := NewPartialTransaction(NewTransaction(ChainID, multisig.Address(), receiver, 1, 100, 1), multisig)
+	if err != nil {
+		t.Fatalf("NewPartialTransaction: %v", err)
+	}
+
+	if err := partial.Combine(different); !errors.Is(err, ErrInvalidMultisig) {
+		t.Errorf("Combine: expected ErrInvalidMultisig, got %v", err)
+	}
+
+	// Only the multisig account can send a partial transaction
+	if _, err := NewPartialTransaction(NewTransaction(ChainID, receiver, multisig.Address(), 0, 100, 1), multisig); !errors.Is(err, ErrInvalidMultisig) {
+		t.Errorf("NewPartialTransaction: expected ErrInvalidMultisig, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+// DefaultHalvingInterval is the default number of Blocks after which the block subsidy halves
+const DefaultHalvingInterval int64 = 210000
+
+const (
+	// LedgerAccount is the ledger model in which Transactions move tokens between account balances
+	LedgerAccount = "account"
+	// LedgerUTXO is the ledger model in which Transactions spend unspent outputs into new outputs
+	LedgerUTXO = "utxo"
+)
+
+// ErrWrongLedger is returned when a Transaction does not follow the ledger model of its chain
+var ErrWrongLedger = errors.New("transaction does not follow the ledger of the chain")
+
+// ChainParams are the consensus parameters of a chain.
+// They are fixed when the Genesis Block of the chain is created.
+//
+// The subsidy is the amount of new tokens minted by the coinbase of each Block.
+// It begins at the initial reward and halves every halving interval until it is zero,
+// which bounds the supply of the chain.
+//
+// The ledger is the model of the Transactions of the chain. Transactions either move tokens between accounts
+// or spend unspent outputs into new outputs, while coinbase transactions follow both models.
+type ChainParams struct {
+	// Represents the identifier of the chain, which every Block and Transaction commits to
+	ChainID uint64
+
+	// Represents the subsidy of the Blocks in the first halving interval
+	InitialReward common.Amount
+	// Represents the number of Blocks after which the subsidy halves
+	HalvingInterval int64
+	// Represents the ledger model of the chain, LedgerAccount or LedgerUTXO. An empty ledger is the
+	// account ledger, which chains initialized before the ledger was selectable have.
+	Ledger string
+}
+
+// DefaultChainParams returns the ChainParams of the Essensio network
+func DefaultChainParams() ChainParams {
+	return ChainParams{
+		ChainID:         ChainID,
+		InitialReward:   BlockReward,
+		HalvingInterval: DefaultHalvingInterval,
+		Ledger:          LedgerAccount,
+	}
+}
+
+// Validate returns an error if the ChainParams do not describe a valid chain
+func (params ChainParams) Validate() error {
+	if params.ChainID == 0 {
+		return fmt.Errorf("chain id must not be 0")
+	}
+
+	if params.InitialReward == 0 {
+		return fmt.Errorf("initial reward must be positive")
+	}
+
+	if params.HalvingInterval <= 0 {
+		return fmt.Errorf("halving interval %v must be positive", params.HalvingInterval)
+	}
+
+	// The maximum supply is less than twice the supply of the first halving interval
+	if _, err := params.InitialReward.Mul(2 * uint64(params.HalvingInterval)); err != nil {
+		return fmt.Errorf("initial reward %v and halving interval %v exceed the maximum supply", params.InitialReward, params.HalvingInterval)
+	}
+
+	if params.Ledger != "" && params.Ledger != LedgerAccount && params.Ledger != LedgerUTXO {
+		return fmt.Errorf("ledger '%v' must be '%v' or '%v'", params.Ledger, LedgerAccount, LedgerUTXO)
+	}
+
+	return nil
+}
+
+// IsUTXO returns whether the chain has the UTXO ledger
+func (params ChainParams) IsUTXO() bool {
+	return params.Ledger == LedgerUTXO
+}
+
+// CheckLedger returns ErrWrongLedger if the given Transaction does not follow the ledger of the chain.
+// Coinbase transactions follow the ledger of every chain.
+func (params ChainParams) CheckLedger(txn *Transaction) error {
+	if txn.IsCoinbase() || txn.IsUTXO() == params.IsUTXO() {
+		return nil
+	}
+
+	if params.IsUTXO() {
+		return fmt.Errorf("%w: chain has the utxo ledger, transaction has no inputs or outputs", ErrWrongLedger)
+	}
+
+	return fmt.Errorf("%w: chain has the account ledger, transaction has inputs or outputs", ErrWrongLedger)
+}
+
+// Subsidy returns the subsidy of the Block at the given height
+func (params ChainParams) Subsidy(height int64) common.Amount {
+	if height < 0 {
+		return 0
+	}
+
+	halvings := height / params.HalvingInterval
+	if halvings >= 64 {
+		return 0
+	}
+
+	return params.InitialReward >> uint(halvings)
+}
+
+// Supply returns the number of tokens minted by the subsidies of a chain with the given height.
+// This is the circulating supply, as fees only move tokens between accounts.
+// Returns common.ErrAmountOverflow if the supply exceeds the maximum Amount,
+// which is only possible for ChainParams that are not valid.
+func (params ChainParams) Supply(height int64) (common.Amount, error) {
+	var supply common.Amount
+	for halvings := uint(0); height > 0 && halvings < 64; halvings++ {
+		blocks := height
+		if blocks > params.HalvingInterval {
+			blocks = params.HalvingInterval
+		}
+
+		minted, err := (params.InitialReward >> halvings).Mul(uint64(blocks))
+		if err != nil {
+			return 0, err
+		}
+
+		if supply, err = supply.Add(minted); err != nil {
+			return 0, err
+		}
+
+		height -= blocks
+	}
+
+	return supply, nil
+}
+
+// MaxSupply returns the number of tokens that will have been minted once the subsidy reaches zero.
+// Returns common.ErrAmountOverflow if it exceeds the maximum Amount (see Supply).
+func (params ChainParams) MaxSupply() (common.Amount, error) {
+	return params.Supply(math.MaxInt64)
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+func TestSupply(t *testing.T) {
+	params := ChainParams{ChainID: 1, InitialReward: 100, HalvingInterval: 10}
+
+	tests := []struct {
+		height int64
+		supply common.Amount
+	}{
+		{0, 0},
+		{1, 100},
+		{10, 1000},
+		{15, 1000 + 5*50},
+		{20, 1000 + 500},
+		{1 << 40, 1000 + 500 + 250 + 120 + 60 + 30 + 10},
+	}
+
+	for _, test := range tests {
+		supply, err := params.Supply(test.height)
+		if err != nil {
+			t.Fatalf("Supply(%v): %v", test.height, err)
+		}
+
+		if supply != test.supply {
+			t.Errorf("Supply(%v): expected %v, got %v", test.height, test.supply, supply)
+		}
+	}
+
+	maxSupply, err := params.MaxSupply()
+	if err != nil {
+		t.Fatalf("MaxSupply: %v", err)
+	}
+
+	if maxSupply != 1970 {
+		t.Errorf("MaxSupply: expected 1970, got %v", maxSupply)
+	}
+}
+
+func TestSupplyOverflow(t *testing.T) {
+	// The parameters are not valid, as their supply exceeds the maximum Amount
+	params := ChainParams{ChainID: 1, InitialReward: 1 << 62, HalvingInterval: 4}
+	if params.Validate() == nil {
+		t.Fatalf("Validate: expected an error")
+	}
+
+	if _, err := params.Supply(3); err != nil {
+		t.Fatalf("Supply(3): %v", err)
+	}
+
+	if _, err := params.Supply(4); !errors.Is(err, common.ErrAmountOverflow) {
+		t.Fatalf("Supply: expected ErrAmountOverflow, got %v", err)
+	}
+
+	if _, err := params.MaxSupply(); !errors.Is(err, common.ErrAmountOverflow) {
+		t.Fatalf("MaxSupply: expected ErrAmountOverflow, got %v", err)
+	}
+
+	// The supply of the default parameters does not overflow
+	if _, err := DefaultChainParams().MaxSupply(); err != nil {
+		t.Fatalf("MaxSupply of the default params: %v", err)
+	}
+}
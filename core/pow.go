@@ -1,8 +1,8 @@
 package core
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"math"
 	"math/big"
 
@@ -10,47 +10,56 @@ import (
 )
 
 const (
-	// BlockDifficulty represents the number of bits that need to be 0 for the Proof Of Work Algorithm.
+	// ChainID is the chain ID of the Essensio network
+	ChainID uint64 = 1
+
+	// BlockDifficulty represents the default number of bits that need to be 0 for the Proof Of Work Algorithm.
 	// Currently static, but can eventually be adjusted based on the total hash rate of the network.
 	BlockDifficulty uint8 = 18
 
-	// BlockReward represents the standard reward for mining a Block
+	// BlockReward represents the initial subsidy for mining a Block on the Essensio network
 	// The default block reward is 5 Essences or 1 Quintessence
 	BlockReward = common.Quintessence
 )
 
-// GenerateTarget returns a big.Int with the target hash value for the current difficulty
-func GenerateTarget() *big.Int {
+// GenerateTarget returns a big.Int with the target hash value for the given difficulty
+func GenerateTarget(difficulty uint8) *big.Int {
 	// Generate a new big Integer and left shift to match difficulty
 	target := big.NewInt(1)
-	target.Lsh(target, 256-uint(BlockDifficulty))
+	target.Lsh(target, 256-uint(difficulty))
 
 	return target
 }
 
 // Mint is the Proof of Work routine that generates a nonce
 // that is valid for the Target difficulty of the header.
-func (header *BlockHeader) Mint() common.Hash {
+// Returns an error if the context is cancelled before the header is mined.
+func (header *BlockHeader) Mint(ctx context.Context) (common.Hash, error) {
 	var hash common.Hash
 
+	target, err := header.Target()
+	if err != nil {
+		return common.NullHash(), err
+	}
+
 	// Reset Nonce
 	header.Nonce = 0
 
 	for header.Nonce < math.MaxInt64 {
-		// Serialize the Header
-		data, err := header.Serialize()
-		if err != nil {
-			log.Fatalln("header serialization failed during PoW:", err)
+		// Abandon mining if the context is cancelled
+		if err := ctx.Err(); err != nil {
+			fmt.Println()
+			return common.NullHash(), fmt.Errorf("mining cancelled: %w", err)
 		}
 
-		// Hash the Header data
-		hash = common.Hash256(data)
+		// Hash the Header
+		hash = header.Hash()
 
 		// Print the hash mining process
 		fmt.Printf("\rMining Block [%v]: %v", header.Nonce, hash.Hex())
 
 		// Compare the hash with target
-		if hash.Big().Cmp(header.Target) == -1 {
+		if hash.Big().Cmp(target) == -1 {
 			break // Block Mined!
 		} else {
 			// Increment Nonce & Repeat
@@ -59,21 +68,18 @@ func (header *BlockHeader) Mint() common.Hash {
 	}
 
 	fmt.Println()
-	return hash
+	return hash, nil
 }
 
 // Validate is the Proof of Work validation routine.
 // Returns a boolean indicating if the hash of the block is valid for its target.
+// A header with an invalid compact target is never valid.
 func (header *BlockHeader) Validate() bool {
-	// Serialize the Header
-	data, err := header.Serialize()
+	target, err := header.Target()
 	if err != nil {
-		log.Fatalln("header serialization failed during PoW:", err)
+		return false
 	}
 
-	// Hash the Header data
-	hash := common.Hash256(data)
-
-	// Compare hash with target
-	return hash.Big().Cmp(header.Target) == -1
+	// Hash the Header and compare it with the target
+	return header.Hash().Big().Cmp(target) == -1
 }
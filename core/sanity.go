@@ -0,0 +1,148 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core/script"
+)
+
+var (
+	// ErrZeroValue is returned when a Transaction that is not a coinbase does not transfer any tokens
+	ErrZeroValue = errors.New("transaction value is zero")
+	// ErrSelfTransfer is returned when the sender of a Transaction is also its receiver
+	ErrSelfTransfer = errors.New("transaction sender is its receiver")
+	// ErrCostOverflow is returned when the value and fee of a Transaction overflow
+	ErrCostOverflow = errors.New("transaction value and fee overflow")
+	// ErrInvalidUTXO is returned when a Transaction on a chain with the UTXO ledger is malformed
+	ErrInvalidUTXO = errors.New("invalid utxo transaction")
+)
+
+// CheckSanity checks the Transaction independently of the chain state. A transfer must be between
+// two different valid addresses, transfer a non-zero value and have a cost that does not overflow.
+// A coinbase transaction must not have a fee or be signed. The receiver of a coinbase is the miner
+// address of a node, which is not required to be a valid address.
+// A sender with a lock script is checked by checkScriptSanity, and a Transaction on a chain with the
+// UTXO ledger is checked by checkUTXOSanity instead of its receiver and value.
+func (txn *Transaction) CheckSanity() error {
+	if txn.IsCoinbase() {
+		if txn.IsUTXO() {
+			return fmt.Errorf("%w: coinbase has inputs or outputs", ErrInvalidCoinbase)
+		}
+
+		if txn.Fee != 0 {
+			return fmt.Errorf("%w: coinbase has a fee", ErrInvalidCoinbase)
+		}
+
+		if len(txn.PublicKey) != 0 || len(txn.Signature) != 0 || len(txn.Script) != 0 || len(txn.Witness) != 0 {
+			return fmt.Errorf("%w: coinbase is signed", ErrInvalidCoinbase)
+		}
+
+		return nil
+	}
+
+	if err := txn.From.Validate(); err != nil {
+		return fmt.Errorf("sender: %w", err)
+	}
+
+	if err := txn.checkScriptSanity(); err != nil {
+		return err
+	}
+
+	if txn.IsUTXO() {
+		return txn.checkUTXOSanity()
+	}
+
+	if err := txn.To.Validate(); err != nil {
+		return fmt.Errorf("receiver: %w", err)
+	}
+
+	if txn.From == txn.To {
+		return fmt.Errorf("%w: %v", ErrSelfTransfer, txn.From)
+	}
+
+	if txn.Value == 0 {
+		return ErrZeroValue
+	}
+
+	if _, err := txn.Cost(); err != nil {
+		return fmt.Errorf("%w: %v", ErrCostOverflow, err)
+	}
+
+	return nil
+}
+
+// checkUTXOSanity checks a Transaction on a chain with the UTXO ledger independently of the chain state.
+// It must spend at least one output without spending any output twice, and create at least one output,
+// each with a valid address and a non-zero value. It must not have a receiver, value or nonce, and its
+// cost must not overflow.
+func (txn *Transaction) checkUTXOSanity() error {
+	if txn.To != common.NullAddress() || txn.Value != 0 || txn.Nonce != 0 {
+		return fmt.Errorf("%w: receiver, value and nonce must not be set", ErrInvalidUTXO)
+	}
+
+	if len(txn.Inputs) == 0 {
+		return fmt.Errorf("%w: no inputs", ErrInvalidUTXO)
+	}
+
+	if len(txn.Outputs) == 0 {
+		return fmt.Errorf("%w: no outputs", ErrInvalidUTXO)
+	}
+
+	spent := make(map[Outpoint]bool, len(txn.Inputs))
+	for _, input := range txn.Inputs {
+		if spent[input.Previous] {
+			return fmt.Errorf("%w: %v", ErrDuplicateInput, input.Previous)
+		}
+
+		spent[input.Previous] = true
+	}
+
+	for idx, output := range txn.Outputs {
+		if err := output.Address.Validate(); err != nil {
+			return fmt.Errorf("%w: output %v: %v", ErrInvalidUTXO, idx, err)
+		}
+
+		if output.Value == 0 {
+			return fmt.Errorf("output %v: %w", idx, ErrZeroValue)
+		}
+	}
+
+	if _, err := txn.Cost(); err != nil {
+		return fmt.Errorf("%w: %v", ErrCostOverflow, err)
+	}
+
+	return nil
+}
+
+// checkScriptSanity checks the lock script and witness of a Transaction independently of the chain state.
+// A sender with a lock script does not sign the Transaction, the lock script must be valid and belong to
+// the sender, and the witness must only push data. A Transaction without a lock script has no witness.
+func (txn *Transaction) checkScriptSanity() error {
+	if !txn.IsScripted() {
+		if len(txn.Witness) != 0 {
+			return fmt.Errorf("%w: witness without a lock script", script.ErrInvalidScript)
+		}
+
+		return nil
+	}
+
+	if len(txn.PublicKey) != 0 || len(txn.Signature) != 0 {
+		return fmt.Errorf("%w: sender with a lock script is signed", script.ErrInvalidScript)
+	}
+
+	if _, err := script.Parse(txn.Script); err != nil {
+		return fmt.Errorf("lock script: %w", err)
+	}
+
+	if address := script.Address(txn.Script); address != txn.From {
+		return fmt.Errorf("%w: lock script of '%v' does not belong to sender '%v'", script.ErrInvalidScript, address, txn.From)
+	}
+
+	if err := script.CheckPushOnly(txn.Witness); err != nil {
+		return fmt.Errorf("witness: %w", err)
+	}
+
+	return nil
+}
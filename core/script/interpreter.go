@@ -0,0 +1,385 @@
+package script
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+// Context is the data of the Transaction and Block that a script is executed for
+type Context struct {
+	// Represents the data signed by the signatures checked by the script
+	Payload []byte
+	// Represents the height of the Block that includes the Transaction
+	Height int64
+}
+
+// Execute executes the witness script followed by the lock script on the same stack, for the given Context.
+// The witness must only push data, which the lock script then checks. Returns ErrScriptFailed if either script
+// fails or the lock script does not leave true on the top of the stack, and ErrInvalidScript if either is not valid.
+func Execute(witness, lock []byte, ctx *Context) error {
+	if err := CheckPushOnly(witness); err != nil {
+		return fmt.Errorf("witness: %w", err)
+	}
+
+	machine := &machine{ctx: ctx}
+	if err := machine.run(witness); err != nil {
+		return fmt.Errorf("witness: %w", err)
+	}
+
+	if err := machine.run(lock); err != nil {
+		return err
+	}
+
+	if len(machine.stack) == 0 || !asBool(machine.stack[len(machine.stack)-1]) {
+		return fmt.Errorf("%w: false on top of the stack", ErrScriptFailed)
+	}
+
+	return nil
+}
+
+// machine is the stack machine that executes scripts
+type machine struct {
+	ctx   *Context
+	stack [][]byte
+}
+
+// run executes the Instructions of a script on the stack of the machine
+func (machine *machine) run(script []byte) error {
+	instructions, err := Parse(script)
+	if err != nil {
+		return err
+	}
+
+	// Track whether each enclosing conditional branch is being executed
+	var branches []bool
+
+	for _, instruction := range instructions {
+		executing := true
+		for _, branch := range branches {
+			executing = executing && branch
+		}
+
+		// Conditionals are tracked in branches that are not executed, other opcodes are skipped
+		switch instruction.Opcode {
+		case OP_IF, OP_NOTIF:
+			branch := false
+			if executing {
+				value, err := machine.pop()
+				if err != nil {
+					return err
+				}
+
+				branch = asBool(value) == (instruction.Opcode == OP_IF)
+			}
+
+			branches = append(branches, branch)
+			continue
+
+		case OP_ELSE:
+			branches[len(branches)-1] = !branches[len(branches)-1]
+			continue
+
+		case OP_ENDIF:
+			branches = branches[:len(branches)-1]
+			continue
+		}
+
+		if !executing {
+			continue
+		}
+
+		if err := machine.step(instruction); err != nil {
+			return fmt.Errorf("%v: %w", instruction.Opcode, err)
+		}
+
+		if len(machine.stack) > MaxStackSize {
+			return fmt.Errorf("%w: stack exceeds %v elements", ErrScriptFailed, MaxStackSize)
+		}
+	}
+
+	return nil
+}
+
+// step executes a single Instruction that is not a conditional
+func (machine *machine) step(instruction Instruction) error {
+	switch opcode := instruction.Opcode; {
+	case instruction.IsPush():
+		if opcode >= OP_1 && opcode <= OP_16 {
+			machine.push(encodeNumber(uint64(opcode-OP_1) + 1))
+		} else {
+			machine.push(instruction.Data)
+		}
+
+		return nil
+
+	case opcode == OP_RETURN:
+		return fmt.Errorf("%w: script returned", ErrScriptFailed)
+
+	case opcode == OP_VERIFY:
+		return machine.verify()
+
+	case opcode == OP_DROP:
+		_, err := machine.pop()
+		return err
+
+	case opcode == OP_DUP, opcode == OP_OVER:
+		depth := 0
+		if opcode == OP_OVER {
+			depth = 1
+		}
+
+		if len(machine.stack) <= depth {
+			return fmt.Errorf("%w: stack underflow", ErrScriptFailed)
+		}
+
+		machine.push(machine.stack[len(machine.stack)-1-depth])
+		return nil
+
+	case opcode == OP_SWAP:
+		if len(machine.stack) < 2 {
+			return fmt.Errorf("%w: stack underflow", ErrScriptFailed)
+		}
+
+		top := len(machine.stack) - 1
+		machine.stack[top], machine.stack[top-1] = machine.stack[top-1], machine.stack[top]
+		return nil
+
+	case opcode == OP_EQUAL, opcode == OP_EQUALVERIFY:
+		a, b, err := machine.pop2()
+		if err != nil {
+			return err
+		}
+
+		machine.push(fromBool(bytes.Equal(a, b)))
+		if opcode == OP_EQUALVERIFY {
+			return machine.verify()
+		}
+
+		return nil
+
+	case opcode == OP_SHA256, opcode == OP_HASH256:
+		data, err := machine.pop()
+		if err != nil {
+			return err
+		}
+
+		if opcode == OP_SHA256 {
+			hash := sha256.Sum256(data)
+			machine.push(hash[:])
+		} else {
+			machine.push(common.Hash256(data).Bytes())
+		}
+
+		return nil
+
+	case opcode == OP_CHECKSIG, opcode == OP_CHECKSIGVERIFY:
+		signature, publicKey, err := machine.pop2()
+		if err != nil {
+			return err
+		}
+
+		valid, err := machine.checkSignature(publicKey, signature)
+		if err != nil {
+			return err
+		}
+
+		machine.push(fromBool(valid))
+		if opcode == OP_CHECKSIGVERIFY {
+			return machine.verify()
+		}
+
+		return nil
+
+	case opcode == OP_CHECKMULTISIG, opcode == OP_CHECKMULTISIGVERIFY:
+		valid, err := machine.checkMultisig()
+		if err != nil {
+			return err
+		}
+
+		machine.push(fromBool(valid))
+		if opcode == OP_CHECKMULTISIGVERIFY {
+			return machine.verify()
+		}
+
+		return nil
+
+	case opcode == OP_CHECKHEIGHTVERIFY:
+		height, err := machine.popNumber()
+		if err != nil {
+			return err
+		}
+
+		if machine.ctx.Height < 0 || uint64(machine.ctx.Height) < height {
+			return fmt.Errorf("%w: locked until height %v, block height is %v", ErrScriptFailed, height, machine.ctx.Height)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: unknown opcode", ErrInvalidScript)
+}
+
+// checkSignature returns whether the signature is valid for the payload of the Context under the public key.
+// An empty signature is not valid, which allows a script to continue without it. Returns an error if the
+// public key is not an Ed25519 public key.
+func (machine *machine) checkSignature(publicKey, signature []byte) (bool, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return false, fmt.Errorf("%w: invalid public key length %v", ErrScriptFailed, len(publicKey))
+	}
+
+	if len(signature) == 0 {
+		return false, nil
+	}
+
+	return ed25519.Verify(publicKey, machine.ctx.Payload, signature), nil
+}
+
+// checkMultisig pops the number of public keys, the public keys, the number of required signatures
+// and the signatures, and returns whether the signatures are valid for different public keys in the
+// same order. Returns an error if the numbers are invalid or any public key is not an Ed25519 public key.
+func (machine *machine) checkMultisig() (bool, error) {
+	count, err := machine.popNumber()
+	if err != nil {
+		return false, err
+	}
+
+	if count == 0 || count > MaxMultisigKeys {
+		return false, fmt.Errorf("%w: invalid number of public keys %v", ErrScriptFailed, count)
+	}
+
+	publicKeys := make([][]byte, count)
+	for idx := range publicKeys {
+		// The first public key of the script is the deepest in the stack
+		if publicKeys[int(count)-1-idx], err = machine.pop(); err != nil {
+			return false, err
+		}
+	}
+
+	required, err := machine.popNumber()
+	if err != nil {
+		return false, err
+	}
+
+	if required == 0 || required > count {
+		return false, fmt.Errorf("%w: invalid number of signatures %v of %v", ErrScriptFailed, required, count)
+	}
+
+	signatures := make([][]byte, required)
+	for idx := range signatures {
+		if signatures[int(required)-1-idx], err = machine.pop(); err != nil {
+			return false, err
+		}
+	}
+
+	// Match each signature with the next public key it is valid for
+	key := 0
+	for _, signature := range signatures {
+		for ; key < len(publicKeys); key++ {
+			valid, err := machine.checkSignature(publicKeys[key], signature)
+			if err != nil {
+				return false, err
+			}
+
+			if valid {
+				break
+			}
+		}
+
+		if key == len(publicKeys) {
+			return false, nil
+		}
+
+		key++
+	}
+
+	return true, nil
+}
+
+// verify pops the top of the stack and returns ErrScriptFailed if it is false
+func (machine *machine) verify() error {
+	value, err := machine.pop()
+	if err != nil {
+		return err
+	}
+
+	if !asBool(value) {
+		return fmt.Errorf("%w: verify failed", ErrScriptFailed)
+	}
+
+	return nil
+}
+
+// push pushes the data onto the stack
+func (machine *machine) push(data []byte) {
+	machine.stack = append(machine.stack, data)
+}
+
+// pop pops the top of the stack. Returns ErrScriptFailed if the stack is empty.
+func (machine *machine) pop() ([]byte, error) {
+	if len(machine.stack) == 0 {
+		return nil, fmt.Errorf("%w: stack underflow", ErrScriptFailed)
+	}
+
+	top := machine.stack[len(machine.stack)-1]
+	machine.stack = machine.stack[:len(machine.stack)-1]
+
+	return top, nil
+}
+
+// pop2 pops the top two elements of the stack, returning the deeper one first
+func (machine *machine) pop2() ([]byte, []byte, error) {
+	b, err := machine.pop()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	a, err := machine.pop()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return a, b, nil
+}
+
+// popNumber pops the top of the stack as a big endian unsigned number of at most 8 bytes
+func (machine *machine) popNumber() (uint64, error) {
+	data, err := machine.pop()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(data) > 8 {
+		return 0, fmt.Errorf("%w: number of %v bytes exceeds 8", ErrScriptFailed, len(data))
+	}
+
+	var number uint64
+	for _, b := range data {
+		number = number<<8 | uint64(b)
+	}
+
+	return number, nil
+}
+
+// asBool returns whether the data is true, which it is unless all its bytes are zero
+func asBool(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fromBool returns the data of a bool, which is 1 for true and empty for false
+func fromBool(value bool) []byte {
+	if value {
+		return []byte{1}
+	}
+
+	return []byte{}
+}
@@ -0,0 +1,169 @@
+package script
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
+	"testing"
+)
+
+// testKeys returns n deterministic Ed25519 private keys
+func testKeys(n int) []ed25519.PrivateKey {
+	keys := make([]ed25519.PrivateKey, n)
+	for idx := range keys {
+		seed := make([]byte, ed25519.SeedSize)
+		seed[0] = byte(idx + 1)
+		keys[idx] = ed25519.NewKeyFromSeed(seed)
+	}
+
+	return keys
+}
+
+// multisigScript returns the lock script that requires m signatures of the given keys
+func multisigScript(m uint64, keys []ed25519.PrivateKey) []byte {
+	builder := NewBuilder().AddInt(m)
+	for _, key := range keys {
+		builder.AddData(key.Public().(ed25519.PublicKey))
+	}
+
+	return builder.AddInt(uint64(len(keys))).AddOp(OP_CHECKMULTISIG).Script()
+}
+
+// witness returns the witness script that pushes the given data in order
+func witness(data ...[]byte) []byte {
+	builder := NewBuilder()
+	for _, item := range data {
+		builder.AddData(item)
+	}
+
+	return builder.Script()
+}
+
+func TestExecuteMultisig(t *testing.T) {
+	ctx := &Context{Payload: []byte("payload"), Height: 1}
+	keys := testKeys(3)
+	lock := multisigScript(2, keys)
+
+	sig := make([][]byte, len(keys))
+	for idx, key := range keys {
+		sig[idx] = ed25519.Sign(key, ctx.Payload)
+	}
+
+	forged := ed25519.Sign(testKeys(4)[3], ctx.Payload)
+
+	tests := []struct {
+		name    string
+		witness []byte
+		err     error
+	}{
+		{"keys 1 and 2", witness(sig[0], sig[1]), nil},
+		{"keys 1 and 3", witness(sig[0], sig[2]), nil},
+		{"keys 2 and 3", witness(sig[1], sig[2]), nil},
+		{"keys out of order", witness(sig[1], sig[0]), ErrScriptFailed},
+		{"same key twice", witness(sig[0], sig[0]), ErrScriptFailed},
+		{"same last key twice", witness(sig[2], sig[2]), ErrScriptFailed},
+		{"signature of another key", witness(sig[0], forged), ErrScriptFailed},
+		{"empty signature", witness(sig[0], nil), ErrScriptFailed},
+		{"too few signatures", witness(sig[0]), ErrScriptFailed},
+		{"no witness", nil, ErrScriptFailed},
+	}
+
+	for _, test := range tests {
+		err := Execute(test.witness, lock, ctx)
+		if test.err == nil && err != nil {
+			t.Errorf("Execute (%v): %v", test.name, err)
+		} else if test.err != nil && !errors.Is(err, test.err) {
+			t.Errorf("Execute (%v): expected %v, got %v", test.name, test.err, err)
+		}
+	}
+
+	// A signature over another payload is not valid
+	if err := Execute(witness(sig[0], sig[1]), lock, &Context{Payload: []byte("other")}); !errors.Is(err, ErrScriptFailed) {
+		t.Errorf("Execute: expected ErrScriptFailed for another payload, got %v", err)
+	}
+}
+
+func TestExecuteMultisigCounts(t *testing.T) {
+	ctx := &Context{Payload: []byte("payload")}
+	keys := testKeys(2)
+	sig := ed25519.Sign(keys[0], ctx.Payload)
+
+	tests := []struct {
+		name string
+		lock []byte
+	}{
+		{"no keys", NewBuilder().AddInt(1).AddInt(0).AddOp(OP_CHECKMULTISIG).Script()},
+		{"no signatures", multisigScript(0, keys)},
+		{"more signatures than keys", multisigScript(3, keys)},
+		{"too many keys", multisigScript(1, testKeys(MaxMultisigKeys+1))},
+	}
+
+	for _, test := range tests {
+		if err := Execute(witness(sig, sig, sig), test.lock, ctx); !errors.Is(err, ErrScriptFailed) {
+			t.Errorf("Execute (%v): expected ErrScriptFailed, got %v", test.name, err)
+		}
+	}
+}
+
+func TestExecuteCheckHeight(t *testing.T) {
+	lock := NewBuilder().AddInt(1000).AddOp(OP_CHECKHEIGHTVERIFY).AddInt(1).Script()
+
+	tests := []struct {
+		height int64
+		valid  bool
+	}{
+		{0, false},
+		{999, false},
+		{1000, true},
+		{1001, true},
+		{-1, false},
+	}
+
+	for _, test := range tests {
+		err := Execute(nil, lock, &Context{Height: test.height})
+		if test.valid && err != nil {
+			t.Errorf("Execute(height %v): %v", test.height, err)
+		} else if !test.valid && !errors.Is(err, ErrScriptFailed) {
+			t.Errorf("Execute(height %v): expected ErrScriptFailed, got %v", test.height, err)
+		}
+	}
+
+	// The height must be a number of at most 8 bytes
+	lock = NewBuilder().AddData(make([]byte, 9)).AddOp(OP_CHECKHEIGHTVERIFY).AddInt(1).Script()
+	if err := Execute(nil, lock, &Context{Height: 1}); !errors.Is(err, ErrScriptFailed) {
+		t.Errorf("Execute: expected ErrScriptFailed for an oversized height, got %v", err)
+	}
+}
+
+func TestExecutePushOnlyWitness(t *testing.T) {
+	ctx := &Context{Payload: []byte("payload")}
+	preimage := []byte("secret")
+	hash := sha256.Sum256(preimage)
+	lock := NewBuilder().AddOp(OP_SHA256).AddData(hash[:]).AddOp(OP_EQUAL).Script()
+
+	if err := Execute(witness(preimage), lock, ctx); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	// A witness that is not push only is rejected before it is executed,
+	// even if it would leave the lock script with a valid stack
+	tests := []struct {
+		name    string
+		witness []byte
+	}{
+		{"DUP", NewBuilder().AddData(preimage).AddOp(OP_DUP).AddOp(OP_DROP).Script()},
+		{"conditional", NewBuilder().AddInt(1).AddOp(OP_IF).AddData(preimage).AddOp(OP_ENDIF).Script()},
+		{"RETURN", NewBuilder().AddData(preimage).AddOp(OP_RETURN).Script()},
+		{"truncated push", []byte{0x05, 0x01}},
+	}
+
+	for _, test := range tests {
+		if err := Execute(test.witness, lock, ctx); !errors.Is(err, ErrInvalidScript) {
+			t.Errorf("Execute (%v): expected ErrInvalidScript, got %v", test.name, err)
+		}
+	}
+
+	if err := Execute(witness([]byte("wrong")), lock, ctx); !errors.Is(err, ErrScriptFailed) {
+		t.Errorf("Execute: expected ErrScriptFailed for the wrong preimage, got %v", err)
+	}
+}
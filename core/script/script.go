@@ -0,0 +1,368 @@
+package script
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+// Limits of a script that bound the work done by the interpreter.
+// Scripts have no loops, so every opcode is executed at most once.
+const (
+	// MaxScriptSize is the maximum size of a script in bytes
+	MaxScriptSize = 1024
+	// MaxElementSize is the maximum size of a data element pushed onto the stack
+	MaxElementSize = 520
+	// MaxStackSize is the maximum number of elements on the stack
+	MaxStackSize = 100
+	// MaxOps is the maximum number of opcodes that are not pushes in a script
+	MaxOps = 200
+	// MaxMultisigKeys is the maximum number of public keys checked by a CHECKMULTISIG,
+	// whose signatures all fit in a witness of MaxScriptSize
+	MaxMultisigKeys = 15
+)
+
+var (
+	// ErrInvalidScript is returned when a script is malformed or exceeds the limits of the interpreter
+	ErrInvalidScript = errors.New("invalid script")
+	// ErrScriptFailed is returned when a script executes without leaving true on the stack
+	ErrScriptFailed = errors.New("script failed")
+)
+
+// Opcode is an instruction of a script
+type Opcode byte
+
+// Opcodes of the script language. Opcodes from 0x01 to 0x4b push the next that many bytes onto the stack.
+const (
+	OP_0         Opcode = 0x00
+	OP_PUSHDATA1 Opcode = 0x4c
+	OP_PUSHDATA2 Opcode = 0x4d
+	OP_1         Opcode = 0x51
+	OP_16        Opcode = 0x60
+
+	OP_IF     Opcode = 0x63
+	OP_NOTIF  Opcode = 0x64
+	OP_ELSE   Opcode = 0x67
+	OP_ENDIF  Opcode = 0x68
+	OP_VERIFY Opcode = 0x69
+	OP_RETURN Opcode = 0x6a
+
+	OP_DROP Opcode = 0x75
+	OP_DUP  Opcode = 0x76
+	OP_OVER Opcode = 0x78
+	OP_SWAP Opcode = 0x7c
+
+	OP_EQUAL       Opcode = 0x87
+	OP_EQUALVERIFY Opcode = 0x88
+
+	OP_SHA256              Opcode = 0xa8
+	OP_HASH256             Opcode = 0xaa
+	OP_CHECKSIG            Opcode = 0xac
+	OP_CHECKSIGVERIFY      Opcode = 0xad
+	OP_CHECKMULTISIG       Opcode = 0xae
+	OP_CHECKMULTISIGVERIFY Opcode = 0xaf
+
+	OP_CHECKHEIGHTVERIFY Opcode = 0xb1
+)
+
+// names is the assembly name of each opcode that is not a push
+var names = map[Opcode]string{
+	OP_IF:                  "IF",
+	OP_NOTIF:               "NOTIF",
+	OP_ELSE:                "ELSE",
+	OP_ENDIF:               "ENDIF",
+	OP_VERIFY:              "VERIFY",
+	OP_RETURN:              "RETURN",
+	OP_DROP:                "DROP",
+	OP_DUP:                 "DUP",
+	OP_OVER:                "OVER",
+	OP_SWAP:                "SWAP",
+	OP_EQUAL:               "EQUAL",
+	OP_EQUALVERIFY:         "EQUALVERIFY",
+	OP_SHA256:              "SHA256",
+	OP_HASH256:             "HASH256",
+	OP_CHECKSIG:            "CHECKSIG",
+	OP_CHECKSIGVERIFY:      "CHECKSIGVERIFY",
+	OP_CHECKMULTISIG:       "CHECKMULTISIG",
+	OP_CHECKMULTISIGVERIFY: "CHECKMULTISIGVERIFY",
+	OP_CHECKHEIGHTVERIFY:   "CHECKHEIGHTVERIFY",
+}
+
+// opcodes is the opcode of each assembly name
+var opcodes = func() map[string]Opcode {
+	opcodes := make(map[string]Opcode, len(names))
+	for opcode, name := range names {
+		opcodes[name] = opcode
+	}
+
+	return opcodes
+}()
+
+// String implements the Stringer interface for Opcode
+func (opcode Opcode) String() string {
+	if name, ok := names[opcode]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("0x%02x", byte(opcode))
+}
+
+// Instruction is a decoded opcode of a script along with the data it pushes
+type Instruction struct {
+	// Represents the opcode of the instruction
+	Opcode Opcode
+	// Represents the data pushed by a push opcode
+	Data []byte
+}
+
+// IsPush returns whether the Instruction pushes data or a small number onto the stack
+func (ins Instruction) IsPush() bool {
+	return ins.Opcode <= OP_PUSHDATA2 || (ins.Opcode >= OP_1 && ins.Opcode <= OP_16)
+}
+
+// Parse decodes a script into its Instructions. Returns ErrInvalidScript if the script is larger than
+// MaxScriptSize, has a truncated or oversized push, an unknown opcode or more than MaxOps opcodes that
+// are not pushes, or if its conditionals are not balanced.
+func Parse(script []byte) ([]Instruction, error) {
+	if len(script) > MaxScriptSize {
+		return nil, fmt.Errorf("%w: size %v exceeds %v bytes", ErrInvalidScript, len(script), MaxScriptSize)
+	}
+
+	var instructions []Instruction
+	ops, depth := 0, 0
+
+	for pos := 0; pos < len(script); {
+		opcode := Opcode(script[pos])
+		pos++
+
+		// Determine the size of the data pushed by the opcode
+		size := -1
+		switch {
+		case opcode > OP_0 && opcode < OP_PUSHDATA1:
+			size = int(opcode)
+
+		case opcode == OP_PUSHDATA1:
+			if pos+1 > len(script) {
+				return nil, fmt.Errorf("%w: truncated push length at %v", ErrInvalidScript, pos)
+			}
+
+			size = int(script[pos])
+			pos++
+
+		case opcode == OP_PUSHDATA2:
+			if pos+2 > len(script) {
+				return nil, fmt.Errorf("%w: truncated push length at %v", ErrInvalidScript, pos)
+			}
+
+			size = int(binary.BigEndian.Uint16(script[pos:]))
+			pos += 2
+		}
+
+		if size >= 0 {
+			if size > MaxElementSize {
+				return nil, fmt.Errorf("%w: push of %v bytes exceeds %v", ErrInvalidScript, size, MaxElementSize)
+			}
+
+			if pos+size > len(script) {
+				return nil, fmt.Errorf("%w: truncated push of %v bytes at %v", ErrInvalidScript, size, pos)
+			}
+
+			instructions = append(instructions, Instruction{Opcode: opcode, Data: script[pos : pos+size]})
+			pos += size
+			continue
+		}
+
+		instruction := Instruction{Opcode: opcode}
+		if instruction.IsPush() {
+			instructions = append(instructions, instruction)
+			continue
+		}
+
+		if _, ok := names[opcode]; !ok {
+			return nil, fmt.Errorf("%w: unknown opcode %v at %v", ErrInvalidScript, opcode, pos-1)
+		}
+
+		if ops++; ops > MaxOps {
+			return nil, fmt.Errorf("%w: more than %v opcodes", ErrInvalidScript, MaxOps)
+		}
+
+		switch opcode {
+		case OP_IF, OP_NOTIF:
+			depth++
+		case OP_ELSE:
+			if depth == 0 {
+				return nil, fmt.Errorf("%w: ELSE without IF", ErrInvalidScript)
+			}
+		case OP_ENDIF:
+			if depth == 0 {
+				return nil, fmt.Errorf("%w: ENDIF without IF", ErrInvalidScript)
+			}
+
+			depth--
+		}
+
+		instructions = append(instructions, instruction)
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("%w: unbalanced conditional", ErrInvalidScript)
+	}
+
+	return instructions, nil
+}
+
+// CheckPushOnly returns ErrInvalidScript if the script is not valid or contains opcodes other than pushes.
+// The witness of a Transaction must only push data, so that it cannot change how the lock script executes.
+func CheckPushOnly(script []byte) error {
+	instructions, err := Parse(script)
+	if err != nil {
+		return err
+	}
+
+	for _, instruction := range instructions {
+		if !instruction.IsPush() {
+			return fmt.Errorf("%w: opcode %v in push only script", ErrInvalidScript, instruction.Opcode)
+		}
+	}
+
+	return nil
+}
+
+// Address returns the Address of an account or output locked by the given script, which is the hex encoding
+// of the last common.AddressLength bytes of the SHA-256 hash of the script prefixed by "script". The prefix
+// separates script addresses from the addresses of public keys.
+func Address(script []byte) common.Address {
+	hash := common.Hash256(append([]byte("script"), script...))
+	return common.Address(common.HexEncode(hash.Bytes()[common.HashLength-common.AddressLength:]))
+}
+
+// Builder builds a script from opcodes and pushes
+type Builder struct {
+	script []byte
+}
+
+// NewBuilder returns a new Builder with an empty script
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// AddOp adds the given opcode to the script and returns the Builder
+func (builder *Builder) AddOp(opcode Opcode) *Builder {
+	builder.script = append(builder.script, byte(opcode))
+	return builder
+}
+
+// AddData adds a push of the given data to the script with the smallest push opcode and returns the Builder
+func (builder *Builder) AddData(data []byte) *Builder {
+	switch size := len(data); {
+	case size == 0:
+		builder.script = append(builder.script, byte(OP_0))
+	case size < int(OP_PUSHDATA1):
+		builder.script = append(builder.script, byte(size))
+	case size <= 0xff:
+		builder.script = append(builder.script, byte(OP_PUSHDATA1), byte(size))
+	default:
+		builder.script = append(builder.script, byte(OP_PUSHDATA2), byte(size>>8), byte(size))
+	}
+
+	builder.script = append(builder.script, data...)
+	return builder
+}
+
+// AddInt adds a push of the given number to the script and returns the Builder.
+// Numbers up to 16 are pushed with their own opcodes.
+func (builder *Builder) AddInt(number uint64) *Builder {
+	if number == 0 {
+		return builder.AddOp(OP_0)
+	}
+
+	if number <= 16 {
+		return builder.AddOp(OP_1 + Opcode(number-1))
+	}
+
+	return builder.AddData(encodeNumber(number))
+}
+
+// Script returns the script built by the Builder
+func (builder *Builder) Script() []byte {
+	return builder.script
+}
+
+// Assemble converts the text form of a script into a script. The text is a sequence of opcode names, decimal
+// numbers and 0x prefixed hex data separated by whitespace. Opcode names may be given with an "OP_" prefix.
+// Returns ErrInvalidScript if a token is not recognised or the assembled script is not valid.
+func Assemble(text string) ([]byte, error) {
+	builder := NewBuilder()
+
+	for _, token := range strings.Fields(text) {
+		if strings.HasPrefix(token, "0x") {
+			data, err := common.HexDecode(token)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid data '%v': %v", ErrInvalidScript, token, err)
+			}
+
+			builder.AddData(data)
+			continue
+		}
+
+		if number, err := strconv.ParseUint(token, 10, 64); err == nil {
+			builder.AddInt(number)
+			continue
+		}
+
+		opcode, ok := opcodes[strings.TrimPrefix(strings.ToUpper(token), "OP_")]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown token '%v'", ErrInvalidScript, token)
+		}
+
+		builder.AddOp(opcode)
+	}
+
+	if _, err := Parse(builder.Script()); err != nil {
+		return nil, err
+	}
+
+	return builder.Script(), nil
+}
+
+// Disassemble converts a script into its text form (see Assemble).
+// Returns ErrInvalidScript if the script is not valid.
+func Disassemble(script []byte) (string, error) {
+	instructions, err := Parse(script)
+	if err != nil {
+		return "", err
+	}
+
+	tokens := make([]string, 0, len(instructions))
+	for _, instruction := range instructions {
+		switch {
+		case instruction.Opcode == OP_0:
+			tokens = append(tokens, "0")
+		case instruction.Opcode >= OP_1 && instruction.Opcode <= OP_16:
+			tokens = append(tokens, strconv.Itoa(int(instruction.Opcode-OP_1)+1))
+		case instruction.IsPush():
+			tokens = append(tokens, common.HexEncode(instruction.Data))
+		default:
+			tokens = append(tokens, instruction.Opcode.String())
+		}
+	}
+
+	return strings.Join(tokens, " "), nil
+}
+
+// encodeNumber returns the minimal big endian encoding of a number
+func encodeNumber(number uint64) []byte {
+	var data [8]byte
+	binary.BigEndian.PutUint64(data[:], number)
+
+	idx := 0
+	for idx < len(data) && data[idx] == 0 {
+		idx++
+	}
+
+	return data[idx:]
+}
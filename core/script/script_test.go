@@ -0,0 +1,108 @@
+package script
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestParseLimits(t *testing.T) {
+	// ops returns a script of n DUP opcodes
+	ops := func(n int) []byte {
+		return bytes.Repeat([]byte{byte(OP_DUP)}, n)
+	}
+
+	tests := []struct {
+		name   string
+		script []byte
+		valid  bool
+	}{
+		{"empty script", nil, true},
+		{"maximum size", append(NewBuilder().AddData(make([]byte, MaxElementSize)).Script(), make([]byte, MaxScriptSize-MaxElementSize-3)...), true},
+		{"oversized script", make([]byte, MaxScriptSize+1), false},
+		{"maximum ops", ops(MaxOps), true},
+		{"too many ops", ops(MaxOps + 1), false},
+		{"pushes do not count as ops", append(ops(MaxOps), byte(OP_1), byte(OP_0), 0x01, 0xff), true},
+		{"maximum element", NewBuilder().AddData(make([]byte, MaxElementSize)).Script(), true},
+		{"oversized element", NewBuilder().AddData(make([]byte, MaxElementSize+1)).Script(), false},
+		{"truncated push", []byte{0x05, 0x01, 0x02}, false},
+		{"truncated push length", []byte{byte(OP_PUSHDATA2), 0x01}, false},
+		{"unknown opcode", []byte{0xff}, false},
+		{"balanced conditionals", []byte{byte(OP_1), byte(OP_IF), byte(OP_1), byte(OP_NOTIF), byte(OP_ELSE), byte(OP_ENDIF), byte(OP_ELSE), byte(OP_ENDIF)}, true},
+		{"IF without ENDIF", []byte{byte(OP_1), byte(OP_IF), byte(OP_1)}, false},
+		{"ELSE without IF", []byte{byte(OP_1), byte(OP_ELSE), byte(OP_ENDIF)}, false},
+		{"ENDIF without IF", []byte{byte(OP_1), byte(OP_ENDIF)}, false},
+		{"ENDIF before IF", []byte{byte(OP_ENDIF), byte(OP_1), byte(OP_IF)}, false},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.script)
+		if test.valid && err != nil {
+			t.Errorf("Parse (%v): %v", test.name, err)
+		} else if !test.valid && !errors.Is(err, ErrInvalidScript) {
+			t.Errorf("Parse (%v): expected ErrInvalidScript, got %v", test.name, err)
+		}
+	}
+}
+
+func TestCheckPushOnly(t *testing.T) {
+	tests := []struct {
+		name   string
+		script []byte
+		valid  bool
+	}{
+		{"empty script", nil, true},
+		{"data and numbers", NewBuilder().AddData([]byte{1, 2, 3}).AddInt(0).AddInt(16).AddInt(1000).Script(), true},
+		{"large push", NewBuilder().AddData(make([]byte, 300)).Script(), true},
+		{"opcode", NewBuilder().AddInt(1).AddOp(OP_DUP).Script(), false},
+		{"conditional", NewBuilder().AddInt(1).AddOp(OP_IF).AddOp(OP_ENDIF).Script(), false},
+		{"invalid script", []byte{0x05}, false},
+	}
+
+	for _, test := range tests {
+		err := CheckPushOnly(test.script)
+		if test.valid && err != nil {
+			t.Errorf("CheckPushOnly (%v): %v", test.name, err)
+		} else if !test.valid && !errors.Is(err, ErrInvalidScript) {
+			t.Errorf("CheckPushOnly (%v): expected ErrInvalidScript, got %v", test.name, err)
+		}
+	}
+}
+
+func TestAssemble(t *testing.T) {
+	tests := []struct {
+		text   string
+		script []byte
+	}{
+		{"0 1 16 17", []byte{byte(OP_0), byte(OP_1), byte(OP_16), 0x01, 0x11}},
+		{"0x0102 DUP op_equal", []byte{0x02, 0x01, 0x02, byte(OP_DUP), byte(OP_EQUAL)}},
+		{"1 IF 1000 CHECKHEIGHTVERIFY ENDIF", []byte{byte(OP_1), byte(OP_IF), 0x02, 0x03, 0xe8, byte(OP_CHECKHEIGHTVERIFY), byte(OP_ENDIF)}},
+	}
+
+	for _, test := range tests {
+		script, err := Assemble(test.text)
+		if err != nil {
+			t.Fatalf("Assemble(%v): %v", test.text, err)
+		}
+
+		if !bytes.Equal(script, test.script) {
+			t.Errorf("Assemble(%v): expected %x, got %x", test.text, test.script, script)
+		}
+
+		// The disassembled script assembles into the same script
+		text, err := Disassemble(script)
+		if err != nil {
+			t.Fatalf("Disassemble(%x): %v", script, err)
+		}
+
+		if again, err := Assemble(text); err != nil || !bytes.Equal(again, script) {
+			t.Errorf("Assemble(%v): expected %x, got %x (%v)", text, script, again, err)
+		}
+	}
+
+	for _, text := range []string{"NOPE", "0xzz", "IF", "1 ENDIF"} {
+		if _, err := Assemble(text); !errors.Is(err, ErrInvalidScript) {
+			t.Errorf("Assemble(%v): expected ErrInvalidScript, got %v", text, err)
+		}
+	}
+}
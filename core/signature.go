@@ -0,0 +1,83 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core/script"
+)
+
+var (
+	// ErrMissingSignature is returned when a Transaction that requires a signature is not signed
+	ErrMissingSignature = errors.New("transaction is not signed")
+	// ErrInvalidSignature is returned when the signature of a Transaction is not valid for its sender
+	ErrInvalidSignature = errors.New("invalid transaction signature")
+)
+
+// Sign signs the Transaction with the given Ed25519 private key, setting its public key and signature.
+// Returns an error if the key does not belong to the sender of the Transaction.
+func (txn *Transaction) Sign(key ed25519.PrivateKey) error {
+	if len(key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("invalid private key length %v", len(key))
+	}
+
+	publicKey := key.Public().(ed25519.PublicKey)
+	if address := common.PublicKeyToAddress(publicKey); address != txn.From {
+		return fmt.Errorf("key of '%v' cannot sign for sender '%v'", address, txn.From)
+	}
+
+	txn.PublicKey = publicKey
+	txn.Signature = ed25519.Sign(key, txn.SigningPayload())
+
+	return nil
+}
+
+// SignScript returns the signature of the given Ed25519 private key over the signing payload of the Transaction.
+// It is pushed by the witness of a Transaction whose sender has a lock script that checks the signature.
+func (txn *Transaction) SignScript(key ed25519.PrivateKey) ([]byte, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid private key length %v", len(key))
+	}
+
+	return ed25519.Sign(key, txn.SigningPayload()), nil
+}
+
+// VerifySender checks that the sender authorized the Transaction in a Block at the given height.
+// A sender with a lock script must satisfy it with the witness of the Transaction, while all
+// other senders must sign it (see VerifySignature).
+func (txn *Transaction) VerifySender(height int64) error {
+	if !txn.IsScripted() {
+		return txn.VerifySignature()
+	}
+
+	if address := script.Address(txn.Script); address != txn.From {
+		return fmt.Errorf("%w: lock script of '%v' does not belong to sender '%v'", script.ErrScriptFailed, address, txn.From)
+	}
+
+	return script.Execute(txn.Witness, txn.Script, &script.Context{Payload: txn.SigningPayload(), Height: height})
+}
+
+// VerifySignature checks that the Transaction is signed by its sender.
+// The public key of the Transaction must belong to the sender and the
+// signature must be valid for the signing payload under that key.
+func (txn *Transaction) VerifySignature() error {
+	if len(txn.Signature) == 0 {
+		return ErrMissingSignature
+	}
+
+	if len(txn.PublicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: invalid public key length %v", ErrInvalidSignature, len(txn.PublicKey))
+	}
+
+	if address := common.PublicKeyToAddress(txn.PublicKey); address != txn.From {
+		return fmt.Errorf("%w: public key of '%v' does not belong to sender '%v'", ErrInvalidSignature, address, txn.From)
+	}
+
+	if !ed25519.Verify(txn.PublicKey, txn.SigningPayload(), txn.Signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
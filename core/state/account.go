@@ -0,0 +1,58 @@
+package state
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+// Account represents the state of an address on the blockchain
+type Account struct {
+	// Represents the balance of the account
+	Balance common.Amount
+	// Represents the number of transactions sent from the account.
+	// The next transaction from the account must have this nonce.
+	Nonce uint64
+}
+
+// Serialize implements the common.Serializable interface for Account.
+// Converts the Account into a stream of bytes encoded using common.GobEncode.
+func (account *Account) Serialize() ([]byte, error) {
+	return common.GobEncode(account)
+}
+
+// Deserialize implements the common.Serializable interface for Account.
+// Converts the given data into Account and sets it the method's receiver using common.GobDecode.
+func (account *Account) Deserialize(data []byte) error {
+	// Decode the data into a *Account
+	object, err := common.GobDecode(data, new(Account))
+	if err != nil {
+		return err
+	}
+
+	// Cast the object into a *Account and
+	// set it to the method receiver
+	*account = *object.(*Account)
+	return nil
+}
+
+// IsEmpty returns whether the Account has no balance and has never sent a transaction,
+// which is the state of an address that is not in the chain state
+func (account *Account) IsEmpty() bool {
+	return account.Balance == 0 && account.Nonce == 0
+}
+
+// Hash returns the SHA-256 hash of the Account's canonical encoding
+func (account *Account) Hash() common.Hash {
+	return common.Hash256(account.canonical())
+}
+
+// canonical returns the canonical encoding of the Account, which is its balance and nonce in big endian
+func (account *Account) canonical() []byte {
+	var buffer bytes.Buffer
+	_ = binary.Write(&buffer, binary.BigEndian, uint64(account.Balance))
+	_ = binary.Write(&buffer, binary.BigEndian, account.Nonce)
+
+	return buffer.Bytes()
+}
@@ -0,0 +1,276 @@
+package state
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+var (
+	// CoinPrefix is the key prefix for the unspent outputs in the database, which form the UTXO set
+	CoinPrefix = []byte("coin-")
+	// CoinIndexPrefix is the key prefix for the index of the unspent outputs by the address they are locked to
+	CoinIndexPrefix = []byte("address-coin-")
+)
+
+var (
+	// ErrMissingCoin is returned when a transaction spends an output that does not exist or has already
+	// been spent, which includes every attempt to spend an output twice
+	ErrMissingCoin = errors.New("output does not exist or has been spent")
+	// ErrLockedCoin is returned when a transaction spends an output that is locked to another address
+	ErrLockedCoin = errors.New("output is locked to another address")
+	// ErrUnbalancedInputs is returned when the inputs of a transaction do not equal its outputs and fee
+	ErrUnbalancedInputs = errors.New("inputs do not equal outputs and fee")
+)
+
+// coinKey returns the database key for the unspent output with the given Outpoint
+func coinKey(outpoint core.Outpoint) []byte {
+	return append(append([]byte{}, CoinPrefix...), outpoint.Bytes()...)
+}
+
+// coinIndexPrefix returns the key prefix of the coin index entries of the given address
+func coinIndexPrefix(address common.Address) []byte {
+	prefix := append(append([]byte{}, CoinIndexPrefix...), address.Bytes()...)
+	return append(prefix, '-')
+}
+
+// coinIndexKey returns the database key for the coin index entry of the given
+// address and the unspent output with the given Outpoint
+func coinIndexKey(address common.Address, outpoint core.Outpoint) []byte {
+	return append(coinIndexPrefix(address), outpoint.Bytes()...)
+}
+
+// decodeOutpoint returns the Outpoint of the given canonical encoding (see core.Outpoint.Bytes)
+func decodeOutpoint(data []byte) core.Outpoint {
+	return core.Outpoint{
+		Hash:  common.BytesToHash(data[:common.HashLength]),
+		Index: binary.BigEndian.Uint32(data[common.HashLength:]),
+	}
+}
+
+// coinChange is the modification of an unspent output since the last commit of a State
+type coinChange struct {
+	// Represents the output that was created or spent
+	output core.TxOutput
+	// Represents whether the output was spent
+	spent bool
+}
+
+// GetCoin returns the unspent output with the given Outpoint.
+// Returns ErrMissingCoin if the output does not exist or has been spent.
+func (state *State) GetCoin(outpoint core.Outpoint) (*core.TxOutput, error) {
+	if change, ok := state.coins[outpoint]; ok {
+		if change.spent {
+			return nil, fmt.Errorf("%w: %v", ErrMissingCoin, outpoint)
+		}
+
+		output := change.output
+		return &output, nil
+	}
+
+	data, err := state.database.GetEntry(coinKey(outpoint))
+	if err != nil {
+		if errors.Is(err, db.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w: %v", ErrMissingCoin, outpoint)
+		}
+
+		return nil, fmt.Errorf("output retrieve failed for '%v': %w", outpoint, err)
+	}
+
+	object, err := common.GobDecode(data, new(core.TxOutput))
+	if err != nil {
+		return nil, fmt.Errorf("output deserialize failed: %w", err)
+	}
+
+	return object.(*core.TxOutput), nil
+}
+
+// GetCoins returns the unspent outputs locked to the given address in order of Outpoint
+func (state *State) GetCoins(address common.Address) ([]core.Coin, error) {
+	coins := make([]core.Coin, 0)
+
+	prefix := coinIndexPrefix(address)
+	if err := state.database.IteratePrefix(prefix, func(key, _ []byte) error {
+		outpoint := decodeOutpoint(key[len(prefix):])
+
+		// Skip the outputs that have been spent since the last commit
+		output, err := state.GetCoin(outpoint)
+		if err != nil {
+			if errors.Is(err, ErrMissingCoin) {
+				return nil
+			}
+
+			return err
+		}
+
+		coins = append(coins, core.Coin{Outpoint: outpoint, Output: *output})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// Add the outputs that have been created since the last commit
+	for outpoint, change := range state.coins {
+		if change.spent || change.output.Address != address {
+			continue
+		}
+
+		if _, err := state.database.GetEntry(coinKey(outpoint)); err == nil {
+			continue
+		}
+
+		coins = append(coins, core.Coin{Outpoint: outpoint, Output: change.output})
+	}
+
+	return coins, nil
+}
+
+// createCoin adds the given output as the unspent output with the given Outpoint
+// and credits its value to the Account of the address it is locked to
+func (state *State) createCoin(outpoint core.Outpoint, output core.TxOutput) error {
+	receiver, err := state.GetAccount(output.Address)
+	if err != nil {
+		return err
+	}
+
+	if receiver.Balance, err = receiver.Balance.Add(output.Value); err != nil {
+		return fmt.Errorf("balance of '%v': %w", output.Address, err)
+	}
+
+	state.setAccount(output.Address, receiver)
+	state.coins[outpoint] = &coinChange{output: output}
+
+	return nil
+}
+
+// applyUTXO applies the state transition for a Transaction on a chain with the UTXO ledger in a Block at the
+// given height. The Transaction must be authorized by the sender and spend unspent outputs locked to the sender,
+// whose value must be exactly that of its outputs and fee. The spent outputs are debited from the sender's Account
+// and the new outputs are credited to the Accounts of their addresses. The nonce of the sender is not used.
+func (state *State) applyUTXO(txn *core.Transaction, height int64) error {
+	// Check that the transaction is authorized by the sender
+	if err := txn.VerifySender(height); err != nil {
+		return err
+	}
+
+	hash, err := txn.Hash()
+	if err != nil {
+		return err
+	}
+
+	// Spend the inputs, which must be unspent outputs locked to the sender
+	var total common.Amount
+	for idx, input := range txn.Inputs {
+		output, err := state.GetCoin(input.Previous)
+		if err != nil {
+			return fmt.Errorf("input %v: %w", idx, err)
+		}
+
+		if output.Address != txn.From {
+			return fmt.Errorf("input %v: %w: %v is locked to '%v'", idx, ErrLockedCoin, input.Previous, output.Address)
+		}
+
+		if total, err = total.Add(output.Value); err != nil {
+			return fmt.Errorf("input %v: %w", idx, err)
+		}
+
+		state.coins[input.Previous] = &coinChange{output: *output, spent: true}
+	}
+
+	// Check that the inputs pay exactly for the outputs and fee
+	cost, err := txn.Cost()
+	if err != nil {
+		return err
+	}
+
+	if total != cost {
+		return fmt.Errorf("%w: inputs have %v, outputs and fee have %v", ErrUnbalancedInputs, total, cost)
+	}
+
+	sender, err := state.GetAccount(txn.From)
+	if err != nil {
+		return err
+	}
+
+	if sender.Balance, err = sender.Balance.Sub(total); err != nil {
+		return fmt.Errorf("%w: '%v' has %v, needs %v", ErrInsufficientBalance, txn.From, sender.Balance, total)
+	}
+
+	state.setAccount(txn.From, sender)
+
+	// Create the outputs of the transaction
+	for idx, output := range txn.Outputs {
+		if err := state.createCoin(core.Outpoint{Hash: hash, Index: uint32(idx)}, output); err != nil {
+			return fmt.Errorf("output %v: %w", idx, err)
+		}
+	}
+
+	return nil
+}
+
+// updateCoins updates the Trie with the modified unspent outputs
+func (state *State) updateCoins() error {
+	for outpoint, change := range state.coins {
+		var err error
+		if change.spent {
+			err = state.trie.RemoveCoin(outpoint)
+		} else {
+			err = state.trie.UpdateCoin(outpoint, &change.output)
+		}
+
+		if err != nil {
+			return fmt.Errorf("state trie update failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// commitCoins adds the modified unspent outputs and their coin index entries to the given batch
+func (state *State) commitCoins(batch *db.Batch) error {
+	for outpoint, change := range state.coins {
+		if change.spent {
+			batch.Delete(coinKey(outpoint))
+			batch.Delete(coinIndexKey(change.output.Address, outpoint))
+			continue
+		}
+
+		if err := RestoreCoin(batch, &core.Coin{Outpoint: outpoint, Output: change.output}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IterateCoins calls fn with every unspent output in the database in order of Outpoint.
+// Iteration stops at the first error returned by fn.
+func IterateCoins(database *db.Database, fn func(*core.Coin) error) error {
+	return database.IteratePrefix(CoinPrefix, func(key, value []byte) error {
+		object, err := common.GobDecode(value, new(core.TxOutput))
+		if err != nil {
+			return fmt.Errorf("output deserialize failed: %w", err)
+		}
+
+		outpoint := decodeOutpoint(key[len(CoinPrefix):])
+		return fn(&core.Coin{Outpoint: outpoint, Output: *object.(*core.TxOutput)})
+	})
+}
+
+// RestoreCoin adds the given unspent output and its coin index entry to the batch,
+// which restores it into the database once the batch is written
+func RestoreCoin(batch *db.Batch, coin *core.Coin) error {
+	data, err := common.GobEncode(&coin.Output)
+	if err != nil {
+		return fmt.Errorf("output serialize failed: %w", err)
+	}
+
+	batch.Set(coinKey(coin.Outpoint), data)
+	batch.Set(coinIndexKey(coin.Output.Address, coin.Outpoint), []byte{})
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package state
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+// ErrUnorderedAccounts is returned when Accounts are not added to a Commitment in increasing order of address
+var ErrUnorderedAccounts = errors.New("accounts are not in order of address")
+
+// Commitment computes the commitment hash of a set of Accounts. The hash is the Hash256 of the canonical
+// encoding of every Account in increasing order of address, which is the address prefixed by its big endian
+// length followed by the big endian balance and nonce. Two sets of Accounts have the same commitment only if they are identical.
+type Commitment struct {
+	// Represents the SHA-256 hash of the encoded Accounts
+	hasher hash.Hash
+	// Represents the address of the last Account that was added
+	last common.Address
+	// Represents the number of Accounts that were added
+	count int64
+}
+
+// NewCommitment returns a new Commitment without any Accounts
+func NewCommitment() *Commitment {
+	return &Commitment{hasher: sha256.New()}
+}
+
+// Add adds the Account with the given address to the Commitment.
+// Returns ErrUnorderedAccounts if the address does not follow the address of the previous Account.
+func (commitment *Commitment) Add(address common.Address, account *Account) error {
+	if commitment.count > 0 && address <= commitment.last {
+		return fmt.Errorf("%w: '%v' follows '%v'", ErrUnorderedAccounts, address, commitment.last)
+	}
+
+	var buffer bytes.Buffer
+	_ = binary.Write(&buffer, binary.BigEndian, uint64(len(address)))
+	buffer.Write(address.Bytes())
+	buffer.Write(account.canonical())
+
+	commitment.hasher.Write(buffer.Bytes())
+	commitment.last = address
+	commitment.count++
+
+	return nil
+}
+
+// Count returns the number of Accounts in the Commitment
+func (commitment *Commitment) Count() int64 {
+	return commitment.count
+}
+
+// Sum returns the commitment hash of the Accounts.
+// It is equal to the common.Hash256 of their encoding.
+func (commitment *Commitment) Sum() common.Hash {
+	return common.Hash(sha256.Sum256(commitment.hasher.Sum(nil)))
+}
+
+// Iterate calls fn with every Account in the database in increasing order of address.
+// Iteration stops at the first error returned by fn.
+func Iterate(database *db.Database, fn func(common.Address, *Account) error) error {
+	return database.IteratePrefix(AccountPrefix, func(key, value []byte) error {
+		account := new(Account)
+		if err := account.Deserialize(value); err != nil {
+			return fmt.Errorf("account deserialize failed: %w", err)
+		}
+
+		return fn(common.Address(key[len(AccountPrefix):]), account)
+	})
+}
+
+// ComputeCommitment returns the Commitment of all the Accounts in the database
+func ComputeCommitment(database *db.Database) (*Commitment, error) {
+	commitment := NewCommitment()
+	if err := Iterate(database, commitment.Add); err != nil {
+		return nil, err
+	}
+
+	return commitment, nil
+}
+
+// Restore adds the given Account with the given address to the batch,
+// which restores it into the database once the batch is written
+func Restore(batch *db.Batch, address common.Address, account *Account) error {
+	data, err := account.Serialize()
+	if err != nil {
+		return fmt.Errorf("account serialize failed: %w", err)
+	}
+
+	batch.Set(accountKey(address), data)
+	return nil
+}
@@ -0,0 +1,229 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+// AccountPrefix is the key prefix for Account data in the database
+var AccountPrefix = []byte("account-")
+
+var (
+	// ErrInsufficientBalance is returned when a sender cannot afford a transaction
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	// ErrInvalidNonce is returned when a transaction nonce does not match the sender's account nonce
+	ErrInvalidNonce = errors.New("invalid nonce")
+)
+
+// accountKey returns the database key for the Account of the given address
+func accountKey(address common.Address) []byte {
+	return append(append([]byte{}, AccountPrefix...), address.Bytes()...)
+}
+
+// State is a view over the account state of the blockchain.
+// Accounts are read from the database and any modifications are
+// held in memory until they are committed into a database batch.
+// The Accounts are also held in a Trie, whose root commits to them.
+// On a chain with the UTXO ledger, the State also holds the set of
+// unspent outputs, which are committed to by the same Trie.
+type State struct {
+	// Represents the database containing all Account data indexed by their address
+	database *db.Database
+	// Represents the Trie of the Accounts
+	trie *Trie
+	// Represents the Accounts modified since the last commit
+	dirty map[common.Address]*Account
+	// Represents the unspent outputs created or spent since the last commit
+	coins map[core.Outpoint]*coinChange
+	// Represents whether the chain has the UTXO ledger
+	utxo bool
+}
+
+// New returns a new State backed by the given database, whose Accounts have the given state root,
+// for a chain with the given ledger (see core.ChainParams)
+func New(database *db.Database, root common.Hash, ledger string) *State {
+	return &State{
+		database: database,
+		trie:     NewTrie(database, root),
+		dirty:    make(map[common.Address]*Account),
+		coins:    make(map[core.Outpoint]*coinChange),
+		utxo:     ledger == core.LedgerUTXO,
+	}
+}
+
+// GetAccount returns the Account for the given address.
+// An empty Account is returned if the address has no state.
+func (state *State) GetAccount(address common.Address) (*Account, error) {
+	// Return a copy of the account if it has been modified
+	if account, ok := state.dirty[address]; ok {
+		acc := *account
+		return &acc, nil
+	}
+
+	// Find the Account data in the database
+	data, err := state.database.GetEntry(accountKey(address))
+	if err != nil {
+		if errors.Is(err, db.ErrKeyNotFound) {
+			return new(Account), nil
+		}
+
+		return nil, fmt.Errorf("account retrieve failed for '%v': %w", address, err)
+	}
+
+	// Create a new Account and deserialize the account data into it
+	account := new(Account)
+	if err := account.Deserialize(data); err != nil {
+		return nil, fmt.Errorf("account deserialize failed: %w", err)
+	}
+
+	return account, nil
+}
+
+// setAccount marks the Account for the given address as modified
+func (state *State) setAccount(address common.Address, account *Account) {
+	state.dirty[address] = account
+}
+
+// ApplyTransaction applies the state transition for a Transaction in a Block at the given height.
+// Coinbase transactions credit their value to the receiver, while all other transactions must be
+// authorized by the sender (see core.Transaction.VerifySender), move their value from the sender
+// to the receiver, debit their fee from the sender and increment the sender's nonce.
+// The fee is credited to the miner by the coinbase transaction of the block.
+// Transactions with inputs and outputs are applied by applyUTXO, and on a chain with
+// the UTXO ledger, the coinbase also creates an unspent output of its value for the receiver.
+// Returns an error if the sender cannot apply the Transaction.
+func (state *State) ApplyTransaction(txn *core.Transaction, height int64) error {
+	if txn.IsUTXO() {
+		return state.applyUTXO(txn, height)
+	}
+
+	if txn.IsCoinbase() && state.utxo {
+		// A coinbase without any value has no output to create
+		if txn.Value == 0 {
+			return nil
+		}
+
+		outpoint, err := txn.Outpoint(0)
+		if err != nil {
+			return err
+		}
+
+		return state.createCoin(outpoint, core.TxOutput{Value: txn.Value, Address: txn.To})
+	}
+
+	if !txn.IsCoinbase() {
+		// Check that the transaction is authorized by the sender
+		if err := txn.VerifySender(height); err != nil {
+			return err
+		}
+
+		sender, err := state.GetAccount(txn.From)
+		if err != nil {
+			return err
+		}
+
+		// Check that the transaction is the next one for the sender
+		if txn.Nonce != sender.Nonce {
+			return fmt.Errorf("%w: '%v' expects nonce %v, got %v", ErrInvalidNonce, txn.From, sender.Nonce, txn.Nonce)
+		}
+
+		// Check that the sender can afford the value and fee of the transaction
+		cost, err := txn.Cost()
+		if err != nil {
+			return err
+		}
+
+		balance, err := sender.Balance.Sub(cost)
+		if err != nil {
+			return fmt.Errorf("%w: '%v' has %v, needs %v", ErrInsufficientBalance, txn.From, sender.Balance, cost)
+		}
+
+		sender.Balance = balance
+		sender.Nonce++
+		state.setAccount(txn.From, sender)
+	}
+
+	receiver, err := state.GetAccount(txn.To)
+	if err != nil {
+		return err
+	}
+
+	if receiver.Balance, err = receiver.Balance.Add(txn.Value); err != nil {
+		return fmt.Errorf("balance of '%v': %w", txn.To, err)
+	}
+
+	state.setAccount(txn.To, receiver)
+
+	return nil
+}
+
+// ApplyTransactions applies the state transition for each Transaction in
+// a Block at the given height in order and returns the first error that occurs.
+func (state *State) ApplyTransactions(txns core.Transactions, height int64) error {
+	for idx, txn := range txns {
+		if err := state.ApplyTransaction(txn, height); err != nil {
+			return fmt.Errorf("transaction %v: %w", idx, err)
+		}
+	}
+
+	return nil
+}
+
+// Root returns the state root of the State with its modified Accounts and unspent
+// outputs, which is the root of the Trie of its Accounts and unspent outputs
+func (state *State) Root() (common.Hash, error) {
+	for address, account := range state.dirty {
+		if err := state.trie.Update(address, account); err != nil {
+			return common.NullHash(), fmt.Errorf("state trie update failed: %w", err)
+		}
+	}
+
+	if err := state.updateCoins(); err != nil {
+		return common.NullHash(), err
+	}
+
+	return state.trie.Root(), nil
+}
+
+// Prove returns the AccountProof of the state of the given address against the state root of the State.
+// The State must not have any modified Accounts.
+func (state *State) Prove(address common.Address) (*AccountProof, error) {
+	if len(state.dirty) > 0 || len(state.coins) > 0 {
+		return nil, fmt.Errorf("state has uncommitted accounts or outputs")
+	}
+
+	return state.trie.Prove(address)
+}
+
+// Commit adds all modified Accounts and unspent outputs and the modified nodes of their Trie to the given
+// batch and resets the modifications. The State only reflects the modifications in the database once the batch is written.
+func (state *State) Commit(batch *db.Batch) error {
+	if _, err := state.Root(); err != nil {
+		return err
+	}
+
+	if err := state.trie.Commit(batch); err != nil {
+		return err
+	}
+
+	for address, account := range state.dirty {
+		data, err := account.Serialize()
+		if err != nil {
+			return fmt.Errorf("account serialize failed: %w", err)
+		}
+
+		batch.Set(accountKey(address), data)
+	}
+
+	if err := state.commitCoins(batch); err != nil {
+		return err
+	}
+
+	state.dirty = make(map[common.Address]*Account)
+	state.coins = make(map[core.Outpoint]*coinChange)
+	return nil
+}
@@ -0,0 +1,80 @@
+package state
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+func TestApplyTransaction(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	key := ed25519.NewKeyFromSeed(seed)
+	sender := common.PublicKeyToAddress(key.Public().(ed25519.PublicKey))
+
+	seed[0] = 1
+	receiverKey := ed25519.NewKeyFromSeed(seed)
+	receiver := common.PublicKeyToAddress(receiverKey.Public().(ed25519.PublicKey))
+
+	// signed returns a transfer from the sender to the receiver that is signed by the sender
+	signed := func(nonce uint64, value, fee common.Amount) *core.Transaction {
+		txn := core.NewTransaction(core.ChainID, sender, receiver, nonce, value, fee)
+		if err := txn.Sign(key); err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+
+		return txn
+	}
+
+	unsigned := core.NewTransaction(core.ChainID, sender, receiver, 0, 100, 1)
+	unfunded := core.NewTransaction(core.ChainID, receiver, sender, 0, 1, 0)
+	if err := unfunded.Sign(receiverKey); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	// The transactions are applied in order onto the same State
+	chainstate := New(openTestDB(t), common.NullHash(), core.LedgerAccount)
+	tests := []struct {
+		name     string
+		txn      *core.Transaction
+		err      error
+		sender   Account
+		receiver Account
+	}{
+		{"coinbase credits its receiver", core.NewCoinbaseTransaction(core.ChainID, sender, 0, 1000), nil, Account{Balance: 1000}, Account{}},
+		{"unsigned transfer", unsigned, core.ErrMissingSignature, Account{Balance: 1000}, Account{}},
+		{"unfunded transfer", unfunded, ErrInsufficientBalance, Account{Balance: 1000}, Account{}},
+		{"nonce ahead of the sender", signed(1, 100, 1), ErrInvalidNonce, Account{Balance: 1000}, Account{}},
+		{"transfer", signed(0, 100, 1), nil, Account{Balance: 899, Nonce: 1}, Account{Balance: 100}},
+		{"replayed transfer", signed(0, 100, 1), ErrInvalidNonce, Account{Balance: 899, Nonce: 1}, Account{Balance: 100}},
+		{"value and fee above the balance", signed(1, 899, 1), ErrInsufficientBalance, Account{Balance: 899, Nonce: 1}, Account{Balance: 100}},
+		{"transfer of the whole balance", signed(1, 898, 1), nil, Account{Nonce: 2}, Account{Balance: 998}},
+	}
+
+	for _, test := range tests {
+		err := chainstate.ApplyTransaction(test.txn, 1)
+		if test.err == nil && err != nil {
+			t.Fatalf("ApplyTransaction (%v): %v", test.name, err)
+		} else if test.err != nil && !errors.Is(err, test.err) {
+			t.Fatalf("ApplyTransaction (%v): expected %v, got %v", test.name, test.err, err)
+		}
+
+		for address, expected := range map[common.Address]Account{sender: test.sender, receiver: test.receiver} {
+			account, err := chainstate.GetAccount(address)
+			if err != nil {
+				t.Fatalf("GetAccount(%v): %v", address, err)
+			}
+
+			if *account != expected {
+				t.Errorf("ApplyTransaction (%v): expected %+v for '%v', got %+v", test.name, expected, address, *account)
+			}
+		}
+	}
+
+	// The coinbase has no sender whose account is debited
+	if account, err := chainstate.GetAccount(common.NullAddress()); err != nil || *account != (Account{}) {
+		t.Errorf("GetAccount: expected an empty account for the null address, got %+v (%v)", account, err)
+	}
+}
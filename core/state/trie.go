@@ -0,0 +1,623 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+// TrieNodePrefix is the key prefix for the nodes of the state Trie in the database
+var TrieNodePrefix = []byte("trie-node-")
+
+var (
+	// ErrMissingTrieNode is returned when a node of a Trie is not found
+	ErrMissingTrieNode = errors.New("trie node not found")
+	// ErrInvalidAccountProof is returned when an AccountProof does not prove the state of an Account
+	ErrInvalidAccountProof = errors.New("invalid account proof")
+)
+
+// trieDepth is the number of bits in the key of a Trie leaf, which is the maximum depth of a Trie
+const trieDepth = common.HashLength * 8
+
+// pruneBatchSize is the maximum number of Trie nodes that are deleted in a single batch by PruneTrie
+const pruneBatchSize = 1000
+
+// trieNodeKey returns the database key for the Trie node with the given hash
+func trieNodeKey(hash common.Hash) []byte {
+	return append(append([]byte{}, TrieNodePrefix...), hash.Bytes()...)
+}
+
+// TrieKey returns the key of the leaf of the given address in a Trie, which is the hash of the address.
+// The bits of the key from the most significant one are the path from the root of the Trie to the leaf.
+func TrieKey(address common.Address) common.Hash {
+	return common.Hash256(address.Bytes())
+}
+
+// CoinKey returns the key of the leaf of the unspent output with the given Outpoint in a Trie,
+// which is the hash of the canonical encoding of the Outpoint prefixed by a marker
+func CoinKey(outpoint core.Outpoint) common.Hash {
+	return common.Hash256(append([]byte("coin"), outpoint.Bytes()...))
+}
+
+// keyBit returns whether the bit of the given key at the given depth is set
+func keyBit(key common.Hash, depth int) bool {
+	return key[depth/8]&(0x80>>(depth%8)) != 0
+}
+
+// TrieLeaf is a leaf of a Trie, which holds the hash of the Account of an address
+// or the hash of an unspent output on a chain with the UTXO ledger
+type TrieLeaf struct {
+	// Represents the key of the leaf (see TrieKey and CoinKey)
+	Key common.Hash
+	// Represents the hash of the Account or output of the leaf
+	Value common.Hash
+}
+
+// Hash returns the hash of the TrieLeaf. Leaves are prefixed with a marker
+// so that a leaf can never be mistaken for an interior node of the Trie.
+func (leaf *TrieLeaf) Hash() common.Hash {
+	data := make([]byte, 0, 1+2*common.HashLength)
+	data = append(data, 0x00)
+	data = append(data, leaf.Key.Bytes()...)
+	data = append(data, leaf.Value.Bytes()...)
+
+	return common.Hash256(data)
+}
+
+// trieInterior returns the hash of the interior node of a Trie with the given children
+func trieInterior(left, right common.Hash) common.Hash {
+	data := make([]byte, 0, 1+2*common.HashLength)
+	data = append(data, 0x01)
+	data = append(data, left.Bytes()...)
+	data = append(data, right.Bytes()...)
+
+	return common.Hash256(data)
+}
+
+// trieNode is a node of a Trie as it is stored in the database. It is an account leaf
+// if it has an Account, a coin leaf if it has an output and an interior node otherwise.
+type trieNode struct {
+	// Represents the hashes of the children of an interior node
+	Left, Right common.Hash
+	// Represents the address of an account leaf
+	Address common.Address
+	// Represents the Account of an account leaf
+	Account *Account
+	// Represents the Outpoint of a coin leaf
+	Outpoint *core.Outpoint
+	// Represents the unspent output of a coin leaf
+	Output *core.TxOutput
+}
+
+// isLeaf returns whether the trieNode is a leaf
+func (node *trieNode) isLeaf() bool {
+	return node.Account != nil || node.Output != nil
+}
+
+// key returns the key of a leaf trieNode
+func (node *trieNode) key() common.Hash {
+	if node.Output != nil {
+		return CoinKey(*node.Outpoint)
+	}
+
+	return TrieKey(node.Address)
+}
+
+// leaf returns the TrieLeaf of a leaf trieNode
+func (node *trieNode) leaf() *TrieLeaf {
+	if node.Output != nil {
+		return &TrieLeaf{node.key(), node.Output.Hash()}
+	}
+
+	return &TrieLeaf{node.key(), node.Account.Hash()}
+}
+
+// hash returns the hash of the trieNode
+func (node *trieNode) hash() common.Hash {
+	if node.isLeaf() {
+		return node.leaf().Hash()
+	}
+
+	return trieInterior(node.Left, node.Right)
+}
+
+// Trie is a sparse Merkle tree over the Accounts of the chain state, whose root commits to every Account.
+// Each Account is a leaf at the path given by the bits of its TrieKey. On a chain with the UTXO ledger,
+// each unspent output is also a leaf at the path given by its CoinKey. A subtree without any leaves has
+// the null hash and a subtree with a single leaf is the leaf itself, so the Trie has the same root for
+// the same set of leaves regardless of the order in which they were added or removed. Nodes are only
+// removed from the database by PruneTrie, so the Trie of any earlier root remains readable until it is
+// pruned. Modified nodes are held in memory until they are committed into a database batch.
+type Trie struct {
+	// Represents the database containing the committed Trie nodes.
+	// A Trie without a database is held entirely in memory.
+	database *db.Database
+	// Represents the hash of the root node of the Trie
+	root common.Hash
+	// Represents the nodes created since the last commit
+	pending map[common.Hash]*trieNode
+}
+
+// NewTrie returns the Trie with the given root in the given database, which may be nil.
+// The Trie without any Accounts has the null hash as its root.
+func NewTrie(database *db.Database, root common.Hash) *Trie {
+	return &Trie{database, root, make(map[common.Hash]*trieNode)}
+}
+
+// Root returns the root hash of the Trie
+func (trie *Trie) Root() common.Hash {
+	return trie.root
+}
+
+// node returns the trieNode with the given hash
+func (trie *Trie) node(hash common.Hash) (*trieNode, error) {
+	if node, ok := trie.pending[hash]; ok {
+		return node, nil
+	}
+
+	if trie.database == nil {
+		return nil, fmt.Errorf("%w: %v", ErrMissingTrieNode, hash.Hex())
+	}
+
+	data, err := trie.database.GetEntry(trieNodeKey(hash))
+	if err != nil {
+		if errors.Is(err, db.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w: %v", ErrMissingTrieNode, hash.Hex())
+		}
+
+		return nil, fmt.Errorf("trie node retrieve failed: %w", err)
+	}
+
+	object, err := common.GobDecode(data, new(trieNode))
+	if err != nil {
+		return nil, fmt.Errorf("trie node deserialize failed: %w", err)
+	}
+
+	return object.(*trieNode), nil
+}
+
+// put adds the given trieNode to the pending nodes of the Trie and returns its hash
+func (trie *Trie) put(node *trieNode) common.Hash {
+	hash := node.hash()
+	trie.pending[hash] = node
+
+	return hash
+}
+
+// interior adds the interior node with the child at the given side of the given
+// key bit and the sibling at the other side, and returns its hash
+func (trie *Trie) interior(bit bool, child, sibling common.Hash) common.Hash {
+	if bit {
+		return trie.put(&trieNode{Left: sibling, Right: child})
+	}
+
+	return trie.put(&trieNode{Left: child, Right: sibling})
+}
+
+// get returns the leaf with the given key in the Trie, or nil if there is no such leaf
+func (trie *Trie) get(key common.Hash) (*trieNode, error) {
+	hash := trie.root
+
+	for depth := 0; hash != common.NullHash(); depth++ {
+		node, err := trie.node(hash)
+		if err != nil {
+			return nil, err
+		}
+
+		if node.isLeaf() {
+			if node.key() == key {
+				return node, nil
+			}
+
+			break
+		}
+
+		if keyBit(key, depth) {
+			hash = node.Right
+		} else {
+			hash = node.Left
+		}
+	}
+
+	return nil, nil
+}
+
+// GetAccount returns the Account for the given address in the Trie.
+// An empty Account is returned if the address has no state.
+func (trie *Trie) GetAccount(address common.Address) (*Account, error) {
+	node, err := trie.get(TrieKey(address))
+	if err != nil {
+		return nil, err
+	}
+
+	if node == nil || node.Account == nil {
+		return new(Account), nil
+	}
+
+	account := *node.Account
+	return &account, nil
+}
+
+// GetCoin returns the unspent output with the given Outpoint in the Trie.
+// Returns ErrMissingCoin if the output does not exist or has been spent.
+func (trie *Trie) GetCoin(outpoint core.Outpoint) (*core.TxOutput, error) {
+	node, err := trie.get(CoinKey(outpoint))
+	if err != nil {
+		return nil, err
+	}
+
+	if node == nil || node.Output == nil {
+		return nil, fmt.Errorf("%w: %v", ErrMissingCoin, outpoint)
+	}
+
+	output := *node.Output
+	return &output, nil
+}
+
+// Update sets the Account for the given address in the Trie and updates its root
+func (trie *Trie) Update(address common.Address, account *Account) error {
+	acc := *account
+	return trie.set(&trieNode{Address: address, Account: &acc})
+}
+
+// UpdateCoin sets the unspent output with the given Outpoint in the Trie and updates its root
+func (trie *Trie) UpdateCoin(outpoint core.Outpoint, output *core.TxOutput) error {
+	out := *output
+	return trie.set(&trieNode{Outpoint: &outpoint, Output: &out})
+}
+
+// RemoveCoin removes the unspent output with the given Outpoint from the Trie and updates
+// its root. The Trie is unchanged if it does not have the output.
+func (trie *Trie) RemoveCoin(outpoint core.Outpoint) error {
+	root, err := trie.remove(trie.root, 0, CoinKey(outpoint))
+	if err != nil {
+		return err
+	}
+
+	trie.root = root
+	return nil
+}
+
+// set inserts the given leaf into the Trie and updates its root
+func (trie *Trie) set(leaf *trieNode) error {
+	root, err := trie.insert(trie.root, 0, leaf.key(), leaf)
+	if err != nil {
+		return err
+	}
+
+	trie.root = root
+	return nil
+}
+
+// insert adds the given leaf with the given key into the subtree with the given hash
+// at the given depth of the Trie, and returns the hash of the modified subtree
+func (trie *Trie) insert(hash common.Hash, depth int, key common.Hash, leaf *trieNode) (common.Hash, error) {
+	if hash == common.NullHash() {
+		return trie.put(leaf), nil
+	}
+
+	node, err := trie.node(hash)
+	if err != nil {
+		return common.NullHash(), err
+	}
+
+	if node.isLeaf() {
+		// Replace the leaf of the same key
+		if node.key() == key {
+			return trie.put(leaf), nil
+		}
+
+		return trie.split(hash, node.key(), depth, key, leaf), nil
+	}
+
+	bit := keyBit(key, depth)
+	child, sibling := node.Left, node.Right
+	if bit {
+		child, sibling = node.Right, node.Left
+	}
+
+	if child, err = trie.insert(child, depth+1, key, leaf); err != nil {
+		return common.NullHash(), err
+	}
+
+	return trie.interior(bit, child, sibling), nil
+}
+
+// split returns the hash of the subtree at the given depth with the existing leaf of the given hash and key and
+// the given new leaf with the given key. The leaves hang from interior nodes down to the first bit where their keys differ.
+func (trie *Trie) split(existing, existingKey common.Hash, depth int, key common.Hash, leaf *trieNode) common.Hash {
+	bit := keyBit(key, depth)
+	if keyBit(existingKey, depth) == bit {
+		return trie.interior(bit, trie.split(existing, existingKey, depth+1, key, leaf), common.NullHash())
+	}
+
+	return trie.interior(bit, trie.put(leaf), existing)
+}
+
+// remove removes the leaf with the given key from the subtree with the given hash at the given depth
+// of the Trie, and returns the hash of the modified subtree. An interior node that is left with a single
+// leaf under it is replaced by the leaf, so that the subtree is the same as if the leaf was never added.
+func (trie *Trie) remove(hash common.Hash, depth int, key common.Hash) (common.Hash, error) {
+	if hash == common.NullHash() {
+		return hash, nil
+	}
+
+	node, err := trie.node(hash)
+	if err != nil {
+		return common.NullHash(), err
+	}
+
+	if node.isLeaf() {
+		if node.key() == key {
+			return common.NullHash(), nil
+		}
+
+		return hash, nil
+	}
+
+	bit := keyBit(key, depth)
+	child, sibling := node.Left, node.Right
+	if bit {
+		child, sibling = node.Right, node.Left
+	}
+
+	removed, err := trie.remove(child, depth+1, key)
+	if err != nil || removed == child {
+		return hash, err
+	}
+
+	// Replace the node by its only remaining child if it is a leaf
+	only := common.NullHash()
+	switch {
+	case removed == common.NullHash():
+		only = sibling
+	case sibling == common.NullHash():
+		only = removed
+	}
+
+	if only != common.NullHash() {
+		node, err := trie.node(only)
+		if err != nil {
+			return common.NullHash(), err
+		}
+
+		if node.isLeaf() {
+			return only, nil
+		}
+	}
+
+	if removed == common.NullHash() && sibling == common.NullHash() {
+		return common.NullHash(), nil
+	}
+
+	return trie.interior(bit, removed, sibling), nil
+}
+
+// Commit adds the pending nodes of the Trie that are reachable from its root to the
+// given batch and resets the pending nodes. The nodes are only readable from the
+// database once the batch is written. Returns an error if the Trie has no database.
+func (trie *Trie) Commit(batch *db.Batch) error {
+	if trie.database == nil {
+		return fmt.Errorf("trie without a database cannot be committed")
+	}
+
+	// Nodes that are not pending have already been committed, and so have all their descendants
+	stack := []common.Hash{trie.root}
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		node, ok := trie.pending[hash]
+		if !ok {
+			continue
+		}
+
+		data, err := common.GobEncode(node)
+		if err != nil {
+			return fmt.Errorf("trie node serialize failed: %w", err)
+		}
+
+		batch.Set(trieNodeKey(hash), data)
+		if !node.isLeaf() {
+			stack = append(stack, node.Left, node.Right)
+		}
+	}
+
+	trie.pending = make(map[common.Hash]*trieNode)
+	return nil
+}
+
+// BuildTrie builds the Trie of the Accounts and unspent outputs in the given database, adds its nodes to
+// the given batch and returns its root. It builds the Trie of a chain state whose nodes were never stored.
+func BuildTrie(database *db.Database, batch *db.Batch) (common.Hash, error) {
+	trie := NewTrie(database, common.NullHash())
+	if err := Iterate(database, trie.Update); err != nil {
+		return common.NullHash(), err
+	}
+
+	if err := IterateCoins(database, func(coin *core.Coin) error {
+		return trie.UpdateCoin(coin.Outpoint, &coin.Output)
+	}); err != nil {
+		return common.NullHash(), err
+	}
+
+	if err := trie.Commit(batch); err != nil {
+		return common.NullHash(), err
+	}
+
+	return trie.Root(), nil
+}
+
+// PruneTrie deletes the nodes of the Tries in the given database that are not reachable from any of the given
+// roots, so that only the Tries with those roots remain readable. Nodes that are shared by the Tries are visited
+// once, and roots whose nodes are missing are skipped. Returns the number of deleted nodes.
+func PruneTrie(database *db.Database, roots []common.Hash) (int, error) {
+	trie := NewTrie(database, common.NullHash())
+
+	// Mark the nodes that are reachable from the roots
+	reachable := make(map[common.Hash]bool)
+	stack := append([]common.Hash{}, roots...)
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if hash == common.NullHash() || reachable[hash] {
+			continue
+		}
+
+		node, err := trie.node(hash)
+		if err != nil {
+			if errors.Is(err, ErrMissingTrieNode) {
+				continue
+			}
+
+			return 0, err
+		}
+
+		reachable[hash] = true
+		if !node.isLeaf() {
+			stack = append(stack, node.Left, node.Right)
+		}
+	}
+
+	// Collect the keys of the unreachable nodes
+	unreachable := make([][]byte, 0)
+	if err := database.IteratePrefix(TrieNodePrefix, func(key, _ []byte) error {
+		if !reachable[common.BytesToHash(key[len(TrieNodePrefix):])] {
+			unreachable = append(unreachable, append([]byte{}, key...))
+		}
+
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("trie node iteration failed: %w", err)
+	}
+
+	// Delete the unreachable nodes in batches
+	for start := 0; start < len(unreachable); start += pruneBatchSize {
+		end := start + pruneBatchSize
+		if end > len(unreachable) {
+			end = len(unreachable)
+		}
+
+		batch := db.NewBatch()
+		for _, key := range unreachable[start:end] {
+			batch.Delete(key)
+		}
+
+		if err := database.WriteBatch(batch); err != nil {
+			return start, fmt.Errorf("trie node delete failed: %w", err)
+		}
+	}
+
+	return len(unreachable), nil
+}
+
+// AccountProof is a proof of the state of an address against the root of a Trie.
+// It proves the Account of an address that is in the Trie and the empty Account
+// of an address that is not.
+type AccountProof struct {
+	// Represents the siblings of the nodes on the path from the root of the Trie to the
+	// last node on the path of the address, in order of depth from the root
+	Siblings []common.Hash
+	// Represents the leaf at the end of the path, which is nil if the path ends in an empty subtree.
+	// If it is the leaf of another address, the address is not in the Trie.
+	Leaf *TrieLeaf
+}
+
+// Prove returns the AccountProof of the state of the given address in the Trie
+func (trie *Trie) Prove(address common.Address) (*AccountProof, error) {
+	key := TrieKey(address)
+	proof := &AccountProof{Siblings: make([]common.Hash, 0)}
+
+	hash := trie.root
+	for depth := 0; hash != common.NullHash(); depth++ {
+		node, err := trie.node(hash)
+		if err != nil {
+			return nil, err
+		}
+
+		if node.isLeaf() {
+			proof.Leaf = node.leaf()
+			break
+		}
+
+		if keyBit(key, depth) {
+			hash = node.Right
+			proof.Siblings = append(proof.Siblings, node.Left)
+		} else {
+			hash = node.Left
+			proof.Siblings = append(proof.Siblings, node.Right)
+		}
+	}
+
+	return proof, nil
+}
+
+// Verify checks that the AccountProof proves that the given address has the given Account in the
+// Trie with the given root. An address that is not in the Trie can only be proven to have the empty Account.
+// Returns ErrInvalidAccountProof if it does not.
+func (proof *AccountProof) Verify(address common.Address, account *Account, root common.Hash) error {
+	if len(proof.Siblings) > trieDepth {
+		return fmt.Errorf("%w: %v siblings exceed the depth of the trie", ErrInvalidAccountProof, len(proof.Siblings))
+	}
+
+	key := TrieKey(address)
+	hash := common.NullHash()
+
+	switch {
+	case proof.Leaf != nil && proof.Leaf.Key == key:
+		if proof.Leaf.Value != account.Hash() {
+			return fmt.Errorf("%w: account does not match the leaf of '%v'", ErrInvalidAccountProof, address)
+		}
+
+		hash = proof.Leaf.Hash()
+
+	case !account.IsEmpty():
+		return fmt.Errorf("%w: '%v' is not in the trie", ErrInvalidAccountProof, address)
+
+	case proof.Leaf != nil:
+		// The leaf of another address proves that the address is not in the
+		// trie only if it is the only leaf under the path of the address
+		for depth := range proof.Siblings {
+			if keyBit(proof.Leaf.Key, depth) != keyBit(key, depth) {
+				return fmt.Errorf("%w: leaf is not on the path of '%v'", ErrInvalidAccountProof, address)
+			}
+		}
+
+		hash = proof.Leaf.Hash()
+	}
+
+	for depth := len(proof.Siblings) - 1; depth >= 0; depth-- {
+		if keyBit(key, depth) {
+			hash = trieInterior(proof.Siblings[depth], hash)
+		} else {
+			hash = trieInterior(hash, proof.Siblings[depth])
+		}
+	}
+
+	if hash != root {
+		return fmt.Errorf("%w: expected root %v, got %v", ErrInvalidAccountProof, root.Hex(), hash.Hex())
+	}
+
+	return nil
+}
+
+// ComputeRoot returns the state root of all the Accounts and unspent outputs in the database.
+// It is computed from the Accounts and outputs in memory, without the committed Trie nodes.
+func ComputeRoot(database *db.Database) (common.Hash, error) {
+	trie := NewTrie(nil, common.NullHash())
+	if err := Iterate(database, trie.Update); err != nil {
+		return common.NullHash(), err
+	}
+
+	if err := IterateCoins(database, func(coin *core.Coin) error {
+		return trie.UpdateCoin(coin.Outpoint, &coin.Output)
+	}); err != nil {
+		return common.NullHash(), err
+	}
+
+	return trie.Root(), nil
+}
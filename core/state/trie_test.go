@@ -0,0 +1,312 @@
+package state
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+// testAddress returns a distinct valid address for the given index
+func testAddress(idx byte) common.Address {
+	data := make([]byte, 20)
+	data[19] = idx
+	return common.Address(common.HexEncode(data))
+}
+
+// testAccounts returns the Accounts of the first n test addresses
+func testAccounts(n int) map[common.Address]*Account {
+	accounts := make(map[common.Address]*Account)
+	for idx := 1; idx <= n; idx++ {
+		accounts[testAddress(byte(idx))] = &Account{Balance: common.Amount(idx * 100), Nonce: uint64(idx)}
+	}
+
+	return accounts
+}
+
+// openTestDB opens a database in a temporary directory that is closed when the test ends
+func openTestDB(t *testing.T) *db.Database {
+	database, err := db.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	t.Cleanup(func() { _ = database.Close() })
+	return database
+}
+
+func TestTrieRootOrder(t *testing.T) {
+	accounts := testAccounts(16)
+
+	forward := NewTrie(nil, common.NullHash())
+	for idx := 1; idx <= 16; idx++ {
+		address := testAddress(byte(idx))
+		if err := forward.Update(address, accounts[address]); err != nil {
+			t.Fatalf("Update(%v): %v", address, err)
+		}
+	}
+
+	backward := NewTrie(nil, common.NullHash())
+	for idx := 16; idx >= 1; idx-- {
+		address := testAddress(byte(idx))
+		if err := backward.Update(address, accounts[address]); err != nil {
+			t.Fatalf("Update(%v): %v", address, err)
+		}
+	}
+
+	if forward.Root() != backward.Root() {
+		t.Fatalf("Root: expected %v, got %v", forward.Root().Hex(), backward.Root().Hex())
+	}
+
+	// Updating an account changes the root, and restoring it restores the root
+	address := testAddress(1)
+	if err := forward.Update(address, &Account{Balance: 1}); err != nil {
+		t.Fatalf("Update(%v): %v", address, err)
+	}
+
+	if forward.Root() == backward.Root() {
+		t.Fatalf("Root: expected a different root after an update")
+	}
+
+	if err := forward.Update(address, accounts[address]); err != nil {
+		t.Fatalf("Update(%v): %v", address, err)
+	}
+
+	if forward.Root() != backward.Root() {
+		t.Fatalf("Root: expected %v after restoring the account, got %v", backward.Root().Hex(), forward.Root().Hex())
+	}
+}
+
+func TestTrieRootRemove(t *testing.T) {
+	trie := NewTrie(nil, common.NullHash())
+	if trie.Root() != common.NullHash() {
+		t.Fatalf("Root: expected the null hash for an empty trie, got %v", trie.Root().Hex())
+	}
+
+	coins := []core.Coin{
+		{Outpoint: core.Outpoint{Hash: common.Hash256([]byte("a")), Index: 0}, Output: core.TxOutput{Value: 10, Address: testAddress(1)}},
+		{Outpoint: core.Outpoint{Hash: common.Hash256([]byte("a")), Index: 1}, Output: core.TxOutput{Value: 20, Address: testAddress(2)}},
+		{Outpoint: core.Outpoint{Hash: common.Hash256([]byte("b")), Index: 0}, Output: core.TxOutput{Value: 30, Address: testAddress(3)}},
+	}
+
+	// Each root after an insertion must be restored when the output is removed again
+	roots := []common.Hash{trie.Root()}
+	for _, coin := range coins {
+		coin := coin
+		if err := trie.UpdateCoin(coin.Outpoint, &coin.Output); err != nil {
+			t.Fatalf("UpdateCoin(%v): %v", coin.Outpoint, err)
+		}
+
+		roots = append(roots, trie.Root())
+	}
+
+	for idx := len(coins) - 1; idx >= 0; idx-- {
+		if err := trie.RemoveCoin(coins[idx].Outpoint); err != nil {
+			t.Fatalf("RemoveCoin(%v): %v", coins[idx].Outpoint, err)
+		}
+
+		if trie.Root() != roots[idx] {
+			t.Errorf("RemoveCoin(%v): expected root %v, got %v", coins[idx].Outpoint, roots[idx].Hex(), trie.Root().Hex())
+		}
+	}
+
+	// Removing an output that is not in the trie leaves it unchanged
+	if err := trie.UpdateCoin(coins[0].Outpoint, &coins[0].Output); err != nil {
+		t.Fatalf("UpdateCoin(%v): %v", coins[0].Outpoint, err)
+	}
+
+	if err := trie.RemoveCoin(coins[1].Outpoint); err != nil {
+		t.Fatalf("RemoveCoin(%v): %v", coins[1].Outpoint, err)
+	}
+
+	if trie.Root() != roots[1] {
+		t.Errorf("RemoveCoin(%v): expected root %v, got %v", coins[1].Outpoint, roots[1].Hex(), trie.Root().Hex())
+	}
+}
+
+func TestTrieProve(t *testing.T) {
+	accounts := testAccounts(8)
+
+	trie := NewTrie(nil, common.NullHash())
+	for address, account := range accounts {
+		if err := trie.Update(address, account); err != nil {
+			t.Fatalf("Update(%v): %v", address, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		address common.Address
+		account *Account
+		valid   bool
+	}{
+		{"present", testAddress(3), accounts[testAddress(3)], true},
+		{"present with the wrong account", testAddress(3), &Account{Balance: 1, Nonce: 3}, false},
+		{"present with the empty account", testAddress(3), new(Account), false},
+		{"absent with the empty account", testAddress(200), new(Account), true},
+		{"absent with an account", testAddress(200), &Account{Balance: 1}, false},
+	}
+
+	for _, test := range tests {
+		proof, err := trie.Prove(test.address)
+		if err != nil {
+			t.Fatalf("Prove(%v): %v", test.address, err)
+		}
+
+		err = proof.Verify(test.address, test.account, trie.Root())
+		if test.valid && err != nil {
+			t.Errorf("Verify (%v): %v", test.name, err)
+		} else if !test.valid && !errors.Is(err, ErrInvalidAccountProof) {
+			t.Errorf("Verify (%v): expected ErrInvalidAccountProof, got %v", test.name, err)
+		}
+	}
+
+	// A proof does not verify against another root
+	proof, err := trie.Prove(testAddress(3))
+	if err != nil {
+		t.Fatalf("Prove: %v", err)
+	}
+
+	if err := proof.Verify(testAddress(3), accounts[testAddress(3)], common.Hash256([]byte("root"))); !errors.Is(err, ErrInvalidAccountProof) {
+		t.Errorf("Verify: expected ErrInvalidAccountProof for another root, got %v", err)
+	}
+
+	// The absence of an address is proven in an empty trie
+	empty := NewTrie(nil, common.NullHash())
+	if proof, err = empty.Prove(testAddress(1)); err != nil {
+		t.Fatalf("Prove: %v", err)
+	}
+
+	if err := proof.Verify(testAddress(1), new(Account), empty.Root()); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+}
+
+func TestComputeRoot(t *testing.T) {
+	database := openTestDB(t)
+	accounts := testAccounts(8)
+	coin := core.Coin{Outpoint: core.Outpoint{Hash: common.Hash256([]byte("a"))}, Output: core.TxOutput{Value: 10, Address: testAddress(1)}}
+
+	trie := NewTrie(nil, common.NullHash())
+	batch := db.NewBatch()
+	for address, account := range accounts {
+		if err := trie.Update(address, account); err != nil {
+			t.Fatalf("Update(%v): %v", address, err)
+		}
+
+		if err := Restore(batch, address, account); err != nil {
+			t.Fatalf("Restore(%v): %v", address, err)
+		}
+	}
+
+	if err := trie.UpdateCoin(coin.Outpoint, &coin.Output); err != nil {
+		t.Fatalf("UpdateCoin: %v", err)
+	}
+
+	if err := RestoreCoin(batch, &coin); err != nil {
+		t.Fatalf("RestoreCoin: %v", err)
+	}
+
+	if err := database.WriteBatch(batch); err != nil {
+		t.Fatalf("WriteBatch: %v", err)
+	}
+
+	root, err := ComputeRoot(database)
+	if err != nil {
+		t.Fatalf("ComputeRoot: %v", err)
+	}
+
+	if root != trie.Root() {
+		t.Fatalf("ComputeRoot: expected %v, got %v", trie.Root().Hex(), root.Hex())
+	}
+
+	// The trie built from the database has the same root and proves its accounts
+	batch = db.NewBatch()
+	if root, err = BuildTrie(database, batch); err != nil {
+		t.Fatalf("BuildTrie: %v", err)
+	}
+
+	if err := database.WriteBatch(batch); err != nil {
+		t.Fatalf("WriteBatch: %v", err)
+	}
+
+	if root != trie.Root() {
+		t.Fatalf("BuildTrie: expected %v, got %v", trie.Root().Hex(), root.Hex())
+	}
+
+	stored := NewTrie(database, root)
+	for address, account := range accounts {
+		proof, err := stored.Prove(address)
+		if err != nil {
+			t.Fatalf("Prove(%v): %v", address, err)
+		}
+
+		if err := proof.Verify(address, account, root); err != nil {
+			t.Errorf("Verify(%v): %v", address, err)
+		}
+	}
+}
+
+func TestPruneTrie(t *testing.T) {
+	database := openTestDB(t)
+	address := testAddress(1)
+
+	// Commit two versions of the trie, which share the leaf of the second address
+	trie := NewTrie(database, common.NullHash())
+	roots := make([]common.Hash, 0, 2)
+	for _, balance := range []common.Amount{100, 200} {
+		if err := trie.Update(address, &Account{Balance: balance}); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		if err := trie.Update(testAddress(2), &Account{Balance: 1}); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		batch := db.NewBatch()
+		if err := trie.Commit(batch); err != nil {
+			t.Fatalf("Commit: %v", err)
+		}
+
+		if err := database.WriteBatch(batch); err != nil {
+			t.Fatalf("WriteBatch: %v", err)
+		}
+
+		roots = append(roots, trie.Root())
+	}
+
+	// The interior nodes and the leaf of the first address are only reachable from the first root
+	deleted, err := PruneTrie(database, roots[1:])
+	if err != nil {
+		t.Fatalf("PruneTrie: %v", err)
+	}
+
+	if deleted == 0 {
+		t.Errorf("PruneTrie: expected the nodes of the first root to be deleted")
+	}
+
+	// Pruning again with the same roots deletes nothing
+	if deleted, err = PruneTrie(database, roots[1:]); err != nil || deleted != 0 {
+		t.Errorf("PruneTrie: expected 0 deleted nodes, got %v (%v)", deleted, err)
+	}
+
+	if _, err := NewTrie(database, roots[0]).GetAccount(address); !errors.Is(err, ErrMissingTrieNode) {
+		t.Errorf("GetAccount: expected ErrMissingTrieNode for the pruned root, got %v", err)
+	}
+
+	account, err := NewTrie(database, roots[1]).GetAccount(address)
+	if err != nil {
+		t.Fatalf("GetAccount: %v", err)
+	}
+
+	if account.Balance != 200 {
+		t.Errorf("GetAccount: expected balance 200, got %v", account.Balance)
+	}
+
+	// The shared leaf of the second address is kept
+	if account, err = NewTrie(database, roots[1]).GetAccount(testAddress(2)); err != nil || account.Balance != 1 {
+		t.Errorf("GetAccount: expected balance 1, got %v (%v)", account, err)
+	}
+}
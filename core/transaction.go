@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"encoding/binary"
 
 	"github.com/manishmeganathan/essensio/common"
 )
@@ -12,9 +13,23 @@ type Transactions []*Transaction
 // Transaction represents a transaction between two addresses.
 // It contains a nonce value to make it unique for transactions
 // between the same account with the same value.
+//
+// On a chain with the UTXO ledger, a Transaction instead spends the outputs of previous
+// Transactions locked to its sender into new outputs. It has no receiver, value or nonce,
+// as it is made unique by the outputs it spends.
+//
+// A sender whose address is the address of a lock script (see script.Address) does not sign its
+// Transactions. They instead reveal the lock script, which must succeed when executed after a witness
+// that only pushes data, such as the signatures or hash preimages that the lock script checks.
 type Transaction struct {
-	// Represents the amount of tokens transferred in Nubs
-	Value uint64
+	// Represents the identifier of the chain the transaction is valid on.
+	// It is signed so that the transaction cannot be replayed on another chain.
+	ChainID uint64
+
+	// Represents the amount of tokens transferred
+	Value common.Amount
+	// Represents the fee paid to the miner of the block
+	Fee common.Amount
 	// Represents the sender account nonce
 	Nonce uint64
 
@@ -22,17 +37,75 @@ type Transaction struct {
 	From common.Address
 	// Represents the address of the receiver
 	To common.Address
+
+	// Represents the previous outputs spent by the transaction on a chain with the UTXO ledger
+	Inputs []TxInput
+	// Represents the new outputs created by the transaction on a chain with the UTXO ledger
+	Outputs []TxOutput
+
+	// Represents the public key of the sender
+	PublicKey []byte
+	// Represents the signature of the sender over the signing payload
+	Signature []byte
+
+	// Represents the lock script of a sender whose address is a script address
+	Script []byte
+	// Represents the script that satisfies the lock script of the sender.
+	// It is not part of the signing payload, as it contains the signatures over it.
+	Witness []byte
+}
+
+// NewTransaction generates a new unsigned Transaction on the given chain
+// between from and to for the given value, fee and nonce.
+func NewTransaction(chainID uint64, from, to common.Address, nonce uint64, value, fee common.Amount) *Transaction {
+	return &Transaction{ChainID: chainID, Value: value, Fee: fee, Nonce: nonce, From: from, To: to}
 }
 
-// NewTransaction generates a new Transaction between from and to for the given value and nonce.
-func NewTransaction(from, to common.Address, nonce, value uint64) *Transaction {
-	return &Transaction{value, nonce, from, to}
+// NewCoinbaseTransaction generates a new coinbase transaction that pays the given reward to the given address.
+// The reward for mining a block is the subsidy for its height and the fees of its transactions.
+// The nonce of a coinbase transaction is the height of its block, which makes its hash unique.
+func NewCoinbaseTransaction(chainID uint64, address common.Address, height int64, reward common.Amount) *Transaction {
+	return &Transaction{ChainID: chainID, Value: reward, Nonce: uint64(height), From: common.NullAddress(), To: address}
 }
 
-// newCoinbaseTransaction generates a new coinbase transaction that mints tokens for the given address.
-// The value of the transaction is the default Block Reward for mining a block.
-func newCoinbaseTransaction(address common.Address) *Transaction {
-	return &Transaction{BlockReward, 0, address, common.NullAddress()}
+// IsCoinbase returns whether the Transaction is a coinbase transaction.
+// Coinbase transactions mint new tokens and have no sender.
+func (txn *Transaction) IsCoinbase() bool {
+	return txn.From == common.NullAddress()
+}
+
+// IsScripted returns whether the sender of the Transaction has a lock script
+func (txn *Transaction) IsScripted() bool {
+	return len(txn.Script) != 0
+}
+
+// Cost returns the total amount debited from the sender of the Transaction, its value (see OutputValue)
+// and fee. Returns common.ErrAmountOverflow if the cost overflows.
+func (txn *Transaction) Cost() (common.Amount, error) {
+	value, err := txn.OutputValue()
+	if err != nil {
+		return 0, err
+	}
+
+	return value.Add(txn.Fee)
+}
+
+// Fees returns the sum of the fees of the non-coinbase Transactions.
+// Returns common.ErrAmountOverflow if the sum overflows.
+func (txns Transactions) Fees() (common.Amount, error) {
+	var fees common.Amount
+	for _, txn := range txns {
+		if txn.IsCoinbase() {
+			continue
+		}
+
+		var err error
+		if fees, err = fees.Add(txn.Fee); err != nil {
+			return 0, err
+		}
+	}
+
+	return fees, nil
 }
 
 // Serialize implements the common.Serializable interface for Transaction.
@@ -56,33 +129,62 @@ func (txn *Transaction) Deserialize(data []byte) error {
 	return nil
 }
 
-// Hash returns the SHA-256	hash of the Transaction's serialized representation.
+// Hash returns the SHA-256 hash of the Transaction's canonical encoding,
+// which is its signing payload followed by its length prefixed signature,
+// and its length prefixed witness if the sender has a lock script.
 func (txn *Transaction) Hash() (common.Hash, error) {
-	data, err := txn.Serialize()
-	if err != nil {
-		return common.NullHash(), err
+	buffer := bytes.NewBuffer(txn.SigningPayload())
+	writeBytes(buffer, txn.Signature)
+
+	if txn.IsScripted() {
+		writeBytes(buffer, txn.Witness)
 	}
 
-	return common.Hash256(data), nil
+	return common.Hash256(buffer.Bytes()), nil
 }
 
-// GenerateSummary generates a summary hash for a given set of Transactions.
-// Currently, concatenates the hash of all given transactions and hashes that data to obtain the summary.
-// This is a valid method of summary generation but does not for allow tamper detection or inclusivity checks
-func GenerateSummary(txns Transactions) (common.Hash, error) {
-	// Iterate over each transaction, obtain
-	// its hash and append it into the buffer
+// SigningPayload returns the data of the Transaction that is signed by its sender.
+// It is the canonical encoding of all the fields of the Transaction apart from its signature,
+// with integers in big endian and byte strings prefixed by their length. Unlike its gob encoding,
+// it does not depend on the types previously encoded by the process, so it can be hashed and signed.
+// The inputs and outputs of a Transaction on a chain with the UTXO ledger follow its public key,
+// followed by the length prefixed lock script of a sender with a lock script.
+func (txn *Transaction) SigningPayload() []byte {
 	var buffer bytes.Buffer
-	for _, txn := range txns {
-		hash, err := txn.Hash()
-		if err != nil {
-			return common.NullHash(), err
-		}
+	_ = binary.Write(&buffer, binary.BigEndian, txn.ChainID)
+	_ = binary.Write(&buffer, binary.BigEndian, uint64(txn.Value))
+	_ = binary.Write(&buffer, binary.BigEndian, uint64(txn.Fee))
+	_ = binary.Write(&buffer, binary.BigEndian, txn.Nonce)
+
+	writeBytes(&buffer, txn.From.Bytes())
+	writeBytes(&buffer, txn.To.Bytes())
+	writeBytes(&buffer, txn.PublicKey)
+
+	if txn.IsUTXO() {
+		txn.writeUTXO(&buffer)
+	}
 
-		buffer.Write(hash.Bytes())
+	if txn.IsScripted() {
+		writeBytes(&buffer, txn.Script)
+	}
+
+	return buffer.Bytes()
+}
+
+// writeBytes writes the given data into the buffer prefixed by its big endian length
+func writeBytes(buffer *bytes.Buffer, data []byte) {
+	_ = binary.Write(buffer, binary.BigEndian, uint64(len(data)))
+	buffer.Write(data)
+}
+
+// GenerateSummary generates a summary hash for a given set of Transactions.
+// The summary is the root of the Merkle tree of the Transaction hashes, which allows
+// the inclusion of a Transaction in a Block to be proven with a MerkleProof.
+func GenerateSummary(txns Transactions) (common.Hash, error) {
+	hashes, err := txnHashes(txns)
+	if err != nil {
+		return common.NullHash(), err
 	}
 
-	// Generate the hash of the buffer bytes
-	txnsum := common.Hash256(buffer.Bytes())
-	return txnsum, nil
+	return MerkleRoot(hashes), nil
 }
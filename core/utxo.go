@@ -0,0 +1,188 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+var (
+	// ErrDuplicateInput is returned when a Transaction spends the same output more than once
+	ErrDuplicateInput = errors.New("transaction spends an output more than once")
+	// ErrInsufficientFunds is returned when the unspent outputs of a sender cannot pay for a transfer
+	ErrInsufficientFunds = errors.New("insufficient unspent outputs")
+)
+
+// Outpoint is a reference to an output of a Transaction
+type Outpoint struct {
+	// Represents the hash of the Transaction
+	Hash common.Hash
+	// Represents the position of the output in the Transaction
+	Index uint32
+}
+
+// Bytes returns the canonical encoding of the Outpoint,
+// which is the Transaction hash followed by the big endian index
+func (outpoint Outpoint) Bytes() []byte {
+	data := make([]byte, common.HashLength+4)
+	copy(data, outpoint.Hash.Bytes())
+	binary.BigEndian.PutUint32(data[common.HashLength:], outpoint.Index)
+
+	return data
+}
+
+// String implements the Stringer interface for Outpoint
+func (outpoint Outpoint) String() string {
+	return fmt.Sprintf("%v:%v", outpoint.Hash.Hex(), outpoint.Index)
+}
+
+// TxInput is an input of a Transaction on a chain with the UTXO ledger, which spends a previous output
+type TxInput struct {
+	// Represents the output spent by the input
+	Previous Outpoint
+}
+
+// TxOutput is an output of a Transaction on a chain with the UTXO ledger.
+// The output can only be spent by a Transaction authorized by the address it is locked to,
+// which is signed by its key or satisfies its lock script.
+type TxOutput struct {
+	// Represents the amount of tokens held by the output
+	Value common.Amount
+	// Represents the address that the output is locked to
+	Address common.Address
+}
+
+// Hash returns the SHA-256 hash of the TxOutput's canonical encoding
+func (output *TxOutput) Hash() common.Hash {
+	return common.Hash256(output.canonical())
+}
+
+// canonical returns the canonical encoding of the TxOutput,
+// which is its big endian value followed by its length prefixed address
+func (output *TxOutput) canonical() []byte {
+	var buffer bytes.Buffer
+	_ = binary.Write(&buffer, binary.BigEndian, uint64(output.Value))
+	writeBytes(&buffer, output.Address.Bytes())
+
+	return buffer.Bytes()
+}
+
+// Coin is an unspent output of a Transaction along with its Outpoint
+type Coin struct {
+	// Represents the reference to the output
+	Outpoint Outpoint
+	// Represents the output
+	Output TxOutput
+}
+
+// NewUTXOTransaction generates a new unsigned Transaction on the given chain
+// from the given sender that spends the given inputs into the given outputs.
+func NewUTXOTransaction(chainID uint64, from common.Address, inputs []TxInput, outputs []TxOutput, fee common.Amount) *Transaction {
+	return &Transaction{ChainID: chainID, Fee: fee, From: from, Inputs: inputs, Outputs: outputs}
+}
+
+// NewTransfer generates a new unsigned Transaction on the given chain with the UTXO ledger that transfers
+// the given value from the given sender to the given receiver and pays the given fee. The inputs are selected
+// from the given Coins of the sender in decreasing order of value until they cover the value and fee,
+// and any excess is returned to the sender in a change output.
+// Returns ErrInsufficientFunds if the Coins cannot cover the value and fee.
+func NewTransfer(chainID uint64, from, to common.Address, value, fee common.Amount, coins []Coin) (*Transaction, error) {
+	cost, err := value.Add(fee)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCostOverflow, err)
+	}
+
+	selection := append([]Coin{}, coins...)
+	sort.SliceStable(selection, func(i, j int) bool { return selection[i].Output.Value > selection[j].Output.Value })
+
+	var total common.Amount
+	inputs := make([]TxInput, 0)
+	for _, coin := range selection {
+		if total >= cost {
+			break
+		}
+
+		if total, err = total.Add(coin.Output.Value); err != nil {
+			return nil, err
+		}
+
+		inputs = append(inputs, TxInput{Previous: coin.Outpoint})
+	}
+
+	if total < cost {
+		return nil, fmt.Errorf("%w: '%v' has %v, needs %v", ErrInsufficientFunds, from, total, cost)
+	}
+
+	outputs := []TxOutput{{Value: value, Address: to}}
+	if change := total - cost; change > 0 {
+		outputs = append(outputs, TxOutput{Value: change, Address: from})
+	}
+
+	return NewUTXOTransaction(chainID, from, inputs, outputs, fee), nil
+}
+
+// IsUTXO returns whether the Transaction spends inputs into outputs on a chain with the UTXO ledger
+func (txn *Transaction) IsUTXO() bool {
+	return len(txn.Inputs) > 0 || len(txn.Outputs) > 0
+}
+
+// OutputValue returns the amount transferred by the Transaction, which is the sum of its outputs
+// for a Transaction on a chain with the UTXO ledger. Returns common.ErrAmountOverflow if the sum overflows.
+func (txn *Transaction) OutputValue() (common.Amount, error) {
+	if !txn.IsUTXO() {
+		return txn.Value, nil
+	}
+
+	var value common.Amount
+	for _, output := range txn.Outputs {
+		var err error
+		if value, err = value.Add(output.Value); err != nil {
+			return 0, err
+		}
+	}
+
+	return value, nil
+}
+
+// Outpoint returns the Outpoint of the output at the given index of the Transaction
+func (txn *Transaction) Outpoint(index uint32) (Outpoint, error) {
+	hash, err := txn.Hash()
+	if err != nil {
+		return Outpoint{}, err
+	}
+
+	return Outpoint{Hash: hash, Index: index}, nil
+}
+
+// Involves returns whether the given address is the sender, the receiver
+// or the address of an output of the Transaction
+func (txn *Transaction) Involves(address common.Address) bool {
+	if txn.From == address || txn.To == address {
+		return true
+	}
+
+	for _, output := range txn.Outputs {
+		if output.Address == address {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeUTXO writes the length prefixed inputs and outputs of the Transaction into the buffer
+func (txn *Transaction) writeUTXO(buffer *bytes.Buffer) {
+	_ = binary.Write(buffer, binary.BigEndian, uint64(len(txn.Inputs)))
+	for _, input := range txn.Inputs {
+		buffer.Write(input.Previous.Bytes())
+	}
+
+	_ = binary.Write(buffer, binary.BigEndian, uint64(len(txn.Outputs)))
+	for _, output := range txn.Outputs {
+		buffer.Write(output.canonical())
+	}
+}
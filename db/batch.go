@@ -0,0 +1,59 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger"
+)
+
+// Batch is a set of key-value pairs that are written to the database atomically
+// along with a set of keys that are deleted from the database
+type Batch struct {
+	keys    [][]byte
+	values  [][]byte
+	deletes [][]byte
+}
+
+// NewBatch returns a new empty Batch
+func NewBatch() *Batch {
+	return new(Batch)
+}
+
+// Set adds a key-value pair to the Batch
+func (batch *Batch) Set(key, value []byte) {
+	batch.keys = append(batch.keys, key)
+	batch.values = append(batch.values, value)
+}
+
+// Delete adds a key to be deleted to the Batch
+func (batch *Batch) Delete(key []byte) {
+	batch.deletes = append(batch.deletes, key)
+}
+
+// Len returns the number of entries and deletions in the Batch
+func (batch *Batch) Len() int {
+	return len(batch.keys) + len(batch.deletes)
+}
+
+// WriteBatch writes all the entries in the Batch to the database and deletes all its deleted keys
+// in a single transaction. Either all entries are written and deleted, or none of them are.
+func (db *Database) WriteBatch(batch *Batch) error {
+	// Define an update transaction the database
+	return db.client.Update(func(txn *badger.Txn) error {
+		for idx, key := range batch.keys {
+			// Attempt to set the key-value pair to the database
+			if err := txn.Set(key, batch.values[idx]); err != nil {
+				return fmt.Errorf("db batch set for key '%x' failed: %w", key, err)
+			}
+		}
+
+		for _, key := range batch.deletes {
+			// Attempt to delete the key from the database
+			if err := txn.Delete(key); err != nil {
+				return fmt.Errorf("db batch delete for key '%x' failed: %w", key, err)
+			}
+		}
+
+		return nil
+	})
+}
@@ -6,14 +6,17 @@ import (
 	"github.com/dgraph-io/badger"
 )
 
+// ErrKeyNotFound is returned (wrapped) by GetEntry when the requested key does not exist
+var ErrKeyNotFound = badger.ErrKeyNotFound
+
 type Database struct {
 	client *badger.DB
 }
 
-// Open opens a Badger client to the database at Dir()
-func Open() (*Database, error) {
+// Open opens a Badger client to the database at the given directory
+func Open(dir string) (*Database, error) {
 	// Setup Badger Options
-	opts := badger.DefaultOptions(Dir())
+	opts := badger.DefaultOptions(dir)
 	opts.Logger = nil
 
 	// Open Badger Client
@@ -26,11 +29,13 @@ func Open() (*Database, error) {
 	return &Database{client}, nil
 }
 
-// Close closes the Badger client to the database at Dir()
-func (db *Database) Close() {
+// Close flushes all pending writes and closes the Badger client to the database
+func (db *Database) Close() error {
 	if err := db.client.Close(); err != nil {
-		panic(fmt.Errorf("db close fail: %w", err))
+		return fmt.Errorf("db close fail: %w", err)
 	}
+
+	return nil
 }
 
 func (db *Database) GetEntry(key []byte) (value []byte, err error) {
@@ -42,12 +47,9 @@ func (db *Database) GetEntry(key []byte) (value []byte, err error) {
 			return fmt.Errorf("db get on key '%x' fail: %w", key, err)
 		}
 
-		// Retrieve the value from the Item
-		if err = item.Value(func(val []byte) error {
-			value = val
-			return nil
-
-		}); err != nil {
+		// Retrieve a copy of the value from the Item.
+		// The value is only valid within the transaction otherwise.
+		if value, err = item.ValueCopy(nil); err != nil {
 			return fmt.Errorf("db value get on key '%x' fail: %w", key, err)
 		}
 
@@ -68,3 +70,23 @@ func (db *Database) SetEntry(key, value []byte) error {
 		return nil
 	})
 }
+
+// IteratePrefix calls fn with every key in the database that begins with the given prefix and its value.
+// Keys are visited in lexicographic order and the key and value are only valid within the call to fn.
+// Iteration stops at the first error returned by fn.
+func (db *Database) IteratePrefix(prefix []byte, fn func(key, value []byte) error) error {
+	// Define a view transaction on the database
+	return db.client.View(func(txn *badger.Txn) error {
+		iterator := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer iterator.Close()
+
+		for iterator.Seek(prefix); iterator.ValidForPrefix(prefix); iterator.Next() {
+			item := iterator.Item()
+			if err := item.Value(func(value []byte) error { return fn(item.Key(), value) }); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
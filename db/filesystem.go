@@ -3,17 +3,18 @@ package db
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 )
 
 const dbFolder = "data"
 
-// Exists returns a boolean indicating if the database directory is already initialized
-func Exists() bool {
+// Exists returns a boolean indicating if the given database directory is already initialized
+func Exists(dir string) bool {
 	// Create path to MANIFEST file in database directory.
 	// This MANIFEST file is good indication of whether the database is initialized
-	manifest := filepath.Join(Dir(), "MANIFEST")
+	manifest := filepath.Join(dir, "MANIFEST")
 
 	// Check if the MANIFEST file exists
 	if _, err := os.Stat(manifest); errors.Is(err, os.ErrNotExist) {
@@ -24,7 +25,7 @@ func Exists() bool {
 	return true
 }
 
-// Dir returns the path to the directory that contains the database contents.
+// Dir returns the path to the default directory that contains the database contents.
 // It is always in the same directory as the running binary.
 func Dir() string {
 	// Get path to executable
@@ -38,3 +39,32 @@ func Dir() string {
 	// Add dbFolder to return directory of database
 	return filepath.Join(execDir, dbFolder)
 }
+
+// Usage returns the disk usage in bytes of the LSM tree and value log files of the database at the given directory
+func Usage(dir string) (lsm, vlog int64, err error) {
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		switch filepath.Ext(path) {
+		case ".sst":
+			lsm += info.Size()
+		case ".vlog":
+			vlog += info.Size()
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		err = fmt.Errorf("database usage detection failure: %w", err)
+	}
+
+	return
+}
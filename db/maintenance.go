@@ -0,0 +1,54 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger"
+)
+
+// GCDiscardRatio is the fraction of a value log file that must be discardable for it to be rewritten by Compact
+const GCDiscardRatio = 0.5
+
+// IterateKeys calls fn with every key in the database and the estimated size of its entry.
+// Keys are visited in lexicographic order and only valid within the call to fn.
+// Iteration stops at the first error returned by fn.
+func (db *Database) IterateKeys(fn func(key []byte, size int64) error) error {
+	// Define a view transaction on the database
+	return db.client.View(func(txn *badger.Txn) error {
+		// Values are not required, so they are not prefetched
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+
+		iterator := txn.NewIterator(opts)
+		defer iterator.Close()
+
+		for iterator.Rewind(); iterator.Valid(); iterator.Next() {
+			item := iterator.Item()
+			if err := fn(item.Key(), item.EstimatedSize()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Compact compacts the LSM tree into a single level and then garbage collects
+// the value log until no more files can be rewritten. Space is reclaimed from
+// overwritten entries, such as previous versions of the chain head and accounts.
+func (db *Database) Compact() error {
+	if err := db.client.Flatten(1); err != nil {
+		return fmt.Errorf("db flatten fail: %w", err)
+	}
+
+	for {
+		if err := db.client.RunValueLogGC(GCDiscardRatio); err != nil {
+			if errors.Is(err, badger.ErrNoRewrite) {
+				return nil
+			}
+
+			return fmt.Errorf("db value log gc fail: %w", err)
+		}
+	}
+}
@@ -3,9 +3,13 @@ module github.com/manishmeganathan/essensio
 go 1.18
 
 require (
+	github.com/BurntSushi/toml v1.2.1
 	github.com/dgraph-io/badger v1.6.2
 	github.com/gorilla/mux v1.8.0
-	github.com/gorilla/rpc v1.2.0
+	github.com/gorilla/websocket v1.5.0
+	golang.org/x/crypto v0.8.0
+	golang.org/x/term v0.7.0
+	golang.org/x/text v0.9.0
 )
 
 require (
@@ -15,6 +19,6 @@ require (
 	github.com/dustin/go-humanize v1.0.0 // indirect
 	github.com/golang/protobuf v1.3.1 // indirect
 	github.com/pkg/errors v0.8.1 // indirect
-	golang.org/x/net v0.0.0-20190620200207-3b0461eec859 // indirect
-	golang.org/x/sys v0.0.0-20220722155257-8c9f86f7a55f // indirect
+	golang.org/x/net v0.9.0 // indirect
+	golang.org/x/sys v0.7.0 // indirect
 )
@@ -0,0 +1,132 @@
+package hdwallet
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+const (
+	// HardenedOffset is the first index of a hardened child key.
+	// Ed25519 keys only support hardened derivation.
+	HardenedOffset uint32 = 1 << 31
+
+	// masterSecret is the HMAC key that derives the master key from a seed
+	masterSecret = "ed25519 seed"
+)
+
+// ExtendedKey is an Ed25519 private key with a chain code from which child keys are derived.
+// Keys are derived as specified by SLIP-0010, the Ed25519 variant of BIP-32.
+type ExtendedKey struct {
+	key       []byte
+	chainCode []byte
+}
+
+// NewMasterKey returns the master ExtendedKey for the given seed
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("seed of %v bytes must be between 16 and 64 bytes", len(seed))
+	}
+
+	return newExtendedKey([]byte(masterSecret), seed), nil
+}
+
+// newExtendedKey returns the ExtendedKey from the HMAC-SHA512 of the data with the given key.
+// The left half of the MAC is the private key and the right half is the chain code.
+func newExtendedKey(key, data []byte) *ExtendedKey {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	return &ExtendedKey{key: sum[:32], chainCode: sum[32:]}
+}
+
+// Child returns the hardened child ExtendedKey at the given index.
+// Returns an error if the index is not hardened.
+func (extended *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	if index < HardenedOffset {
+		return nil, fmt.Errorf("index %v is not hardened, ed25519 only supports hardened derivation", index)
+	}
+
+	data := make([]byte, 1+32+4)
+	copy(data[1:], extended.key)
+	binary.BigEndian.PutUint32(data[33:], index)
+
+	return newExtendedKey(extended.chainCode, data), nil
+}
+
+// Derive returns the ExtendedKey at the given derivation path from this key
+func (extended *ExtendedKey) Derive(path DerivationPath) (*ExtendedKey, error) {
+	key := extended
+	for _, index := range path {
+		var err error
+		if key, err = key.Child(index); err != nil {
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+// PrivateKey returns the Ed25519 private key of the ExtendedKey
+func (extended *ExtendedKey) PrivateKey() ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(extended.key)
+}
+
+// Address returns the Address of the ExtendedKey
+func (extended *ExtendedKey) Address() common.Address {
+	return common.PublicKeyToAddress(extended.PrivateKey().Public().(ed25519.PublicKey))
+}
+
+// DerivationPath is a sequence of child indexes from a master key
+type DerivationPath []uint32
+
+// ParseDerivationPath parses a derivation path such as "m/44'/0'/0'".
+// Indexes followed by ' or h are hardened.
+func ParseDerivationPath(path string) (DerivationPath, error) {
+	components := strings.Split(strings.TrimSpace(path), "/")
+	if components[0] != "m" {
+		return nil, fmt.Errorf("derivation path '%v' must begin with 'm'", path)
+	}
+
+	parsed := make(DerivationPath, 0, len(components)-1)
+	for _, component := range components[1:] {
+		hardened := strings.HasSuffix(component, "'") || strings.HasSuffix(component, "h")
+		component = strings.TrimRight(component, "'h")
+
+		index, err := strconv.ParseUint(component, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("derivation path '%v' has invalid index '%v'", path, component)
+		}
+
+		if hardened {
+			index += uint64(HardenedOffset)
+		}
+
+		parsed = append(parsed, uint32(index))
+	}
+
+	return parsed, nil
+}
+
+// String implements the Stringer interface for DerivationPath
+func (path DerivationPath) String() string {
+	var s strings.Builder
+	s.WriteString("m")
+
+	for _, index := range path {
+		if index >= HardenedOffset {
+			s.WriteString(fmt.Sprintf("/%v'", index-HardenedOffset))
+		} else {
+			s.WriteString(fmt.Sprintf("/%v", index))
+		}
+	}
+
+	return s.String()
+}
@@ -0,0 +1,116 @@
+package hdwallet
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+// slip10Vectors are the published SLIP-0010 test vector 1 for ed25519, derived from the seed 000102030405060708090a0b0c0d0e0f.
+// Public keys are prefixed with a zero byte as in the specification.
+var slip10Vectors = []struct {
+	path       string
+	chainCode  string
+	privateKey string
+	publicKey  string
+}{
+	{
+		"m",
+		"0x90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb",
+		"0x2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+		"0x00a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed",
+	},
+	{
+		"m/0'",
+		"0x8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69",
+		"0x68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+		"0x008c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c",
+	},
+	{
+		"m/0'/1'",
+		"0xa320425f77d1b5c2505a6b1b27382b37368ee640e3557c315416801243552f14",
+		"0xb1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+		"0x001932a5270f335bed617d5b935c80aedb1a35bd9fc1e31acafd5372c30f5c1187",
+	},
+	{
+		"m/0'/1'/2'",
+		"0x2e69929e00b5ab250f49c3fb1c12f252de4fed2c1db88387094a0f8c4c9ccd6c",
+		"0x92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9",
+		"0x00ae98736566d30ed0e9d2f4486a64bc95740d89c7db33f52121f8ea8f76ff0fc1",
+	},
+	{
+		"m/0'/1'/2'/2'",
+		"0x8f6d87f93d750e0efccda017d662a1b31a266e4a6f5993b15f5c1f07f74dd5cc",
+		"0x30d1dc7e5fc04c31219ab25a27ae00b50f6fd66622f6e9c913253d6511d1e662",
+		"0x008abae2d66361c879b900d204ad2cc4984fa2aa344dd7ddc46007329ac76c429c",
+	},
+	{
+		"m/0'/1'/2'/2'/1000000000'",
+		"0x68789923a0cac2cd5a29172a475fe9e0fb14cd6adb5ad98a3fa70333e7afa230",
+		"0x8f94d394a8e8fd6b1bc2f3f49f5c47e385281d5c17e65324b0f62483e37e8793",
+		"0x003c24da049451555d51a7014a37337aa4e12d41e485abccfa46b47dfb2af54b7a",
+	},
+}
+
+func TestDerivationVectors(t *testing.T) {
+	seed, _ := common.HexDecode("0x000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		t.Fatalf("NewMasterKey: %v", err)
+	}
+
+	for _, vector := range slip10Vectors {
+		path, err := ParseDerivationPath(vector.path)
+		if err != nil {
+			t.Fatalf("ParseDerivationPath('%v'): %v", vector.path, err)
+		}
+
+		if path.String() != vector.path {
+			t.Errorf("DerivationPath.String: expected '%v', got '%v'", vector.path, path.String())
+		}
+
+		key, err := master.Derive(path)
+		if err != nil {
+			t.Fatalf("Derive('%v'): %v", vector.path, err)
+		}
+
+		if chainCode := common.HexEncode(key.chainCode); chainCode != vector.chainCode {
+			t.Errorf("%v: expected chain code %v, got %v", vector.path, vector.chainCode, chainCode)
+		}
+
+		if privateKey := common.HexEncode(key.PrivateKey().Seed()); privateKey != vector.privateKey {
+			t.Errorf("%v: expected private key %v, got %v", vector.path, vector.privateKey, privateKey)
+		}
+
+		publicKey := append([]byte{0}, key.PrivateKey().Public().(ed25519.PublicKey)...)
+		if encoded := common.HexEncode(publicKey); encoded != vector.publicKey {
+			t.Errorf("%v: expected public key %v, got %v", vector.path, vector.publicKey, encoded)
+		}
+	}
+}
+
+func TestDerivationRejectsNonHardened(t *testing.T) {
+	seed, _ := common.HexDecode("0x000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		t.Fatalf("NewMasterKey: %v", err)
+	}
+
+	path, err := ParseDerivationPath("m/0'/1")
+	if err != nil {
+		t.Fatalf("ParseDerivationPath: %v", err)
+	}
+
+	if _, err := master.Derive(path); err == nil {
+		t.Fatalf("Derive: expected an error for a non-hardened index")
+	}
+}
+
+func TestInvalidDerivationPath(t *testing.T) {
+	for _, path := range []string{"", "0'/1'", "m/x'", "m/2147483648'", "m//1'"} {
+		if _, err := ParseDerivationPath(path); err == nil {
+			t.Errorf("ParseDerivationPath('%v'): expected an error", path)
+		}
+	}
+}
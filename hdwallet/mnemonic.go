@@ -0,0 +1,159 @@
+package hdwallet
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// SeedLength is the length of the seed derived from a mnemonic in bytes
+	SeedLength = 64
+	// seedIterations is the number of PBKDF2 iterations to derive a seed
+	seedIterations = 2048
+	// bitsPerWord is the number of bits encoded by each word of a mnemonic
+	bitsPerWord = 11
+)
+
+// ErrInvalidMnemonic is returned when a mnemonic has unknown words, the wrong length or a bad checksum
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+// english is the BIP-39 English wordlist
+//
+//go:embed english.txt
+var english string
+
+var (
+	// wordlist is the list of mnemonic words, indexed by their 11 bit value
+	wordlist = strings.Fields(english)
+	// wordIndex maps each mnemonic word to its 11 bit value
+	wordIndex = func() map[string]int {
+		index := make(map[string]int, len(wordlist))
+		for idx, word := range wordlist {
+			index[word] = idx
+		}
+
+		return index
+	}()
+)
+
+// NewEntropy returns the given number of bits of random entropy for a mnemonic.
+// The number of bits must be a multiple of 32 between 128 and 256.
+func NewEntropy(bits int) ([]byte, error) {
+	if err := checkEntropyBits(bits); err != nil {
+		return nil, err
+	}
+
+	entropy := make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, fmt.Errorf("entropy generation failed: %w", err)
+	}
+
+	return entropy, nil
+}
+
+// checkEntropyBits returns an error if the number of bits of entropy is not valid for a mnemonic
+func checkEntropyBits(bits int) error {
+	if bits%32 != 0 || bits < 128 || bits > 256 {
+		return fmt.Errorf("entropy of %v bits must be a multiple of 32 between 128 and 256", bits)
+	}
+
+	return nil
+}
+
+// NewMnemonic returns the mnemonic for the given entropy. The entropy is followed by a checksum
+// of its first bits/32 SHA-256 hash bits, and every 11 bits are encoded as a word of the wordlist.
+func NewMnemonic(entropy []byte) (string, error) {
+	bits := len(entropy) * 8
+	if err := checkEntropyBits(bits); err != nil {
+		return "", err
+	}
+
+	checksumBits := bits / 32
+	checksum := sha256.Sum256(entropy)
+
+	// Append the checksum bits to the entropy
+	data := new(big.Int).SetBytes(entropy)
+	data.Lsh(data, uint(checksumBits))
+	data.Or(data, big.NewInt(int64(checksum[0]>>(8-checksumBits))))
+
+	// Split the data into 11 bit words, from the last word to the first
+	count := (bits + checksumBits) / bitsPerWord
+	words := make([]string, count)
+	mask := big.NewInt(1<<bitsPerWord - 1)
+
+	for idx := count - 1; idx >= 0; idx-- {
+		words[idx] = wordlist[new(big.Int).And(data, mask).Int64()]
+		data.Rsh(data, bitsPerWord)
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy returns the entropy encoded by the given mnemonic.
+// Returns ErrInvalidMnemonic if the mnemonic is not valid.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+
+	// The words encode the entropy and a checksum of 1 bit for every 32 bits of entropy
+	totalBits := len(words) * bitsPerWord
+	checksumBits := totalBits / 33
+	bits := totalBits - checksumBits
+
+	if len(words)%3 != 0 || checkEntropyBits(bits) != nil {
+		return nil, fmt.Errorf("%w: %v words", ErrInvalidMnemonic, len(words))
+	}
+
+	data := new(big.Int)
+	for _, word := range words {
+		value, ok := wordIndex[word]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown word '%v'", ErrInvalidMnemonic, word)
+		}
+
+		data.Lsh(data, bitsPerWord)
+		data.Or(data, big.NewInt(int64(value)))
+	}
+
+	// Separate the checksum from the entropy
+	checksum := new(big.Int).And(data, big.NewInt(1<<checksumBits-1)).Int64()
+	data.Rsh(data, uint(checksumBits))
+
+	entropy := data.FillBytes(make([]byte, bits/8))
+
+	expected := sha256.Sum256(entropy)
+	if int64(expected[0]>>(8-checksumBits)) != checksum {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidMnemonic)
+	}
+
+	return entropy, nil
+}
+
+// ValidateMnemonic returns an error if the given mnemonic is not valid
+func ValidateMnemonic(mnemonic string) error {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err
+}
+
+// NewSeed returns the seed for the given mnemonic and passphrase. The seed is derived with
+// PBKDF2-HMAC-SHA512 from the mnemonic, salted with "mnemonic" and the passphrase.
+// Returns ErrInvalidMnemonic if the mnemonic is not valid.
+func NewSeed(mnemonic, passphrase string) ([]byte, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+
+	// The mnemonic is normalized so that it is independent of its whitespace and unicode form
+	mnemonic = norm.NFKD.String(strings.Join(strings.Fields(mnemonic), " "))
+	salt := norm.NFKD.String("mnemonic" + passphrase)
+
+	return pbkdf2.Key([]byte(mnemonic), []byte(salt), seedIterations, SeedLength, sha512.New), nil
+}
@@ -0,0 +1,96 @@
+package hdwallet
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+// bip39Vectors are the published BIP-39 test vectors for the english wordlist, whose seeds use the passphrase "TREZOR"
+var bip39Vectors = []struct {
+	entropy  string
+	mnemonic string
+	seed     string
+}{
+	{
+		"0x00000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"0xc55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	},
+	{
+		"0x7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"0x2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+	},
+	{
+		"0x80808080808080808080808080808080",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+		"0xd71de856f81a8acc65e6fc851a38d4d7ec216fd0796d0a6827a3ad6ed5511a30fa280f12eb2e47ed2ac03b5c462a0358d18d69fe4f985ec81778c1b370b652a8",
+	},
+	{
+		"0xffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		"0xac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+	},
+	{
+		"0x0000000000000000000000000000000000000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+		"0xbda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8",
+	},
+}
+
+func TestMnemonicVectors(t *testing.T) {
+	for _, vector := range bip39Vectors {
+		entropy, err := common.HexDecode(vector.entropy)
+		if err != nil {
+			t.Fatalf("invalid entropy %v: %v", vector.entropy, err)
+		}
+
+		mnemonic, err := NewMnemonic(entropy)
+		if err != nil {
+			t.Fatalf("NewMnemonic(%v): %v", vector.entropy, err)
+		}
+
+		if mnemonic != vector.mnemonic {
+			t.Errorf("NewMnemonic(%v): expected '%v', got '%v'", vector.entropy, vector.mnemonic, mnemonic)
+		}
+
+		decoded, err := MnemonicToEntropy(vector.mnemonic)
+		if err != nil {
+			t.Fatalf("MnemonicToEntropy('%v'): %v", vector.mnemonic, err)
+		}
+
+		if !bytes.Equal(decoded, entropy) {
+			t.Errorf("MnemonicToEntropy('%v'): expected %v, got %v", vector.mnemonic, vector.entropy, common.HexEncode(decoded))
+		}
+
+		seed, err := NewSeed(vector.mnemonic, "TREZOR")
+		if err != nil {
+			t.Fatalf("NewSeed('%v'): %v", vector.mnemonic, err)
+		}
+
+		if encoded := common.HexEncode(seed); encoded != vector.seed {
+			t.Errorf("NewSeed('%v'): expected %v, got %v", vector.mnemonic, vector.seed, encoded)
+		}
+	}
+}
+
+func TestInvalidMnemonic(t *testing.T) {
+	tests := []struct {
+		name     string
+		mnemonic string
+	}{
+		{"bad checksum", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon"},
+		{"unknown word", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon essensio"},
+		{"bad length", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"},
+		{"empty", ""},
+	}
+
+	for _, test := range tests {
+		if err := ValidateMnemonic(test.mnemonic); !errors.Is(err, ErrInvalidMnemonic) {
+			t.Errorf("%v: expected ErrInvalidMnemonic, got %v", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package hdwallet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+const (
+	// Purpose is the BIP-44 purpose of the derivation paths of a Wallet
+	Purpose uint32 = 44
+	// CoinType is the BIP-44 coin type of Essensio addresses (the bytes of "ESS")
+	CoinType uint32 = 0x455353
+
+	// DefaultGapLimit is the number of consecutive unused addresses after which a scan stops
+	DefaultGapLimit = 20
+)
+
+// Wallet is a hierarchical deterministic wallet that derives the keys of an account from
+// a single seed. The key at an index is derived at the path m/44'/CoinType'/account'/0'/index'.
+type Wallet struct {
+	// Represents the master key of the wallet
+	master *ExtendedKey
+	// Represents the account of the wallet's derivation paths
+	account uint32
+}
+
+// New returns a Wallet for the given account from a mnemonic and an optional passphrase.
+// Returns ErrInvalidMnemonic if the mnemonic is not valid.
+func New(mnemonic, passphrase string, account uint32) (*Wallet, error) {
+	seed, err := NewSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFromSeed(seed, account)
+}
+
+// NewFromSeed returns a Wallet for the given account from a seed
+func NewFromSeed(seed []byte, account uint32) (*Wallet, error) {
+	if account >= HardenedOffset {
+		return nil, fmt.Errorf("account %v is too large", account)
+	}
+
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Wallet{master: master, account: account}, nil
+}
+
+// Path returns the DerivationPath of the key at the given index
+func (wallet *Wallet) Path(index uint32) DerivationPath {
+	return DerivationPath{
+		Purpose + HardenedOffset,
+		CoinType + HardenedOffset,
+		wallet.account + HardenedOffset,
+		HardenedOffset,
+		index + HardenedOffset,
+	}
+}
+
+// Key returns the ExtendedKey at the given index
+func (wallet *Wallet) Key(index uint32) (*ExtendedKey, error) {
+	if index >= HardenedOffset {
+		return nil, fmt.Errorf("index %v is too large", index)
+	}
+
+	return wallet.master.Derive(wallet.Path(index))
+}
+
+// Address returns the Address of the key at the given index
+func (wallet *Wallet) Address(index uint32) (common.Address, error) {
+	key, err := wallet.Key(index)
+	if err != nil {
+		return "", err
+	}
+
+	return key.Address(), nil
+}
+
+// SignTransaction signs the Transaction with the key at the given index.
+// Returns an error if the key does not belong to the sender of the Transaction.
+func (wallet *Wallet) SignTransaction(txn *core.Transaction, index uint32) error {
+	key, err := wallet.Key(index)
+	if err != nil {
+		return err
+	}
+
+	return txn.Sign(key.PrivateKey())
+}
+
+// AccountState returns the balance and nonce of an address on the chain
+type AccountState func(ctx context.Context, address common.Address) (balance common.Amount, nonce uint64, err error)
+
+// ScannedAccount is an address of a Wallet that has been used on the chain
+type ScannedAccount struct {
+	// Represents the index of the address in the wallet
+	Index uint32 `json:"index"`
+	// Represents the derivation path of the address
+	Path string `json:"path"`
+	// Represents the address
+	Address common.Address `json:"address"`
+	// Represents the balance of the address
+	Balance common.Amount `json:"balance"`
+	// Represents the next nonce of the address
+	Nonce uint64 `json:"nonce"`
+}
+
+// ScanResult is the result of scanning the chain for the used addresses of a Wallet
+type ScanResult struct {
+	// Represents the used addresses of the wallet
+	Accounts []ScannedAccount `json:"accounts"`
+	// Represents the aggregate balance of the used addresses
+	Balance common.Amount `json:"balance"`
+	// Represents the index of the first unused address after the last used address
+	NextIndex uint32 `json:"next_index"`
+}
+
+// Scan derives the addresses of the Wallet in order and looks up their state on the chain.
+// An address is used if it has a balance or has sent a transaction. The scan stops after gap
+// consecutive unused addresses, and returns the used addresses and their aggregate balance.
+func (wallet *Wallet) Scan(ctx context.Context, state AccountState, gap uint32) (*ScanResult, error) {
+	if gap == 0 {
+		return nil, fmt.Errorf("gap limit must be positive")
+	}
+
+	result := &ScanResult{Accounts: make([]ScannedAccount, 0)}
+
+	for index, unused := uint32(0), uint32(0); unused < gap; index++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		address, err := wallet.Address(index)
+		if err != nil {
+			return nil, err
+		}
+
+		balance, nonce, err := state(ctx, address)
+		if err != nil {
+			return nil, fmt.Errorf("state lookup of '%v' failed: %w", address, err)
+		}
+
+		if balance == 0 && nonce == 0 {
+			unused++
+			continue
+		}
+
+		unused = 0
+		result.NextIndex = index + 1
+		if result.Balance, err = result.Balance.Add(balance); err != nil {
+			return nil, fmt.Errorf("aggregate balance: %w", err)
+		}
+
+		result.Accounts = append(result.Accounts, ScannedAccount{
+			Index:   index,
+			Path:    wallet.Path(index).String(),
+			Address: address,
+			Balance: balance,
+			Nonce:   nonce,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,221 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/state"
+)
+
+type AccountArgs struct {
+	Address string `json:"address"`
+
+	// Block after which the state is queried, by hash or by height.
+	// The current state is queried if neither is given.
+	BlockHash   string  `json:"block_hash,omitempty"`
+	BlockHeight *uint64 `json:"block_height,omitempty"`
+}
+
+type GetBalanceResult struct {
+	Address string        `json:"address"`
+	Balance common.Amount `json:"balance"`
+
+	// Block after which the state was queried, omitted for the current state
+	BlockHash   string  `json:"block_hash,omitempty"`
+	BlockHeight *uint64 `json:"block_height,omitempty"`
+}
+
+type GetNonceResult struct {
+	Address string `json:"address"`
+	Nonce   uint64 `json:"nonce"`
+
+	// Block after which the state was queried, omitted for the current state
+	BlockHash   string  `json:"block_hash,omitempty"`
+	BlockHeight *uint64 `json:"block_height,omitempty"`
+}
+
+type GetAccountProofResult struct {
+	Address string        `json:"address"`
+	Balance common.Amount `json:"balance"`
+	Nonce   uint64        `json:"nonce"`
+
+	BlockHash   string `json:"block_hash"`
+	BlockHeight uint64 `json:"block_height"`
+	StateRoot   string `json:"state_root"`
+
+	Siblings []string  `json:"siblings"`
+	Leaf     *TrieLeaf `json:"leaf,omitempty"`
+}
+
+type TrieLeaf struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Account returns the state.Account of the GetAccountProofResult
+func (result GetAccountProofResult) Account() *state.Account {
+	return &state.Account{Balance: result.Balance, Nonce: result.Nonce}
+}
+
+// AccountProof converts the proof of the GetAccountProofResult into a state.AccountProof.
+// Returns an error if any of its hashes are invalid.
+func (result GetAccountProofResult) AccountProof() (*state.AccountProof, error) {
+	proof := &state.AccountProof{Siblings: make([]common.Hash, 0, len(result.Siblings))}
+	for _, sibling := range result.Siblings {
+		hash, err := common.HexToHash(sibling)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proof hash '%v': %w", sibling, err)
+		}
+
+		proof.Siblings = append(proof.Siblings, hash)
+	}
+
+	if result.Leaf != nil {
+		key, err := common.HexToHash(result.Leaf.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid leaf key '%v': %w", result.Leaf.Key, err)
+		}
+
+		value, err := common.HexToHash(result.Leaf.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid leaf value '%v': %w", result.Leaf.Value, err)
+		}
+
+		proof.Leaf = &state.TrieLeaf{Key: key, Value: value}
+	}
+
+	return proof, nil
+}
+
+// stateBlock returns the hash of the Block after which the state is queried by args,
+// and whether a Block was given. The chain head is returned if no Block was given.
+func (api *API) stateBlock(args *AccountArgs) (common.Hash, bool, error) {
+	switch {
+	case args.BlockHash != "" && args.BlockHeight != nil:
+		return common.NullHash(), false, invalidParams("only one of block_hash and block_height may be given")
+
+	case args.BlockHash != "":
+		hash, err := common.HexToHash(args.BlockHash)
+		if err != nil {
+			return common.NullHash(), false, invalidParams("invalid block hash '%v': %v", args.BlockHash, err)
+		}
+
+		return hash, true, nil
+
+	case args.BlockHeight != nil:
+		hash, err := api.chain.GetHashByHeight(int64(*args.BlockHeight))
+		if err != nil {
+			return common.NullHash(), false, lookupError(err)
+		}
+
+		return hash, true, nil
+
+	default:
+		return api.chain.Head(), false, nil
+	}
+}
+
+// getAccount returns the state of the Account for the address in args. If args
+// give a Block, the state after it is returned along with the Header of the Block.
+func (api *API) getAccount(args *AccountArgs) (*state.Account, *core.Header, error) {
+	if args.Address == "" {
+		return nil, nil, invalidParams("missing address")
+	}
+
+	block, pinned, err := api.stateBlock(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !pinned {
+		account, err := api.chain.GetAccount(common.Address(args.Address))
+		if err != nil {
+			return nil, nil, newError(ErrCodeInternal, "failed to retrieve account '%v': %v", args.Address, err)
+		}
+
+		return account, nil, nil
+	}
+
+	account, header, err := api.chain.GetAccountAt(common.Address(args.Address), block)
+	if err != nil {
+		return nil, nil, lookupError(err)
+	}
+
+	return account, header, nil
+}
+
+func (api *API) GetBalance(r *http.Request, args *AccountArgs, result *GetBalanceResult) error {
+	log.Println("'GetBalance' Called")
+
+	account, header, err := api.getAccount(args)
+	if err != nil {
+		return err
+	}
+
+	*result = GetBalanceResult{Address: args.Address, Balance: account.Balance}
+	if header != nil {
+		height := uint64(header.BlockHeight)
+		result.BlockHash, result.BlockHeight = header.BlockHash.Hex(), &height
+	}
+
+	return nil
+}
+
+func (api *API) GetNonce(r *http.Request, args *AccountArgs, result *GetNonceResult) error {
+	log.Println("'GetNonce' Called")
+
+	account, header, err := api.getAccount(args)
+	if err != nil {
+		return err
+	}
+
+	*result = GetNonceResult{Address: args.Address, Nonce: account.Nonce}
+	if header != nil {
+		height := uint64(header.BlockHeight)
+		result.BlockHash, result.BlockHeight = header.BlockHash.Hex(), &height
+	}
+
+	return nil
+}
+
+func (api *API) GetAccountProof(r *http.Request, args *AccountArgs, result *GetAccountProofResult) error {
+	log.Println("'GetAccountProof' Called")
+
+	if args.Address == "" {
+		return invalidParams("missing address")
+	}
+
+	block, _, err := api.stateBlock(args)
+	if err != nil {
+		return err
+	}
+
+	account, proof, header, err := api.chain.ProveAccount(common.Address(args.Address), block)
+	if err != nil {
+		return lookupError(err)
+	}
+
+	siblings := make([]string, 0, len(proof.Siblings))
+	for _, sibling := range proof.Siblings {
+		siblings = append(siblings, sibling.Hex())
+	}
+
+	*result = GetAccountProofResult{
+		Address:     args.Address,
+		Balance:     account.Balance,
+		Nonce:       account.Nonce,
+		BlockHash:   header.BlockHash.Hex(),
+		BlockHeight: uint64(header.BlockHeight),
+		StateRoot:   header.StateRoot.Hex(),
+		Siblings:    siblings,
+	}
+
+	if proof.Leaf != nil {
+		result.Leaf = &TrieLeaf{Key: proof.Leaf.Key.Hex(), Value: proof.Leaf.Value.Hex()}
+	}
+
+	return nil
+}
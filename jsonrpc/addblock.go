@@ -1,12 +1,14 @@
 package jsonrpc
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"log"
 	"net/http"
 
 	"github.com/manishmeganathan/essensio/common"
 	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/chainmgr"
 )
 
 type AddBlockArgs struct {
@@ -14,9 +16,28 @@ type AddBlockArgs struct {
 }
 
 type TransactionInput struct {
-	To    string `json:"to"`
-	From  string `json:"from"`
-	Value uint64 `json:"value"`
+	To    string        `json:"to"`
+	From  string        `json:"from"`
+	Value common.Amount `json:"value"`
+	Fee   common.Amount `json:"fee"`
+
+	// Inputs and Outputs are set instead of To and Value for transactions on chains with the UTXO ledger.
+	// Unsigned transactions without them spend the unspent outputs of the sender on such chains.
+	Inputs  []TxInput  `json:"inputs,omitempty"`
+	Outputs []TxOutput `json:"outputs,omitempty"`
+
+	// ChainID, Nonce, PublicKey and Signature are set for transactions signed by the client.
+	// Transactions with inputs and outputs are signed without a nonce.
+	// Unsigned transactions are signed with the unlocked key of the sender in the node's keystore.
+	ChainID   *uint64 `json:"chain_id,omitempty"`
+	Nonce     *uint64 `json:"nonce,omitempty"`
+	PublicKey string  `json:"public_key,omitempty"`
+	Signature string  `json:"signature,omitempty"`
+
+	// Script and Witness are set instead of PublicKey and Signature for senders with a lock script,
+	// which must also provide the chain ID, nonce, inputs and outputs that the witness signs.
+	Script  string `json:"script,omitempty"`
+	Witness string `json:"witness,omitempty"`
 }
 
 type AddBlockResult struct {
@@ -28,22 +49,192 @@ func (api *API) AddBlock(r *http.Request, args *AddBlockArgs, result *AddBlockRe
 	log.Println("'AddBlock' Called")
 
 	if len(args.Transactions) == 0 {
-		return fmt.Errorf("no transactions receieved")
+		return invalidParams("no transactions receieved")
 	}
 
+	params := api.chain.Params()
+	chainID := params.ChainID
+
+	// Track the next nonce of each sender in the block
+	nonces := make(map[common.Address]uint64)
+	// Track the outputs spent in the block, which cannot be selected for a later transfer
+	spent := make(map[core.Outpoint]bool)
+
 	transactions := make(core.Transactions, 0, len(args.Transactions))
-	for _, txn := range args.Transactions {
-		newtxn := core.NewTransaction(common.Address(txn.From), common.Address(txn.To), 0, txn.Value)
+	for idx, txn := range args.Transactions {
+		from := common.Address(txn.From)
+		if from == common.NullAddress() {
+			return invalidParams("transaction %v: missing sender address", idx)
+		}
+
+		newtxn, err := api.newTransaction(idx, chainID, params.IsUTXO(), txn, spent)
+		if err != nil {
+			return err
+		}
+
+		if err := setScript(idx, newtxn, txn); err != nil {
+			return err
+		}
+
+		// Check the transaction before the sender's state is retrieved or it is signed.
+		// Its nonce is set once it is known to be sane.
+		if err := newtxn.CheckSanity(); err != nil {
+			return invalidParams("transaction %v: %v", idx, err)
+		}
+
+		if err := params.CheckLedger(newtxn); err != nil {
+			return newError(ErrCodeRejected, "transaction %v: %v", idx, err)
+		}
+
+		utxo := newtxn.IsUTXO()
+		for _, input := range newtxn.Inputs {
+			spent[input.Previous] = true
+		}
+
+		// Fetch the sender's account nonce if it has not been seen in the block
+		if _, ok := nonces[from]; !ok && !utxo {
+			account, err := api.chain.GetAccount(from)
+			if err != nil {
+				return newError(ErrCodeInternal, "failed to retrieve account '%v': %v", from, err)
+			}
+
+			nonces[from] = account.Nonce
+		}
+
+		// Transactions for other chains are rejected before they are signed or verified
+		if txn.ChainID != nil && *txn.ChainID != chainID {
+			return newError(ErrCodeRejected, "transaction %v: %v: expected %v, got %v", idx, core.ErrWrongChain, chainID, *txn.ChainID)
+		}
+
+		if newtxn.IsScripted() {
+			// The lock script of the sender is satisfied by the witness from the client
+			if txn.ChainID == nil || (txn.Nonce == nil && !utxo) {
+				return invalidParams("transaction %v: transaction with a lock script requires a chain id and nonce", idx)
+			}
+
+			if !utxo {
+				newtxn.Nonce = *txn.Nonce
+				nonces[from] = *txn.Nonce + 1
+			}
+
+		} else if txn.Signature != "" {
+			// The transaction has been signed by the client, which must also provide the signed chain ID and nonce
+			if txn.ChainID == nil || (txn.Nonce == nil && !utxo) {
+				return invalidParams("transaction %v: signed transaction requires a chain id and nonce", idx)
+			}
+
+			publicKey, err := common.HexDecode(txn.PublicKey)
+			if err != nil {
+				return invalidParams("transaction %v: invalid public key: %v", idx, err)
+			}
+
+			signature, err := common.HexDecode(txn.Signature)
+			if err != nil {
+				return invalidParams("transaction %v: invalid signature: %v", idx, err)
+			}
+
+			newtxn.PublicKey, newtxn.Signature = publicKey, signature
+			if !utxo {
+				newtxn.Nonce = *txn.Nonce
+				nonces[from] = *txn.Nonce + 1
+			}
+
+		} else {
+			// The transaction is signed with the sender's key in the node's keystore
+			if api.keys == nil {
+				return newError(ErrCodeRejected, "transaction %v: unsigned transaction and the node has no keystore", idx)
+			}
+
+			if !utxo {
+				newtxn.Nonce = nonces[from]
+				nonces[from]++
+			}
+
+			if err := api.keys.SignTransaction(newtxn); err != nil {
+				return newError(ErrCodeRejected, "transaction %v: %v", idx, err)
+			}
+		}
+
 		transactions = append(transactions, newtxn)
 	}
 
-	if err := api.chain.AddBlock(transactions); err != nil {
-		return fmt.Errorf("failed to add block: %w", err)
+	block, err := api.chain.AddBlock(r.Context(), transactions)
+	if err != nil {
+		// Mining is abandoned when the request is cancelled or the node shuts down,
+		// and a node without a miner address cannot mine
+		if errors.Is(err, context.Canceled) || errors.Is(err, chainmgr.ErrChainStopped) || errors.Is(err, chainmgr.ErrNoMiner) {
+			return newError(ErrCodeInternal, "failed to add block: %v", err)
+		}
+
+		return newError(ErrCodeRejected, "failed to add block: %v", err)
 	}
 
 	*result = AddBlockResult{
-		BlockHeight: uint64(api.chain.Height - 1),
-		BlockHash:   api.chain.Head.Hex(),
+		BlockHeight: uint64(block.BlockHeight),
+		BlockHash:   block.BlockHash.Hex(),
+	}
+
+	return nil
+}
+
+// newTransaction returns the unsigned core.Transaction of the TransactionInput at the given index on the chain
+// with the given ID. An unsigned transfer without inputs or outputs on a chain with the UTXO ledger spends the unspent
+// outputs of the sender that are not in the given set of outputs spent by earlier transactions in the block.
+func (api *API) newTransaction(idx int, chainID uint64, utxo bool, txn TransactionInput, spent map[core.Outpoint]bool) (*core.Transaction, *Error) {
+	from, to := common.Address(txn.From), common.Address(txn.To)
+
+	switch {
+	case len(txn.Inputs) > 0 || len(txn.Outputs) > 0:
+		inputs, err := coreInputs(txn.Inputs)
+		if err != nil {
+			return nil, invalidParams("transaction %v: %v", idx, err)
+		}
+
+		return core.NewUTXOTransaction(chainID, from, inputs, coreOutputs(txn.Outputs), txn.Fee), nil
+
+	case utxo && txn.Signature == "" && txn.Script == "":
+		if err := to.Validate(); err != nil {
+			return nil, invalidParams("transaction %v: receiver: %v", idx, err)
+		}
+
+		coins, err := api.chain.GetCoins(from)
+		if err != nil {
+			return nil, newError(ErrCodeInternal, "failed to retrieve unspent outputs of '%v': %v", from, err)
+		}
+
+		unspent := make([]core.Coin, 0, len(coins))
+		for _, coin := range coins {
+			if !spent[coin.Outpoint] {
+				unspent = append(unspent, coin)
+			}
+		}
+
+		transfer, err := core.NewTransfer(chainID, from, to, txn.Value, txn.Fee, unspent)
+		if err != nil {
+			return nil, newError(ErrCodeRejected, "transaction %v: %v", idx, err)
+		}
+
+		return transfer, nil
+
+	default:
+		return core.NewTransaction(chainID, from, to, 0, txn.Value, txn.Fee), nil
+	}
+}
+
+// setScript sets the lock script and witness of the TransactionInput at the given index on its core.Transaction.
+// Returns an Error if either is not valid hex.
+func setScript(idx int, newtxn *core.Transaction, txn TransactionInput) *Error {
+	var err error
+	if txn.Script != "" {
+		if newtxn.Script, err = common.HexDecode(txn.Script); err != nil {
+			return invalidParams("transaction %v: invalid lock script: %v", idx, err)
+		}
+	}
+
+	if txn.Witness != "" {
+		if newtxn.Witness, err = common.HexDecode(txn.Witness); err != nil {
+			return invalidParams("transaction %v: invalid witness: %v", idx, err)
+		}
 	}
 
 	return nil
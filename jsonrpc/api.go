@@ -1,24 +1,22 @@
 package jsonrpc
 
 import (
-	"log"
-
 	"github.com/manishmeganathan/essensio/core/chainmgr"
+	"github.com/manishmeganathan/essensio/keystore"
 )
 
 type API struct {
 	chain *chainmgr.ChainManager
+	// Represents the keystore used to sign transactions, which may be nil
+	keys *keystore.Keystore
 }
 
-func NewAPI() *API {
-	chain, err := chainmgr.NewChainManager()
-	if err != nil {
-		log.Fatalln("Failed to Start Blockchain:", err)
-	}
-
-	return &API{chain}
+// NewAPI returns a new API for the given chain. Unsigned transactions are signed with the unlocked
+// keys of the given keystore. If the keystore is nil, only signed transactions are accepted.
+func NewAPI(chain *chainmgr.ChainManager, keys *keystore.Keystore) *API {
+	return &API{chain, keys}
 }
 
-func (api *API) Stop() {
-	api.chain.Stop()
+func (api *API) Stop() error {
+	return api.chain.Stop()
 }
@@ -0,0 +1,59 @@
+package jsonrpc
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+type GetChainInfoArgs struct{}
+
+type GetChainInfoResult struct {
+	ChainID     uint64 `json:"chain_id"`
+	ChainHead   string `json:"chain_head"`
+	ChainHeight uint64 `json:"chain_height"`
+	GenesisHash string `json:"genesis_hash"`
+	Difficulty  uint8  `json:"difficulty"`
+	TotalWork   string `json:"total_work"`
+	Ledger      string `json:"ledger"`
+
+	// Height of the lowest block whose transactions are kept by the node
+	PrunedHeight uint64 `json:"pruned_height"`
+}
+
+func (api *API) GetChainInfo(r *http.Request, args *GetChainInfoArgs, result *GetChainInfoResult) error {
+	log.Println("'GetChainInfo' Called")
+
+	genesis, err := api.chain.GetHeaderByHeight(0)
+	if err != nil {
+		return lookupError(err)
+	}
+
+	// The head and height are read together so that the result describes a single chain head
+	head, height := api.chain.Tip()
+	work, err := api.chain.GetChainWork(head)
+	if err != nil {
+		return newError(ErrCodeInternal, "failed to retrieve chain work: %v", err)
+	}
+
+	ledger := core.LedgerAccount
+	if api.chain.Params().IsUTXO() {
+		ledger = core.LedgerUTXO
+	}
+
+	*result = GetChainInfoResult{
+		ChainID:     api.chain.Params().ChainID,
+		ChainHead:   head.Hex(),
+		ChainHeight: uint64(height),
+		GenesisHash: genesis.BlockHash.Hex(),
+		Difficulty:  api.chain.Difficulty(),
+		TotalWork:   common.HexEncode(work.Bytes()),
+		Ledger:      ledger,
+
+		PrunedHeight: uint64(api.chain.PrunedHeight()),
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package jsonrpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+)
+
+// Error codes returned by the Essensio API.
+// The codes follow the JSON-RPC 2.0 conventions, with
+// the server error range used for API specific errors.
+const (
+	// ErrCodeParse indicates that the request is not valid JSON
+	ErrCodeParse = -32700
+	// ErrCodeInvalidRequest indicates that the request is not a valid JSON-RPC 2.0 request object
+	ErrCodeInvalidRequest = -32600
+	// ErrCodeMethodNotFound indicates that the requested method does not exist
+	ErrCodeMethodNotFound = -32601
+	// ErrCodeInvalidParams indicates that the method arguments are malformed or invalid
+	ErrCodeInvalidParams = -32602
+	// ErrCodeInternal indicates an unexpected failure within the node
+	ErrCodeInternal = -32603
+	// ErrCodeNotFound indicates that the requested block or transaction does not exist
+	ErrCodeNotFound = -32001
+	// ErrCodeRejected indicates that the submitted transactions were rejected by the chain
+	ErrCodeRejected = -32002
+	// ErrCodePruned indicates that the requested block, transaction or state has been pruned from the node
+	ErrCodePruned = -32003
+)
+
+// Error is an error returned by an Essensio API method.
+// It is encoded as a JSON-RPC 2.0 error object.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// Error implements the error interface for Error
+func (err *Error) Error() string {
+	return fmt.Sprintf("%v (code %v)", err.Message, err.Code)
+}
+
+// newError returns an Error with the given code and formatted message
+func newError(code int, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// WithData sets the additional information about the Error and returns it
+func (err *Error) WithData(data any) *Error {
+	err.Data = data
+	return err
+}
+
+// invalidParams returns an Error with the ErrCodeInvalidParams code
+func invalidParams(format string, args ...any) *Error {
+	return newError(ErrCodeInvalidParams, format, args...)
+}
+
+// lookupError converts an error from a chain lookup into an Error.
+// ErrBlockNotFound and ErrTransactionNotFound are converted into an Error with the ErrCodeNotFound
+// code, ErrBlockPruned and ErrStateUnavailable with the ErrCodePruned code and all others with ErrCodeInternal.
+func lookupError(err error) *Error {
+	if errors.Is(err, chainmgr.ErrBlockNotFound) || errors.Is(err, chainmgr.ErrTransactionNotFound) {
+		return newError(ErrCodeNotFound, "%v", err)
+	}
+
+	if errors.Is(err, chainmgr.ErrBlockPruned) || errors.Is(err, chainmgr.ErrStateUnavailable) {
+		return newError(ErrCodePruned, "%v", err)
+	}
+
+	return newError(ErrCodeInternal, "%v", err)
+}
@@ -0,0 +1,81 @@
+package jsonrpc
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+type GetBlockByHashArgs struct {
+	Hash        string `json:"hash"`
+	HeadersOnly bool   `json:"headers_only,omitempty"`
+}
+
+type GetBlockByHeightArgs struct {
+	Height      *uint64 `json:"height"`
+	HeadersOnly bool    `json:"headers_only,omitempty"`
+}
+
+type GetLatestBlockArgs struct {
+	HeadersOnly bool `json:"headers_only,omitempty"`
+}
+
+// blockResult converts the Block with the given hash into a ChainBlock and sets it to result.
+// Only the header of the Block is read if headersOnly is set.
+func (api *API) blockResult(hash common.Hash, headersOnly bool, result *ChainBlock) error {
+	if headersOnly {
+		header, err := api.chain.GetHeaderByHash(hash)
+		if err != nil {
+			return lookupError(err)
+		}
+
+		*result = newChainHeader(header)
+		return nil
+	}
+
+	block, err := api.chain.GetBlockByHash(hash)
+	if err != nil {
+		return lookupError(err)
+	}
+
+	chainblock, err := newChainBlock(block, block.BlockTxns, false)
+	if err != nil {
+		return newError(ErrCodeInternal, "%v", err)
+	}
+
+	*result = chainblock
+	return nil
+}
+
+func (api *API) GetBlockByHash(r *http.Request, args *GetBlockByHashArgs, result *ChainBlock) error {
+	log.Println("'GetBlockByHash' Called")
+
+	hash, err := common.HexToHash(args.Hash)
+	if err != nil {
+		return invalidParams("invalid block hash '%v': %v", args.Hash, err)
+	}
+
+	return api.blockResult(hash, args.HeadersOnly, result)
+}
+
+func (api *API) GetBlockByHeight(r *http.Request, args *GetBlockByHeightArgs, result *ChainBlock) error {
+	log.Println("'GetBlockByHeight' Called")
+
+	if args.Height == nil {
+		return invalidParams("missing block height")
+	}
+
+	hash, err := api.chain.GetHashByHeight(int64(*args.Height))
+	if err != nil {
+		return lookupError(err)
+	}
+
+	return api.blockResult(hash, args.HeadersOnly, result)
+}
+
+func (api *API) GetLatestBlock(r *http.Request, args *GetLatestBlockArgs, result *ChainBlock) error {
+	log.Println("'GetLatestBlock' Called")
+
+	return api.blockResult(api.chain.Head(), args.HeadersOnly, result)
+}
@@ -0,0 +1,122 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+type GetTransactionByHashArgs struct {
+	Hash string `json:"hash"`
+}
+
+type GetTransactionByHashResult struct {
+	BlockTransaction
+
+	BlockHash   string `json:"block_hash"`
+	BlockHeight uint64 `json:"block_height"`
+	Index       uint64 `json:"index"`
+}
+
+type GetTransactionProofArgs struct {
+	Hash string `json:"hash"`
+}
+
+type GetTransactionProofResult struct {
+	GetTransactionByHashResult
+
+	Proof []MerkleStep `json:"proof"`
+}
+
+type MerkleStep struct {
+	Hash string `json:"hash"`
+	Left bool   `json:"left,omitempty"`
+}
+
+// MerkleProof converts the proof of the GetTransactionProofResult into a core.MerkleProof.
+// Returns an error if any of its hashes are invalid.
+func (result GetTransactionProofResult) MerkleProof() (core.MerkleProof, error) {
+	proof := make(core.MerkleProof, 0, len(result.Proof))
+	for _, step := range result.Proof {
+		hash, err := common.HexToHash(step.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proof hash '%v': %w", step.Hash, err)
+		}
+
+		proof = append(proof, core.MerkleStep{Hash: hash, Left: step.Left})
+	}
+
+	return proof, nil
+}
+
+func (api *API) GetTransactionByHash(r *http.Request, args *GetTransactionByHashArgs, result *GetTransactionByHashResult) error {
+	log.Println("'GetTransactionByHash' Called")
+
+	hash, err := common.HexToHash(args.Hash)
+	if err != nil {
+		return invalidParams("invalid transaction hash '%v': %v", args.Hash, err)
+	}
+
+	txn, block, idx, err := api.chain.GetTransaction(hash)
+	if err != nil {
+		return lookupError(err)
+	}
+
+	transaction, err := NewBlockTransaction(txn)
+	if err != nil {
+		return newError(ErrCodeInternal, "%v", err)
+	}
+
+	*result = GetTransactionByHashResult{
+		BlockTransaction: transaction,
+		BlockHash:        block.BlockHash.Hex(),
+		BlockHeight:      uint64(block.BlockHeight),
+		Index:            uint64(idx),
+	}
+
+	return nil
+}
+
+func (api *API) GetTransactionProof(r *http.Request, args *GetTransactionProofArgs, result *GetTransactionProofResult) error {
+	log.Println("'GetTransactionProof' Called")
+
+	hash, err := common.HexToHash(args.Hash)
+	if err != nil {
+		return invalidParams("invalid transaction hash '%v': %v", args.Hash, err)
+	}
+
+	txn, block, idx, err := api.chain.GetTransaction(hash)
+	if err != nil {
+		return lookupError(err)
+	}
+
+	transaction, err := NewBlockTransaction(txn)
+	if err != nil {
+		return newError(ErrCodeInternal, "%v", err)
+	}
+
+	proof, err := core.NewMerkleProof(block.BlockTxns, idx)
+	if err != nil {
+		return newError(ErrCodeInternal, "failed to generate merkle proof: %v", err)
+	}
+
+	steps := make([]MerkleStep, 0, len(proof))
+	for _, step := range proof {
+		steps = append(steps, MerkleStep{Hash: step.Hash.Hex(), Left: step.Left})
+	}
+
+	*result = GetTransactionProofResult{
+		GetTransactionByHashResult: GetTransactionByHashResult{
+			BlockTransaction: transaction,
+			BlockHash:        block.BlockHash.Hex(),
+			BlockHeight:      uint64(block.BlockHeight),
+			Index:            uint64(idx),
+		},
+		Proof: steps,
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package jsonrpc
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/keystore"
+)
+
+type ListAccountsArgs struct{}
+
+type ListAccountsResult struct {
+	Accounts []KeystoreAccount `json:"accounts"`
+}
+
+type KeystoreAccount struct {
+	Address  string `json:"address"`
+	Unlocked bool   `json:"unlocked"`
+	// Time at which the account is locked, omitted if it is locked or does not expire
+	Expires string `json:"expires,omitempty"`
+}
+
+type UnlockAccountArgs struct {
+	Address  string `json:"address"`
+	Password string `json:"password"`
+	// Number of seconds to keep the account unlocked, 0 keeps it unlocked until it is locked
+	Duration uint64 `json:"duration,omitempty"`
+}
+
+type LockAccountArgs struct {
+	Address string `json:"address"`
+}
+
+// keystoreAccount returns the KeystoreAccount for the given address
+func (api *API) keystoreAccount(address common.Address) KeystoreAccount {
+	account := KeystoreAccount{Address: string(address)}
+
+	unlocked, expires := api.keys.Unlocked(address)
+	if account.Unlocked = unlocked; unlocked && !expires.IsZero() {
+		account.Expires = expires.UTC().Format(time.RFC3339)
+	}
+
+	return account
+}
+
+// keystoreError converts an error from the keystore into an Error.
+// Unknown accounts and wrong passwords are rejected and all others are internal errors.
+func keystoreError(err error) *Error {
+	if errors.Is(err, keystore.ErrAccountNotFound) {
+		return newError(ErrCodeNotFound, "%v", err)
+	}
+
+	if errors.Is(err, keystore.ErrDecrypt) {
+		return newError(ErrCodeRejected, "%v", err)
+	}
+
+	return newError(ErrCodeInternal, "%v", err)
+}
+
+// requireKeystore returns an error if the node has no keystore
+func (api *API) requireKeystore() error {
+	if api.keys == nil {
+		return newError(ErrCodeRejected, "node has no keystore")
+	}
+
+	return nil
+}
+
+func (api *API) ListAccounts(r *http.Request, args *ListAccountsArgs, result *ListAccountsResult) error {
+	log.Println("'ListAccounts' Called")
+
+	if err := api.requireKeystore(); err != nil {
+		return err
+	}
+
+	addresses, err := api.keys.Accounts()
+	if err != nil {
+		return keystoreError(err)
+	}
+
+	accounts := make([]KeystoreAccount, 0, len(addresses))
+	for _, address := range addresses {
+		accounts = append(accounts, api.keystoreAccount(address))
+	}
+
+	*result = ListAccountsResult{accounts}
+	return nil
+}
+
+func (api *API) UnlockAccount(r *http.Request, args *UnlockAccountArgs, result *KeystoreAccount) error {
+	log.Println("'UnlockAccount' Called")
+
+	if err := api.requireKeystore(); err != nil {
+		return err
+	}
+
+	address := common.Address(args.Address)
+	if err := api.keys.Unlock(address, args.Password, time.Duration(args.Duration)*time.Second); err != nil {
+		return keystoreError(err)
+	}
+
+	*result = api.keystoreAccount(address)
+	return nil
+}
+
+func (api *API) LockAccount(r *http.Request, args *LockAccountArgs, result *KeystoreAccount) error {
+	log.Println("'LockAccount' Called")
+
+	if err := api.requireKeystore(); err != nil {
+		return err
+	}
+
+	address := common.Address(args.Address)
+	if !api.keys.Has(address) {
+		return keystoreError(keystore.ErrAccountNotFound)
+	}
+
+	api.keys.Lock(address)
+
+	*result = api.keystoreAccount(address)
+	return nil
+}
@@ -0,0 +1,271 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+type CreateMultisigArgs struct {
+	Threshold  int      `json:"threshold"`
+	PublicKeys []string `json:"public_keys"`
+}
+
+type MultisigAccount struct {
+	Address   string `json:"address"`
+	Script    string `json:"script"`
+	Threshold int    `json:"threshold"`
+	// Public keys of the participants in the order of the lock script
+	PublicKeys []string `json:"public_keys"`
+}
+
+type PartialTransaction struct {
+	// Transaction from the multisig address, with the lock script of the multisig and no witness
+	Transaction BlockTransaction   `json:"transaction"`
+	Threshold   int                `json:"threshold"`
+	Signatures  []PartialSignature `json:"signatures"`
+}
+
+type PartialSignature struct {
+	PublicKey string `json:"public_key"`
+	// Signature of the participant, omitted until it has signed
+	Signature string `json:"signature,omitempty"`
+}
+
+type CreatePartialTransactionArgs struct {
+	// Hex encoded lock script of the sending multisig address
+	Script string        `json:"script"`
+	To     string        `json:"to"`
+	Value  common.Amount `json:"value"`
+	Fee    common.Amount `json:"fee"`
+}
+
+type SignPartialTransactionArgs struct {
+	Partial PartialTransaction `json:"partial"`
+	// Address of the participant whose key is unlocked in the node's keystore
+	Address string `json:"address"`
+}
+
+type SubmitPartialTransactionArgs struct {
+	Partial PartialTransaction `json:"partial"`
+}
+
+// NewMultisigAccount converts a core.Multisig into a MultisigAccount
+func NewMultisigAccount(multisig *core.Multisig) MultisigAccount {
+	account := MultisigAccount{
+		Address:   string(multisig.Address()),
+		Script:    common.HexEncode(multisig.Script()),
+		Threshold: multisig.Threshold,
+	}
+
+	for _, publicKey := range multisig.PublicKeys {
+		account.PublicKeys = append(account.PublicKeys, common.HexEncode(publicKey))
+	}
+
+	return account
+}
+
+// NewPartialTransaction converts a core.PartialTransaction into a PartialTransaction
+func NewPartialTransaction(partial *core.PartialTransaction) (PartialTransaction, error) {
+	blocktxn, err := NewBlockTransaction(partial.Transaction)
+	if err != nil {
+		return PartialTransaction{}, err
+	}
+
+	result := PartialTransaction{Transaction: blocktxn, Threshold: partial.Multisig.Threshold}
+	for idx, publicKey := range partial.Multisig.PublicKeys {
+		signature := PartialSignature{PublicKey: common.HexEncode(publicKey)}
+		if partial.Signatures[idx] != nil {
+			signature.Signature = common.HexEncode(partial.Signatures[idx])
+		}
+
+		result.Signatures = append(result.Signatures, signature)
+	}
+
+	return result, nil
+}
+
+// Partial converts the PartialTransaction into a core.PartialTransaction. The multisig account is parsed from
+// the lock script of the transaction, and every signature is verified. Returns an error if the transaction or
+// its lock script are invalid, or if any signature is not valid for a participant of the multisig.
+func (partial PartialTransaction) Partial() (*core.PartialTransaction, error) {
+	txn, err := partial.Transaction.Transaction()
+	if err != nil {
+		return nil, err
+	}
+
+	multisig, err := core.ParseMultisig(txn.Script)
+	if err != nil {
+		return nil, err
+	}
+
+	// The witness of the transaction is only set when it is finalized
+	txn.Witness = nil
+	result, err := core.NewPartialTransaction(txn, multisig)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, signature := range partial.Signatures {
+		if signature.Signature == "" {
+			continue
+		}
+
+		publicKey, err := common.HexDecode(signature.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key '%v': %w", signature.PublicKey, err)
+		}
+
+		sig, err := common.HexDecode(signature.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature '%v': %w", signature.Signature, err)
+		}
+
+		if err := result.AddSignature(publicKey, sig); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// NewTransactionInput converts a signed core.Transaction into a TransactionInput.
+// The nonce of a Transaction on a chain with the UTXO ledger is omitted.
+func NewTransactionInput(txn *core.Transaction) TransactionInput {
+	input := TransactionInput{
+		From: string(txn.From), To: string(txn.To), Value: txn.Value, Fee: txn.Fee,
+		Inputs: NewTxInputs(txn.Inputs), Outputs: NewTxOutputs(txn.Outputs),
+		ChainID: &txn.ChainID,
+	}
+
+	if !txn.IsUTXO() {
+		input.Nonce = &txn.Nonce
+	}
+
+	if len(txn.Signature) > 0 {
+		input.PublicKey = common.HexEncode(txn.PublicKey)
+		input.Signature = common.HexEncode(txn.Signature)
+	}
+
+	if txn.IsScripted() {
+		input.Script = common.HexEncode(txn.Script)
+	}
+
+	if len(txn.Witness) > 0 {
+		input.Witness = common.HexEncode(txn.Witness)
+	}
+
+	return input
+}
+
+func (api *API) CreateMultisig(r *http.Request, args *CreateMultisigArgs, result *MultisigAccount) error {
+	log.Println("'CreateMultisig' Called")
+
+	publicKeys := make([][]byte, 0, len(args.PublicKeys))
+	for _, publicKey := range args.PublicKeys {
+		decoded, err := common.HexDecode(publicKey)
+		if err != nil {
+			return invalidParams("invalid public key '%v': %v", publicKey, err)
+		}
+
+		publicKeys = append(publicKeys, decoded)
+	}
+
+	multisig, err := core.NewMultisig(args.Threshold, publicKeys)
+	if err != nil {
+		return invalidParams("%v", err)
+	}
+
+	*result = NewMultisigAccount(multisig)
+	return nil
+}
+
+func (api *API) CreatePartialTransaction(r *http.Request, args *CreatePartialTransactionArgs, result *PartialTransaction) error {
+	log.Println("'CreatePartialTransaction' Called")
+
+	lock, err := common.HexDecode(args.Script)
+	if err != nil {
+		return invalidParams("invalid lock script: %v", err)
+	}
+
+	multisig, err := core.ParseMultisig(lock)
+	if err != nil {
+		return invalidParams("%v", err)
+	}
+
+	params := api.chain.Params()
+	from := multisig.Address()
+
+	// The transaction is built as a transfer from the multisig address, which spends its unspent outputs on a chain
+	// with the UTXO ledger and has the next nonce of its account otherwise
+	txn, rpcErr := api.newTransaction(0, params.ChainID, params.IsUTXO(), TransactionInput{From: string(from), To: args.To, Value: args.Value, Fee: args.Fee}, nil)
+	if rpcErr != nil {
+		return rpcErr
+	}
+
+	if !txn.IsUTXO() {
+		account, err := api.chain.GetAccount(from)
+		if err != nil {
+			return newError(ErrCodeInternal, "failed to retrieve account '%v': %v", from, err)
+		}
+
+		txn.Nonce = account.Nonce
+	}
+
+	partial, err := core.NewPartialTransaction(txn, multisig)
+	if err != nil {
+		return invalidParams("%v", err)
+	}
+
+	if err := txn.CheckSanity(); err != nil {
+		return invalidParams("%v", err)
+	}
+
+	if *result, err = NewPartialTransaction(partial); err != nil {
+		return newError(ErrCodeInternal, "%v", err)
+	}
+
+	return nil
+}
+
+func (api *API) SignPartialTransaction(r *http.Request, args *SignPartialTransactionArgs, result *PartialTransaction) error {
+	log.Println("'SignPartialTransaction' Called")
+
+	if err := api.requireKeystore(); err != nil {
+		return err
+	}
+
+	partial, err := args.Partial.Partial()
+	if err != nil {
+		return invalidParams("invalid partial transaction: %v", err)
+	}
+
+	if err := api.keys.SignPartialTransaction(partial, common.Address(args.Address)); err != nil {
+		return newError(ErrCodeRejected, "%v", err)
+	}
+
+	if *result, err = NewPartialTransaction(partial); err != nil {
+		return newError(ErrCodeInternal, "%v", err)
+	}
+
+	return nil
+}
+
+func (api *API) SubmitPartialTransaction(r *http.Request, args *SubmitPartialTransactionArgs, result *AddBlockResult) error {
+	log.Println("'SubmitPartialTransaction' Called")
+
+	partial, err := args.Partial.Partial()
+	if err != nil {
+		return invalidParams("invalid partial transaction: %v", err)
+	}
+
+	txn, err := partial.Finalize()
+	if err != nil {
+		return newError(ErrCodeRejected, "%v", err)
+	}
+
+	return api.AddBlock(r, &AddBlockArgs{Transactions: []TransactionInput{NewTransactionInput(txn)}}, result)
+}
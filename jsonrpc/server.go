@@ -0,0 +1,326 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"reflect"
+)
+
+// Version is the JSON-RPC protocol version spoken by the Server
+const Version = "2.0"
+
+const (
+	// MaxBatchSize is the maximum number of requests in a single batch
+	MaxBatchSize = 100
+	// MaxRequestSize is the maximum size of a request body in bytes
+	MaxRequestSize = 5 << 20
+)
+
+// Request is a JSON-RPC 2.0 request object.
+// A Request without an ID is a notification and receives no Response.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// IsNotification returns whether the Request is a notification
+func (request *Request) IsNotification() bool {
+	return len(request.ID) == 0
+}
+
+// Response is a JSON-RPC 2.0 response object.
+// Exactly one of Result and Error is set.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  any             `json:"result"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// MarshalJSON implements the json.Marshaler interface for Response.
+// The result member is encoded whenever the Response has no error, even if
+// it is null, so that the Response always has either a result or an error.
+func (response Response) MarshalJSON() ([]byte, error) {
+	if response.Error != nil {
+		return json.Marshal(struct {
+			JSONRPC string          `json:"jsonrpc"`
+			Error   *Error          `json:"error"`
+			ID      json.RawMessage `json:"id"`
+		}{response.JSONRPC, response.Error, response.ID})
+	}
+
+	return json.Marshal(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		Result  any             `json:"result"`
+		ID      json.RawMessage `json:"id"`
+	}{response.JSONRPC, response.Result, response.ID})
+}
+
+// errorResponse returns a Response for the given id with the given Error
+func errorResponse(id json.RawMessage, err *Error) *Response {
+	return &Response{JSONRPC: Version, Error: err, ID: id}
+}
+
+var (
+	typeOfError   = reflect.TypeOf((*error)(nil)).Elem()
+	typeOfRequest = reflect.TypeOf((*http.Request)(nil))
+)
+
+// method is a service method registered with the Server
+type method struct {
+	function  reflect.Value
+	argsType  reflect.Type
+	replyType reflect.Type
+}
+
+// Server is a JSON-RPC 2.0 server over HTTP.
+// It supports single and batch requests as well as notifications,
+// which must be POST requests with the application/json Content-Type.
+// The requests of a batch are called in order, one after another.
+type Server struct {
+	methods map[string]*method
+}
+
+// NewServer returns a new Server with no registered services
+func NewServer() *Server {
+	return &Server{make(map[string]*method)}
+}
+
+// RegisterService registers the methods of the receiver as the service with the given name.
+// If name is empty, it is inferred from the receiver type name. Methods are called as
+// "Service.Method" and are registered if they are exported and have the signature:
+//
+//	func (receiver) Method(r *http.Request, args *Args, reply *Reply) error
+//
+// All other methods are ignored. Returns an error if the receiver has no such methods.
+func (server *Server) RegisterService(receiver any, name string) error {
+	rcvr := reflect.ValueOf(receiver)
+	if name == "" {
+		name = reflect.Indirect(rcvr).Type().Name()
+	}
+
+	registered := 0
+	for idx := 0; idx < rcvr.NumMethod(); idx++ {
+		function, mtype := rcvr.Method(idx), rcvr.Type().Method(idx).Type
+
+		// Check the method signature (the receiver is the first input)
+		if mtype.NumIn() != 4 || mtype.NumOut() != 1 || mtype.Out(0) != typeOfError {
+			continue
+		}
+		if mtype.In(1) != typeOfRequest || mtype.In(2).Kind() != reflect.Pointer || mtype.In(3).Kind() != reflect.Pointer {
+			continue
+		}
+
+		server.methods[name+"."+rcvr.Type().Method(idx).Name] = &method{
+			function:  function,
+			argsType:  mtype.In(2).Elem(),
+			replyType: mtype.In(3).Elem(),
+		}
+
+		registered++
+	}
+
+	if registered == 0 {
+		return fmt.Errorf("rpc: %v has no exported methods of suitable type", name)
+	}
+
+	return nil
+}
+
+// HasMethod returns whether a method with the given "Service.Method" name is registered
+func (server *Server) HasMethod(name string) bool {
+	_, ok := server.methods[name]
+	return ok
+}
+
+// Call calls the method with the given "Service.Method" name in-process. The args and reply
+// are converted through their JSON encoding, exactly as they are for a request over HTTP,
+// and the method is called with a request that carries the given context.
+// Returns an *Error if the method fails.
+func (server *Server) Call(ctx context.Context, name string, args, reply any) error {
+	params, err := json.Marshal(args)
+	if err != nil {
+		return invalidParams("invalid params: %v", err)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	if err != nil {
+		return newError(ErrCodeInternal, "%v", err)
+	}
+
+	result, rpcErr := server.call(r, &Request{JSONRPC: Version, Method: name, Params: params})
+	if rpcErr != nil {
+		return rpcErr
+	}
+
+	// Convert the result into the reply
+	data, err := json.Marshal(result)
+	if err != nil {
+		return newError(ErrCodeInternal, "failed to encode result: %v", err)
+	}
+
+	if err := json.Unmarshal(data, reply); err != nil {
+		return newError(ErrCodeInternal, "failed to decode result: %v", err)
+	}
+
+	return nil
+}
+
+// ServeHTTP implements the http.Handler interface for Server
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "rpc: POST method required, received "+r.Method, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Requests must be JSON, which also keeps browsers from sending
+	// requests from other origins without a CORS preflight
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		http.Error(w, "rpc: unsupported Content-Type, expected application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxRequestSize))
+	if err != nil {
+		writeJSON(w, errorResponse(nil, newError(ErrCodeParse, "failed to read request body: %v", err)))
+		return
+	}
+
+	// A batch is a JSON array of requests
+	if body = bytes.TrimSpace(body); len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			writeJSON(w, errorResponse(nil, newError(ErrCodeParse, "parse error: %v", err)))
+			return
+		}
+
+		if len(batch) == 0 {
+			writeJSON(w, errorResponse(nil, newError(ErrCodeInvalidRequest, "empty batch")))
+			return
+		}
+
+		if len(batch) > MaxBatchSize {
+			writeJSON(w, errorResponse(nil, newError(ErrCodeInvalidRequest, "batch of %v requests exceeds the limit of %v", len(batch), MaxBatchSize)))
+			return
+		}
+
+		// Handle the requests one after another in order, so that a request
+		// observes the effects of the requests before it, such as mined blocks
+		responses := make([]*Response, len(batch))
+		for idx, raw := range batch {
+			responses[idx] = server.handle(r, raw)
+		}
+
+		// Notifications do not have responses
+		replies := make([]*Response, 0, len(responses))
+		for _, response := range responses {
+			if response != nil {
+				replies = append(replies, response)
+			}
+		}
+
+		// Nothing is returned if the batch only contained notifications
+		if len(replies) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		writeJSON(w, replies)
+		return
+	}
+
+	if response := server.handle(r, body); response != nil {
+		writeJSON(w, response)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handle decodes and calls a single request.
+// Returns nil if the request is a notification.
+func (server *Server) handle(r *http.Request, raw json.RawMessage) *Response {
+	var request Request
+	if err := json.Unmarshal(raw, &request); err != nil {
+		// A request that is valid JSON but not an object is an invalid request
+		if json.Valid(raw) {
+			return errorResponse(nil, newError(ErrCodeInvalidRequest, "invalid request: %v", err))
+		}
+
+		return errorResponse(nil, newError(ErrCodeParse, "parse error: %v", err))
+	}
+
+	if request.JSONRPC != Version || request.Method == "" {
+		return errorResponse(request.ID, newError(ErrCodeInvalidRequest, "invalid request: must have jsonrpc '%v' and a method", Version))
+	}
+
+	result, err := server.call(r, &request)
+	if request.IsNotification() {
+		if err != nil {
+			log.Printf("Notification '%v' Failed: %v\n", request.Method, err)
+		}
+
+		return nil
+	}
+
+	if err != nil {
+		return errorResponse(request.ID, err)
+	}
+
+	return &Response{JSONRPC: Version, Result: result, ID: request.ID}
+}
+
+// call decodes the params of a request and calls its method
+func (server *Server) call(r *http.Request, request *Request) (any, *Error) {
+	method, ok := server.methods[request.Method]
+	if !ok {
+		return nil, newError(ErrCodeMethodNotFound, "method '%v' not found", request.Method).WithData(map[string]string{"method": request.Method})
+	}
+
+	// Decode the params into the method args. Params may be given by name as an
+	// object or by position as an array containing the args object. Omitted
+	// params leave the args at their zero value.
+	args := reflect.New(method.argsType)
+	if params := bytes.TrimSpace(request.Params); len(params) > 0 && !bytes.Equal(params, []byte("null")) {
+		var err error
+		if params[0] == '[' {
+			positional := [1]any{args.Interface()}
+			err = json.Unmarshal(params, &positional)
+		} else {
+			err = json.Unmarshal(params, args.Interface())
+		}
+
+		if err != nil {
+			return nil, invalidParams("invalid params: %v", err)
+		}
+	}
+
+	reply := reflect.New(method.replyType)
+	returns := method.function.Call([]reflect.Value{reflect.ValueOf(r), args, reply})
+
+	if err, _ := returns[0].Interface().(error); err != nil {
+		if rpcErr, ok := err.(*Error); ok {
+			return nil, rpcErr
+		}
+
+		return nil, newError(ErrCodeInternal, "%v", err)
+	}
+
+	return reply.Interface(), nil
+}
+
+// writeJSON writes the JSON encoding of v as the response
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println("RPC Response Write Failed:", err)
+	}
+}
@@ -0,0 +1,158 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testService is a service whose methods count their calls
+type testService struct {
+	calls int
+}
+
+type echoArgs struct {
+	Value string `json:"value"`
+}
+
+type echoReply struct {
+	Value string `json:"value"`
+}
+
+func (service *testService) Echo(r *http.Request, args *echoArgs, reply *echoReply) error {
+	service.calls++
+	reply.Value = args.Value
+	return nil
+}
+
+// Nothing replies with null
+func (service *testService) Nothing(r *http.Request, args *echoArgs, reply **echoReply) error {
+	service.calls++
+	return nil
+}
+
+// newTestServer returns a Server with a registered testService
+func newTestServer(t *testing.T) (*Server, *testService) {
+	t.Helper()
+
+	service := new(testService)
+	server := NewServer()
+	if err := server.RegisterService(service, "Test"); err != nil {
+		t.Fatalf("RegisterService: %v", err)
+	}
+
+	return server, service
+}
+
+// serve sends a POST request with the given Content-Type and body to the Server
+func serve(server *Server, contentType, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, r)
+	return w
+}
+
+func TestServeHTTPContentType(t *testing.T) {
+	server, service := newTestServer(t)
+	body := `{"jsonrpc": "2.0", "method": "Test.Echo", "params": {"value": "echo"}, "id": 1}`
+
+	tests := []struct {
+		contentType string
+		status      int
+	}{
+		{"application/json", http.StatusOK},
+		{"application/json; charset=utf-8", http.StatusOK},
+		{"Application/JSON", http.StatusOK},
+		{"", http.StatusUnsupportedMediaType},
+		{"text/plain", http.StatusUnsupportedMediaType},
+		{"text/plain; charset=utf-8", http.StatusUnsupportedMediaType},
+		{"application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+		{"multipart/form-data; boundary=x", http.StatusUnsupportedMediaType},
+		{"application/json;;", http.StatusUnsupportedMediaType},
+	}
+
+	for _, test := range tests {
+		calls := service.calls
+		w := serve(server, test.contentType, body)
+		if w.Code != test.status {
+			t.Errorf("ServeHTTP(%q): expected status %v, got %v", test.contentType, test.status, w.Code)
+		}
+
+		// Requests that are refused never reach the method
+		if called := service.calls > calls; called != (test.status == http.StatusOK) {
+			t.Errorf("ServeHTTP(%q): expected the method to be called: %v, got %v", test.contentType, test.status == http.StatusOK, called)
+		}
+	}
+
+	// Only POST requests are served
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("ServeHTTP(GET): expected status %v, got %v", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
+func TestServeHTTPResult(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	// result is the expected encoding of the result, or empty if an error is expected
+	tests := []struct {
+		body   string
+		result string
+	}{
+		{`{"jsonrpc": "2.0", "method": "Test.Echo", "params": {"value": "echo"}, "id": 1}`, `{"value":"echo"}`},
+		{`{"jsonrpc": "2.0", "method": "Test.Nothing", "id": 1}`, `null`},
+		{`{"jsonrpc": "2.0", "method": "Test.Unknown", "id": 1}`, ""},
+		{`{"jsonrpc": "2.0", "method": "Test.Echo", "params": 1, "id": 1}`, ""},
+		{`{"jsonrpc": "2.0", "method": "Test.Echo"`, ""},
+	}
+
+	for _, test := range tests {
+		w := serve(server, "application/json", test.body)
+
+		var response map[string]json.RawMessage
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("ServeHTTP(%v): invalid response %q: %v", test.body, w.Body.String(), err)
+		}
+
+		// A response has exactly one of result and error, even if the result is null
+		result, hasResult := response["result"]
+		_, hasError := response["error"]
+		if hasResult == hasError || hasResult != (test.result != "") {
+			t.Errorf("ServeHTTP(%v): expected exactly one of result and error, got %v", test.body, w.Body.String())
+			continue
+		}
+
+		if hasResult && string(result) != test.result {
+			t.Errorf("ServeHTTP(%v): expected result %v, got %v", test.body, test.result, string(result))
+		}
+	}
+}
+
+func TestResponseMarshal(t *testing.T) {
+	tests := []struct {
+		response *Response
+		encoded  string
+	}{
+		{&Response{JSONRPC: Version, ID: json.RawMessage("1")}, `{"jsonrpc":"2.0","result":null,"id":1}`},
+		{&Response{JSONRPC: Version, Result: "ok", ID: json.RawMessage(`"a"`)}, `{"jsonrpc":"2.0","result":"ok","id":"a"}`},
+		{errorResponse(nil, newError(ErrCodeParse, "parse error")), `{"jsonrpc":"2.0","error":{"code":-32700,"message":"parse error"},"id":null}`},
+	}
+
+	for _, test := range tests {
+		data, err := json.Marshal(test.response)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+
+		if string(data) != test.encoded {
+			t.Errorf("Marshal: expected %v, got %v", test.encoded, string(data))
+		}
+	}
+}
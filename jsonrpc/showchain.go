@@ -1,71 +1,375 @@
 package jsonrpc
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+)
+
+const (
+	// DefaultPageLimit is the number of blocks returned by ShowChain if no limit is specified
+	DefaultPageLimit uint64 = 25
+	// MaxPageLimit is the maximum number of blocks returned by ShowChain in a single page
+	MaxPageLimit uint64 = 100
+	// MaxScanBlocks is the maximum number of blocks read by ShowChain for a single page
+	MaxScanBlocks uint64 = 1000
 )
 
-type ShowChainArgs struct{}
+const (
+	// DirectionDesc iterates from higher blocks to lower blocks (default)
+	DirectionDesc = "desc"
+	// DirectionAsc iterates from lower blocks to higher blocks
+	DirectionAsc = "asc"
+)
+
+type ShowChainArgs struct {
+	// Height of the first block of the page.
+	// Defaults to the upper end of the range for descending pages
+	// and the lower end of the range for ascending pages.
+	Start *uint64 `json:"start,omitempty"`
+	// Maximum number of blocks in the page.
+	// Defaults to DefaultPageLimit and is capped at MaxPageLimit.
+	Limit uint64 `json:"limit,omitempty"`
+	// Direction of iteration, either "desc" (default) or "asc"
+	Direction string `json:"direction,omitempty"`
+	// Omit the transactions of each block if set
+	HeadersOnly bool `json:"headers_only,omitempty"`
+
+	// Only include blocks (and transactions) that involve this address.
+	// At most MaxScanBlocks blocks are read for a page, which then holds fewer
+	// blocks than the limit and has its next cursor set to continue the scan.
+	Address string `json:"address,omitempty"`
+	// Lower bound (inclusive) of the block heights to include
+	FromHeight *uint64 `json:"from_height,omitempty"`
+	// Upper bound (inclusive) of the block heights to include
+	ToHeight *uint64 `json:"to_height,omitempty"`
+}
 
 type ShowChainResult struct {
 	ChainHead   string       `json:"chain_head"`
 	ChainHeight uint64       `json:"chain_height"`
 	Blocks      []ChainBlock `json:"blocks"`
+
+	// Start height of the next page, omitted if there are no more blocks
+	Next *uint64 `json:"next,omitempty"`
 }
 
 type ChainBlock struct {
+	ChainID   uint64 `json:"chain_id"`
 	Height    uint64 `json:"height"`
 	Nonce     uint64 `json:"nonce"`
+	Bits      string `json:"bits"`
 	Timestamp string `json:"timestamp"`
 
 	BlockHash     string `json:"block_hash"`
 	PrevBlockHash string `json:"prev_block_hash"`
+	Summary       string `json:"summary"`
+	StateRoot     string `json:"state_root"`
 
 	TxnCount     int                `json:"txn_count"`
-	Transactions []BlockTransaction `json:"transactions"`
+	Transactions []BlockTransaction `json:"transactions,omitempty"`
 }
 
 type BlockTransaction struct {
-	To    string `json:"to"`
-	From  string `json:"from"`
-	Value uint64 `json:"value"`
-	Nonce uint64 `json:"nonce"`
+	Hash    string        `json:"hash"`
+	ChainID uint64        `json:"chain_id"`
+	To      string        `json:"to"`
+	From    string        `json:"from"`
+	Value   common.Amount `json:"value"`
+	Fee     common.Amount `json:"fee"`
+	Nonce   uint64        `json:"nonce"`
+
+	// Inputs and Outputs are set for transactions on chains with the UTXO ledger
+	Inputs  []TxInput  `json:"inputs,omitempty"`
+	Outputs []TxOutput `json:"outputs,omitempty"`
+
+	PublicKey string `json:"public_key,omitempty"`
+	Signature string `json:"signature,omitempty"`
+
+	// Script and Witness are set instead of PublicKey and Signature for senders with a lock script
+	Script  string `json:"script,omitempty"`
+	Witness string `json:"witness,omitempty"`
+}
+
+// NewBlockTransaction converts a core.Transaction into a BlockTransaction
+func NewBlockTransaction(txn *core.Transaction) (BlockTransaction, error) {
+	hash, err := txn.Hash()
+	if err != nil {
+		return BlockTransaction{}, fmt.Errorf("transaction hash failed: %w", err)
+	}
+
+	blocktxn := BlockTransaction{
+		Hash: hash.Hex(), ChainID: txn.ChainID, To: string(txn.To), From: string(txn.From),
+		Value: txn.Value, Fee: txn.Fee, Nonce: txn.Nonce,
+		Inputs: NewTxInputs(txn.Inputs), Outputs: NewTxOutputs(txn.Outputs),
+	}
+
+	// Coinbase transactions are not signed
+	if len(txn.Signature) > 0 {
+		blocktxn.PublicKey = common.HexEncode(txn.PublicKey)
+		blocktxn.Signature = common.HexEncode(txn.Signature)
+	}
+
+	if txn.IsScripted() {
+		blocktxn.Script = common.HexEncode(txn.Script)
+	}
+
+	if len(txn.Witness) > 0 {
+		blocktxn.Witness = common.HexEncode(txn.Witness)
+	}
+
+	return blocktxn, nil
+}
+
+// Transaction converts the BlockTransaction into a core.Transaction, whose hash and signature can be verified.
+// Returns an error if its public key, signature, lock script or witness are invalid.
+func (blocktxn BlockTransaction) Transaction() (*core.Transaction, error) {
+	txn := core.NewTransaction(blocktxn.ChainID, common.Address(blocktxn.From), common.Address(blocktxn.To), blocktxn.Nonce, blocktxn.Value, blocktxn.Fee)
+	txn.Outputs = coreOutputs(blocktxn.Outputs)
+
+	var err error
+	if txn.Inputs, err = coreInputs(blocktxn.Inputs); err != nil {
+		return nil, err
+	}
+
+	if blocktxn.PublicKey != "" {
+		if txn.PublicKey, err = common.HexDecode(blocktxn.PublicKey); err != nil {
+			return nil, fmt.Errorf("invalid public key '%v': %w", blocktxn.PublicKey, err)
+		}
+	}
+
+	if blocktxn.Signature != "" {
+		if txn.Signature, err = common.HexDecode(blocktxn.Signature); err != nil {
+			return nil, fmt.Errorf("invalid signature '%v': %w", blocktxn.Signature, err)
+		}
+	}
+
+	if blocktxn.Script != "" {
+		if txn.Script, err = common.HexDecode(blocktxn.Script); err != nil {
+			return nil, fmt.Errorf("invalid lock script '%v': %w", blocktxn.Script, err)
+		}
+	}
+
+	if blocktxn.Witness != "" {
+		if txn.Witness, err = common.HexDecode(blocktxn.Witness); err != nil {
+			return nil, fmt.Errorf("invalid witness '%v': %w", blocktxn.Witness, err)
+		}
+	}
+
+	return txn, nil
+}
+
+// newChainHeader converts a core.Header into a ChainBlock without transactions
+func newChainHeader(header *core.Header) ChainBlock {
+	return ChainBlock{
+		ChainID:       header.ChainID,
+		Height:        uint64(header.BlockHeight),
+		Timestamp:     time.Unix(header.Timestamp, 0).Format(time.RFC3339),
+		BlockHash:     header.BlockHash.Hex(),
+		PrevBlockHash: header.Priori.Hex(),
+		Summary:       header.Summary.Hex(),
+		StateRoot:     header.StateRoot.Hex(),
+		Nonce:         uint64(header.Nonce),
+		Bits:          fmt.Sprintf("0x%08x", header.Bits),
+		TxnCount:      header.TxnCount,
+	}
+}
+
+// Header converts the ChainBlock into a core.Header, whose hash and Proof of Work can be verified.
+// Returns an error if any of the fields of the ChainBlock are invalid.
+func (block ChainBlock) Header() (*core.Header, error) {
+	hash, err := common.HexToHash(block.BlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block hash '%v': %w", block.BlockHash, err)
+	}
+
+	priori, err := common.HexToHash(block.PrevBlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid previous block hash '%v': %w", block.PrevBlockHash, err)
+	}
+
+	summary, err := common.HexToHash(block.Summary)
+	if err != nil {
+		return nil, fmt.Errorf("invalid summary '%v': %w", block.Summary, err)
+	}
+
+	stateRoot, err := common.HexToHash(block.StateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state root '%v': %w", block.StateRoot, err)
+	}
+
+	bits, err := strconv.ParseUint(strings.TrimPrefix(block.Bits, "0x"), 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bits '%v': %w", block.Bits, err)
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, block.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp '%v': %w", block.Timestamp, err)
+	}
+
+	return &core.Header{
+		BlockHeader: core.BlockHeader{
+			ChainID:   block.ChainID,
+			Priori:    priori,
+			Summary:   summary,
+			StateRoot: stateRoot,
+			Timestamp: timestamp.Unix(),
+			Bits:      uint32(bits),
+			Nonce:     int64(block.Nonce),
+		},
+		BlockHeight: int64(block.Height),
+		BlockHash:   hash,
+		TxnCount:    block.TxnCount,
+	}, nil
+}
+
+// newChainBlock converts a core.Block into a ChainBlock.
+// The given transactions are set on the ChainBlock unless headersOnly is set.
+func newChainBlock(block *core.Block, txns core.Transactions, headersOnly bool) (ChainBlock, error) {
+	chainblock := newChainHeader(block.Header())
+	if headersOnly {
+		return chainblock, nil
+	}
+
+	chainblock.Transactions = make([]BlockTransaction, 0, len(txns))
+	for _, txn := range txns {
+		transaction, err := NewBlockTransaction(txn)
+		if err != nil {
+			return ChainBlock{}, err
+		}
+
+		chainblock.Transactions = append(chainblock.Transactions, transaction)
+	}
+
+	return chainblock, nil
+}
+
+// filterTransactions returns the transactions in txns that involve
+// the given address as sender, receiver or the address of an output
+func filterTransactions(txns core.Transactions, address common.Address) core.Transactions {
+	filtered := make(core.Transactions, 0)
+	for _, txn := range txns {
+		if txn.Involves(address) {
+			filtered = append(filtered, txn)
+		}
+	}
+
+	return filtered
 }
 
 func (api *API) ShowChain(r *http.Request, args *ShowChainArgs, result *ShowChainResult) error {
 	log.Println("'ShowChain' Called")
 
+	// The page is read from the chain as of its current head
+	head, chainHeight := api.chain.Tip()
 	chainresult := ShowChainResult{
-		ChainHead:   api.chain.Head.Hex(),
-		ChainHeight: uint64(api.chain.Height),
+		ChainHead:   head.Hex(),
+		ChainHeight: uint64(chainHeight),
+		Blocks:      make([]ChainBlock, 0),
 	}
 
-	iterator := api.chain.NewIterator()
-	for !iterator.Done() {
-		// Get the next block
-		block, err := iterator.Next()
-		if err != nil {
-			log.Fatalln("Iterator Error:", err)
+	// Heights beyond the range of int64 are never in the chain
+	if args.FromHeight != nil && *args.FromHeight > math.MaxInt64 {
+		return invalidParams("invalid from_height: height %v is out of range", *args.FromHeight)
+	}
+	if args.ToHeight != nil && *args.ToHeight > math.MaxInt64 {
+		return invalidParams("invalid to_height: height %v is out of range", *args.ToHeight)
+	}
+
+	// Determine the range of heights to iterate over
+	lower, upper := int64(0), chainHeight-1
+	if args.FromHeight != nil {
+		lower = int64(*args.FromHeight)
+	}
+	if args.ToHeight != nil && int64(*args.ToHeight) < upper {
+		upper = int64(*args.ToHeight)
+	}
+	if args.FromHeight != nil && args.ToHeight != nil && *args.FromHeight > *args.ToHeight {
+		return invalidParams("invalid height range: from_height %v is greater than to_height %v", *args.FromHeight, *args.ToHeight)
+	}
+
+	// Determine the direction of iteration and the default start height
+	var step, start int64
+	switch args.Direction {
+	case "", DirectionDesc:
+		step, start = -1, upper
+	case DirectionAsc:
+		step, start = 1, lower
+	default:
+		return invalidParams("invalid direction '%v': must be '%v' or '%v'", args.Direction, DirectionDesc, DirectionAsc)
+	}
+
+	if args.Start != nil {
+		if *args.Start >= uint64(chainHeight) {
+			return invalidParams("invalid start: height %v is beyond the chain head", *args.Start)
+		}
+
+		start = int64(*args.Start)
+	}
+
+	// Determine the page size
+	limit := args.Limit
+	if limit == 0 {
+		limit = DefaultPageLimit
+	} else if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+
+	// The number of blocks read is capped, because blocks without
+	// transactions for the filtered address are not counted in the page
+	height, scanned := start, uint64(0)
+	for ; height >= lower && height <= upper && uint64(len(chainresult.Blocks)) < limit && scanned < MaxScanBlocks; height += step {
+		scanned++
+
+		// Headers are listed without reading the block bodies unless their transactions are filtered
+		if args.HeadersOnly && args.Address == "" {
+			header, err := api.chain.GetHeaderByHeight(height)
+			if err != nil {
+				return newError(ErrCodeInternal, "failed to retrieve header at height %v: %v", height, err)
+			}
+
+			chainresult.Blocks = append(chainresult.Blocks, newChainHeader(header))
+			continue
+		}
+
+		// Get the block at the height
+		block, err := api.chain.GetBlockByHeight(height)
+		if errors.Is(err, chainmgr.ErrBlockPruned) {
+			return lookupError(err)
+		} else if err != nil {
+			return newError(ErrCodeInternal, "failed to retrieve block at height %v: %v", height, err)
+		}
+
+		// Filter the block transactions if an address is specified
+		txns := block.BlockTxns
+		if args.Address != "" {
+			if txns = filterTransactions(txns, common.Address(args.Address)); len(txns) == 0 {
+				continue
+			}
 		}
 
-		transactions := make([]BlockTransaction, 0, block.TxnCount())
-		for _, txn := range block.BlockTxns {
-			transactions = append(transactions, BlockTransaction{
-				string(txn.To), string(txn.From),
-				txn.Value, txn.Nonce,
-			})
+		chainblock, err := newChainBlock(block, txns, args.HeadersOnly)
+		if err != nil {
+			return newError(ErrCodeInternal, "%v", err)
 		}
 
-		chainresult.Blocks = append(chainresult.Blocks, ChainBlock{
-			Height:        uint64(block.BlockHeight),
-			Timestamp:     time.Unix(block.Timestamp, 0).Format(time.RFC3339),
-			BlockHash:     block.BlockHash.Hex(),
-			PrevBlockHash: block.Priori.Hex(),
-			Nonce:         uint64(block.Nonce),
-			Transactions:  transactions,
-			TxnCount:      block.TxnCount(),
-		})
+		chainresult.Blocks = append(chainresult.Blocks, chainblock)
+	}
+
+	// Set the cursor for the next page if the range is not exhausted
+	if height >= lower && height <= upper {
+		next := uint64(height)
+		chainresult.Next = &next
 	}
 
 	*result = chainresult
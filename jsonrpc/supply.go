@@ -0,0 +1,51 @@
+package jsonrpc
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+type GetSupplyArgs struct{}
+
+type GetSupplyResult struct {
+	ChainHeight       uint64        `json:"chain_height"`
+	CirculatingSupply common.Amount `json:"circulating_supply"`
+	MaxSupply         common.Amount `json:"max_supply"`
+
+	// Subsidy of the next block and the height at which the subsidy next halves
+	BlockSubsidy    common.Amount `json:"block_subsidy"`
+	NextHalving     uint64        `json:"next_halving"`
+	InitialReward   common.Amount `json:"initial_reward"`
+	HalvingInterval uint64        `json:"halving_interval"`
+}
+
+func (api *API) GetSupply(r *http.Request, args *GetSupplyArgs, result *GetSupplyResult) error {
+	log.Println("'GetSupply' Called")
+
+	params := api.chain.Params()
+	height := api.chain.Height()
+
+	supply, err := params.Supply(height)
+	if err != nil {
+		return newError(ErrCodeInternal, "failed to compute circulating supply: %v", err)
+	}
+
+	maxSupply, err := params.MaxSupply()
+	if err != nil {
+		return newError(ErrCodeInternal, "failed to compute maximum supply: %v", err)
+	}
+
+	*result = GetSupplyResult{
+		ChainHeight:       uint64(height),
+		CirculatingSupply: supply,
+		MaxSupply:         maxSupply,
+		BlockSubsidy:      params.Subsidy(height),
+		NextHalving:       uint64((height/params.HalvingInterval + 1) * params.HalvingInterval),
+		InitialReward:     params.InitialReward,
+		HalvingInterval:   uint64(params.HalvingInterval),
+	}
+
+	return nil
+}
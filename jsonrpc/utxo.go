@@ -0,0 +1,140 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+type TxInput struct {
+	TxnHash string `json:"txn_hash"`
+	Index   uint32 `json:"index"`
+}
+
+type TxOutput struct {
+	Address string        `json:"address"`
+	Value   common.Amount `json:"value"`
+}
+
+type GetUnspentOutputsArgs struct {
+	Address string `json:"address"`
+}
+
+type GetUnspentOutputsResult struct {
+	Address string          `json:"address"`
+	Outputs []UnspentOutput `json:"outputs"`
+}
+
+type UnspentOutput struct {
+	TxnHash string        `json:"txn_hash"`
+	Index   uint32        `json:"index"`
+	Value   common.Amount `json:"value"`
+}
+
+// Coins converts the outputs of the GetUnspentOutputsResult into core.Coins.
+// Returns an error if any of their transaction hashes are invalid.
+func (result GetUnspentOutputsResult) Coins() ([]core.Coin, error) {
+	coins := make([]core.Coin, 0, len(result.Outputs))
+	for _, output := range result.Outputs {
+		hash, err := common.HexToHash(output.TxnHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction hash '%v': %w", output.TxnHash, err)
+		}
+
+		coins = append(coins, core.Coin{
+			Outpoint: core.Outpoint{Hash: hash, Index: output.Index},
+			Output:   core.TxOutput{Value: output.Value, Address: common.Address(result.Address)},
+		})
+	}
+
+	return coins, nil
+}
+
+// NewTxInputs converts the inputs of a core.Transaction into TxInputs
+func NewTxInputs(inputs []core.TxInput) []TxInput {
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	txinputs := make([]TxInput, 0, len(inputs))
+	for _, input := range inputs {
+		txinputs = append(txinputs, TxInput{TxnHash: input.Previous.Hash.Hex(), Index: input.Previous.Index})
+	}
+
+	return txinputs
+}
+
+// NewTxOutputs converts the outputs of a core.Transaction into TxOutputs
+func NewTxOutputs(outputs []core.TxOutput) []TxOutput {
+	if len(outputs) == 0 {
+		return nil
+	}
+
+	txoutputs := make([]TxOutput, 0, len(outputs))
+	for _, output := range outputs {
+		txoutputs = append(txoutputs, TxOutput{Address: string(output.Address), Value: output.Value})
+	}
+
+	return txoutputs
+}
+
+// coreInputs converts the given TxInputs into the inputs of a core.Transaction.
+// Returns an error if any of their transaction hashes are invalid.
+func coreInputs(txinputs []TxInput) ([]core.TxInput, error) {
+	if len(txinputs) == 0 {
+		return nil, nil
+	}
+
+	inputs := make([]core.TxInput, 0, len(txinputs))
+	for idx, input := range txinputs {
+		hash, err := common.HexToHash(input.TxnHash)
+		if err != nil {
+			return nil, fmt.Errorf("input %v: invalid transaction hash '%v': %w", idx, input.TxnHash, err)
+		}
+
+		inputs = append(inputs, core.TxInput{Previous: core.Outpoint{Hash: hash, Index: input.Index}})
+	}
+
+	return inputs, nil
+}
+
+// coreOutputs converts the given TxOutputs into the outputs of a core.Transaction
+func coreOutputs(txoutputs []TxOutput) []core.TxOutput {
+	if len(txoutputs) == 0 {
+		return nil
+	}
+
+	outputs := make([]core.TxOutput, 0, len(txoutputs))
+	for _, output := range txoutputs {
+		outputs = append(outputs, core.TxOutput{Value: output.Value, Address: common.Address(output.Address)})
+	}
+
+	return outputs
+}
+
+func (api *API) GetUnspentOutputs(r *http.Request, args *GetUnspentOutputsArgs, result *GetUnspentOutputsResult) error {
+	log.Println("'GetUnspentOutputs' Called")
+
+	if args.Address == "" {
+		return invalidParams("missing address")
+	}
+
+	coins, err := api.chain.GetCoins(common.Address(args.Address))
+	if err != nil {
+		return newError(ErrCodeInternal, "failed to retrieve unspent outputs of '%v': %v", args.Address, err)
+	}
+
+	*result = GetUnspentOutputsResult{Address: args.Address, Outputs: make([]UnspentOutput, 0, len(coins))}
+	for _, coin := range coins {
+		result.Outputs = append(result.Outputs, UnspentOutput{
+			TxnHash: coin.Outpoint.Hash.Hex(),
+			Index:   coin.Outpoint.Index,
+			Value:   coin.Output.Value,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,394 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+)
+
+// Subscription kinds supported by the WebSocket endpoint
+const (
+	// SubNewHeads notifies the header of every new chain head
+	SubNewHeads = "new_heads"
+	// SubPendingTransactions notifies every transaction accepted for mining
+	SubPendingTransactions = "pending_transactions"
+	// SubReorgs notifies every replacement of the chain head by a non-descendant.
+	// Blocks only extend the chain head until the node can switch forks, so it is not notified yet.
+	SubReorgs = "reorgs"
+	// SubAddressActivity notifies the transactions in new blocks that involve an address
+	SubAddressActivity = "address_activity"
+)
+
+const (
+	// wsEventBuffer is the number of chain events that can be queued for a connection
+	wsEventBuffer = 256
+	// wsOutboundBuffer is the number of messages that can be queued for writing to a connection
+	wsOutboundBuffer = 256
+
+	// wsWriteWait is the time allowed to write a message to the peer
+	wsWriteWait = 10 * time.Second
+	// wsPongWait is the time allowed to read the next pong message from the peer
+	wsPongWait = 60 * time.Second
+	// wsPingPeriod is the period at which pings are sent to the peer. Must be less than wsPongWait.
+	wsPingPeriod = (wsPongWait * 9) / 10
+)
+
+// WSRequestParams are the params of a JSON-RPC request sent by a WebSocket client.
+// Kind and Address are used by 'subscribe' and Subscription by 'unsubscribe'.
+type WSRequestParams struct {
+	Kind         string `json:"kind,omitempty"`
+	Address      string `json:"address,omitempty"`
+	Subscription string `json:"subscription,omitempty"`
+}
+
+// WSNotification is a JSON-RPC notification pushed to a WebSocket client.
+// Its method is always 'subscription' and its params identify the subscription.
+type WSNotification struct {
+	JSONRPC string               `json:"jsonrpc"`
+	Method  string               `json:"method"`
+	Params  WSNotificationParams `json:"params"`
+}
+
+// WSNotificationParams are the params of a WSNotification
+type WSNotificationParams struct {
+	Subscription string `json:"subscription"`
+	Kind         string `json:"kind"`
+	Result       any    `json:"result"`
+}
+
+// newWSNotification returns a WSNotification for the given subscription
+func newWSNotification(id, kind string, result any) WSNotification {
+	return WSNotification{Version, "subscription", WSNotificationParams{id, kind, result}}
+}
+
+// AddressActivity is the result of a SubAddressActivity notification
+type AddressActivity struct {
+	Address      string             `json:"address"`
+	BlockHash    string             `json:"block_hash"`
+	BlockHeight  uint64             `json:"block_height"`
+	Transactions []BlockTransaction `json:"transactions"`
+}
+
+// Reorg is the result of a SubReorgs notification
+type Reorg struct {
+	OldHead string `json:"old_head"`
+	NewHead string `json:"new_head"`
+}
+
+// WebSocketServer serves subscriptions to chain events over WebSocket connections
+type WebSocketServer struct {
+	api      *API
+	upgrader websocket.Upgrader
+
+	// Represents the open connections of the server
+	mutex  sync.Mutex
+	conns  map[*wsConn]struct{}
+	closed bool
+}
+
+// NewWebSocketServer returns a new WebSocketServer for the given API
+func NewWebSocketServer(api *API) *WebSocketServer {
+	return &WebSocketServer{api: api, conns: make(map[*wsConn]struct{})}
+}
+
+// Close closes all open connections and rejects any new connections.
+// WebSocket connections are hijacked from the HTTP server
+// and must be closed separately when it shuts down.
+func (server *WebSocketServer) Close() {
+	server.mutex.Lock()
+	conns := server.conns
+	server.conns, server.closed = make(map[*wsConn]struct{}), true
+	server.mutex.Unlock()
+
+	for wsconn := range conns {
+		wsconn.close("server shutting down")
+	}
+}
+
+// ServeHTTP implements the http.Handler interface for WebSocketServer.
+// It upgrades the request to a WebSocket connection and serves it until it is closed.
+func (server *WebSocketServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mutex.Lock()
+	closed := server.closed
+	server.mutex.Unlock()
+
+	if closed {
+		http.Error(w, "server shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := server.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println("WebSocket Upgrade Failed:", err)
+		return
+	}
+
+	wsconn := &wsConn{
+		conn:     conn,
+		chain:    server.api.chain.Subscribe(wsEventBuffer),
+		outbound: make(chan any, wsOutboundBuffer),
+		done:     make(chan struct{}),
+		subs:     make(map[string]wsSubscription),
+	}
+
+	// Track the connection until it is closed
+	server.mutex.Lock()
+	if server.closed {
+		server.mutex.Unlock()
+		wsconn.close("server shutting down")
+		return
+	}
+	server.conns[wsconn] = struct{}{}
+	server.mutex.Unlock()
+
+	defer func() {
+		server.mutex.Lock()
+		delete(server.conns, wsconn)
+		server.mutex.Unlock()
+	}()
+
+	go wsconn.writeLoop()
+	go wsconn.eventLoop()
+	wsconn.readLoop()
+}
+
+// wsSubscription is a subscription made on a WebSocket connection
+type wsSubscription struct {
+	kind    string
+	address common.Address
+}
+
+// wsConn is a WebSocket connection with its subscriptions
+type wsConn struct {
+	conn  *websocket.Conn
+	chain *chainmgr.Subscription
+
+	// Represents the messages waiting to be written to the connection
+	outbound chan any
+	// Represents the signal that the connection is closed
+	done chan struct{}
+	once sync.Once
+
+	mutex  sync.Mutex
+	subs   map[string]wsSubscription
+	nextID uint64
+}
+
+// close closes the connection and ends its chain subscription.
+// The reason is sent to the client as a close message.
+func (wsconn *wsConn) close(reason string) {
+	wsconn.once.Do(func() {
+		close(wsconn.done)
+		wsconn.chain.Unsubscribe()
+
+		message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason)
+		_ = wsconn.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(wsWriteWait))
+		_ = wsconn.conn.Close()
+	})
+}
+
+// send queues a message for writing without blocking.
+// The connection is closed if the client is not keeping up with its messages.
+func (wsconn *wsConn) send(message any) {
+	select {
+	case wsconn.outbound <- message:
+	case <-wsconn.done:
+	default:
+		wsconn.close("subscriber lagging")
+	}
+}
+
+// readLoop reads and handles requests from the connection until it is closed
+func (wsconn *wsConn) readLoop() {
+	defer wsconn.close("")
+
+	wsconn.conn.SetReadLimit(1 << 16)
+	_ = wsconn.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	wsconn.conn.SetPongHandler(func(string) error {
+		return wsconn.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, message, err := wsconn.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var request Request
+		if err := json.Unmarshal(message, &request); err != nil {
+			wsconn.send(errorResponse(nil, newError(ErrCodeParse, "parse error: %v", err)))
+			continue
+		}
+
+		if request.JSONRPC != Version || request.Method == "" {
+			wsconn.send(errorResponse(request.ID, newError(ErrCodeInvalidRequest, "invalid request: must have jsonrpc '%v' and a method", Version)))
+			continue
+		}
+
+		result, rpcErr := wsconn.handle(&request)
+		if request.IsNotification() {
+			continue
+		}
+
+		if rpcErr != nil {
+			wsconn.send(errorResponse(request.ID, rpcErr))
+			continue
+		}
+
+		wsconn.send(&Response{JSONRPC: Version, Result: result, ID: request.ID})
+	}
+}
+
+// handle performs a request from the client and returns its result
+func (wsconn *wsConn) handle(request *Request) (any, *Error) {
+	wsconn.mutex.Lock()
+	defer wsconn.mutex.Unlock()
+
+	var params WSRequestParams
+	if len(request.Params) > 0 {
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, invalidParams("invalid params: %v", err)
+		}
+	}
+
+	switch request.Method {
+	case "subscribe":
+		sub := wsSubscription{kind: params.Kind}
+
+		switch params.Kind {
+		case SubNewHeads, SubPendingTransactions, SubReorgs:
+		case SubAddressActivity:
+			if params.Address == "" {
+				return nil, invalidParams("missing address for '%v' subscription", SubAddressActivity)
+			}
+
+			sub.address = common.Address(params.Address)
+		default:
+			return nil, invalidParams("unknown subscription kind '%v'", params.Kind)
+		}
+
+		wsconn.nextID++
+		id := fmt.Sprintf("0x%x", wsconn.nextID)
+		wsconn.subs[id] = sub
+
+		return id, nil
+
+	case "unsubscribe":
+		if _, ok := wsconn.subs[params.Subscription]; !ok {
+			return nil, newError(ErrCodeNotFound, "subscription '%v' not found", params.Subscription)
+		}
+
+		delete(wsconn.subs, params.Subscription)
+		return true, nil
+
+	default:
+		return nil, newError(ErrCodeMethodNotFound, "method '%v' not found", request.Method)
+	}
+}
+
+// writeLoop writes queued messages and periodic pings to the connection until it is closed
+func (wsconn *wsConn) writeLoop() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case message := <-wsconn.outbound:
+			_ = wsconn.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := wsconn.conn.WriteJSON(message); err != nil {
+				wsconn.close("")
+				return
+			}
+
+		case <-ticker.C:
+			if err := wsconn.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				wsconn.close("")
+				return
+			}
+
+		case <-wsconn.done:
+			return
+		}
+	}
+}
+
+// eventLoop converts chain events into notifications for the
+// subscriptions on the connection until the chain subscription ends
+func (wsconn *wsConn) eventLoop() {
+	for event := range wsconn.chain.Events() {
+		for _, notification := range wsconn.notifications(event) {
+			wsconn.send(notification)
+		}
+	}
+
+	// The chain subscription ends either when the connection
+	// is closed, or when it has been dropped for lagging behind
+	wsconn.close("subscriber lagging")
+}
+
+// notifications returns the notifications for a chain event
+// that match the subscriptions on the connection
+func (wsconn *wsConn) notifications(event chainmgr.Event) []WSNotification {
+	wsconn.mutex.Lock()
+	defer wsconn.mutex.Unlock()
+
+	notifications := make([]WSNotification, 0)
+	for id, sub := range wsconn.subs {
+		switch event := event.(type) {
+		case chainmgr.NewHeadEvent:
+			switch sub.kind {
+			case SubNewHeads:
+				notifications = append(notifications, newWSNotification(id, sub.kind, newChainHeader(event.Block.Header())))
+
+			case SubAddressActivity:
+				txns := filterTransactions(event.Block.BlockTxns, sub.address)
+				if len(txns) == 0 {
+					continue
+				}
+
+				activity := AddressActivity{
+					Address:      string(sub.address),
+					BlockHash:    event.Block.BlockHash.Hex(),
+					BlockHeight:  uint64(event.Block.BlockHeight),
+					Transactions: make([]BlockTransaction, 0, len(txns)),
+				}
+
+				for _, txn := range txns {
+					if transaction, err := NewBlockTransaction(txn); err == nil {
+						activity.Transactions = append(activity.Transactions, transaction)
+					}
+				}
+
+				notifications = append(notifications, newWSNotification(id, sub.kind, activity))
+			}
+
+		case chainmgr.PendingTxnsEvent:
+			if sub.kind != SubPendingTransactions {
+				continue
+			}
+
+			for _, txn := range event.Txns {
+				if transaction, err := NewBlockTransaction(txn); err == nil {
+					notifications = append(notifications, newWSNotification(id, sub.kind, transaction))
+				}
+			}
+
+		case chainmgr.ReorgEvent:
+			if sub.kind != SubReorgs {
+				continue
+			}
+
+			notifications = append(notifications, newWSNotification(id, sub.kind, Reorg{event.OldHead.Hex(), event.NewHead.Hex()}))
+		}
+	}
+
+	return notifications
+}
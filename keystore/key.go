@@ -0,0 +1,207 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+const (
+	// Version is the version of the encrypted key file format
+	Version = 1
+
+	// StandardScryptN is the N parameter of scrypt for keys at rest.
+	// It uses 256MB of memory and about a second of CPU time to derive a key.
+	StandardScryptN = 1 << 18
+	// StandardScryptP is the P parameter of scrypt for keys at rest
+	StandardScryptP = 1
+
+	// LightScryptN is the N parameter of scrypt for keys on constrained devices.
+	// It uses 4MB of memory and about 100ms of CPU time to derive a key.
+	LightScryptN = 1 << 12
+	// LightScryptP is the P parameter of scrypt for keys on constrained devices
+	LightScryptP = 6
+
+	scryptR     = 8
+	scryptDKLen = 32
+	saltLength  = 32
+
+	cipherName = "aes-256-gcm"
+	kdfName    = "scrypt"
+)
+
+// ErrDecrypt is returned when a key cannot be decrypted with the given password
+var ErrDecrypt = errors.New("could not decrypt key with the given password")
+
+// keyFile is the JSON encoding of an encrypted key
+type keyFile struct {
+	// Represents the address of the key
+	Address common.Address `json:"address"`
+	// Represents the encrypted key and the parameters to decrypt it
+	Crypto cryptoParams `json:"crypto"`
+	// Represents the version of the key file format
+	Version int `json:"version"`
+}
+
+// cryptoParams contains an encrypted key and the parameters to decrypt it.
+// All binary values are hex encoded.
+type cryptoParams struct {
+	Cipher     string       `json:"cipher"`
+	CipherText string       `json:"ciphertext"`
+	Nonce      string       `json:"nonce"`
+	KDF        string       `json:"kdf"`
+	KDFParams  scryptParams `json:"kdfparams"`
+}
+
+// scryptParams are the parameters of the scrypt key derivation
+type scryptParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+// validate returns an error if the scryptParams are not the parameters of a key encrypted by a Keystore. A key file
+// is imported from elsewhere, so its parameters are checked before deriving a key with them: N must be a power of two
+// of at most StandardScryptN, r must be 8 and dklen must be 32, and the memory and CPU time of the derivation must not
+// exceed that of StandardScryptN and StandardScryptP. Only a smaller N allows a larger p, as with LightScryptP.
+func (params scryptParams) validate() error {
+	if params.N <= 1 || params.N > StandardScryptN || params.N&(params.N-1) != 0 {
+		return fmt.Errorf("invalid scrypt n %v: must be a power of two of at most %v", params.N, StandardScryptN)
+	}
+
+	if params.R != scryptR {
+		return fmt.Errorf("invalid scrypt r %v: must be %v", params.R, scryptR)
+	}
+
+	if params.P < 1 || params.N*params.P > StandardScryptN*StandardScryptP {
+		return fmt.Errorf("invalid scrypt p %v: n*p must be at most %v", params.P, StandardScryptN*StandardScryptP)
+	}
+
+	if params.DKLen != scryptDKLen {
+		return fmt.Errorf("invalid scrypt dklen %v: must be %v", params.DKLen, scryptDKLen)
+	}
+
+	return nil
+}
+
+// encryptKey encrypts the seed of the given private key with an AES-256-GCM key derived from the password
+// with scrypt. The address of the key is authenticated with the ciphertext so it cannot be altered.
+func encryptKey(key ed25519.PrivateKey, password string, scryptN, scryptP int) (*keyFile, error) {
+	address := common.PublicKeyToAddress(key.Public().(ed25519.PublicKey))
+
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("salt generation failed: %w", err)
+	}
+
+	derived, err := scrypt.Key([]byte(password), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, fmt.Errorf("key derivation failed: %w", err)
+	}
+
+	aead, err := newAEAD(derived)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("nonce generation failed: %w", err)
+	}
+
+	ciphertext := aead.Seal(nil, nonce, key.Seed(), address.Bytes())
+
+	return &keyFile{
+		Address: address,
+		Crypto: cryptoParams{
+			Cipher:     cipherName,
+			CipherText: common.HexEncode(ciphertext),
+			Nonce:      common.HexEncode(nonce),
+			KDF:        kdfName,
+			KDFParams: scryptParams{
+				N: scryptN, R: scryptR, P: scryptP,
+				DKLen: scryptDKLen,
+				Salt:  common.HexEncode(salt),
+			},
+		},
+		Version: Version,
+	}, nil
+}
+
+// decryptKey decrypts the private key of the key file with the given password.
+// Returns ErrDecrypt if the password is wrong or the key file has been altered,
+// and an error without deriving a key if its scrypt parameters are too costly.
+func decryptKey(file *keyFile, password string) (ed25519.PrivateKey, error) {
+	if file.Version != Version {
+		return nil, fmt.Errorf("unsupported key file version %v", file.Version)
+	}
+
+	if file.Crypto.Cipher != cipherName || file.Crypto.KDF != kdfName {
+		return nil, fmt.Errorf("unsupported cipher '%v' or kdf '%v'", file.Crypto.Cipher, file.Crypto.KDF)
+	}
+
+	params := file.Crypto.KDFParams
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	salt, err := common.HexDecode(params.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+
+	nonce, err := common.HexDecode(file.Crypto.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+
+	ciphertext, err := common.HexDecode(file.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+
+	derived, err := scrypt.Key([]byte(password), salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return nil, fmt.Errorf("key derivation failed: %w", err)
+	}
+
+	aead, err := newAEAD(derived)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length %v", len(nonce))
+	}
+
+	seed, err := aead.Open(nil, nonce, ciphertext, file.Address.Bytes())
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, ErrDecrypt
+	}
+
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// newAEAD returns an AES-GCM cipher for the given derived key
+func newAEAD(derived []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, fmt.Errorf("cipher creation failed: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("cipher creation failed: %w", err)
+	}
+
+	return aead, nil
+}
@@ -0,0 +1,432 @@
+package keystore
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+const keystoreFolder = "keystore"
+
+var (
+	// ErrAccountNotFound is returned when an address does not have a key in the Keystore
+	ErrAccountNotFound = errors.New("account not found in keystore")
+	// ErrAccountExists is returned when importing a key that is already in the Keystore
+	ErrAccountExists = errors.New("account already exists in keystore")
+	// ErrLocked is returned when signing with a key that has not been unlocked
+	ErrLocked = errors.New("account is locked")
+)
+
+// Keystore is a directory of encrypted keys, each stored as a JSON file named after its address.
+// Keys are only decrypted to sign transactions, either with a password for a single signature
+// or by unlocking them for a period of time. Raw private keys never leave the Keystore.
+type Keystore struct {
+	// Represents the directory of the key files
+	dir string
+	// Represents the scrypt parameters for encrypting keys
+	scryptN, scryptP int
+
+	// Represents the keys that are currently unlocked
+	mutex    sync.Mutex
+	unlocked map[common.Address]*unlockedKey
+}
+
+// unlockedKey is a decrypted key that is held in memory until it expires or is locked
+type unlockedKey struct {
+	key ed25519.PrivateKey
+	// Represents the time at which the key is locked. The zero time never expires.
+	expires time.Time
+	timer   *time.Timer
+}
+
+// New opens the Keystore at the given directory, creating the directory if it does not exist.
+// Keys are encrypted with the given scrypt parameters (see StandardScryptN and LightScryptN).
+func New(dir string, scryptN, scryptP int) (*Keystore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("keystore open fail: %w", err)
+	}
+
+	return &Keystore{
+		dir:      dir,
+		scryptN:  scryptN,
+		scryptP:  scryptP,
+		unlocked: make(map[common.Address]*unlockedKey),
+	}, nil
+}
+
+// path returns the path of the key file for the given address.
+// Returns ErrAccountNotFound if the address is not a valid key address.
+func (keystore *Keystore) path(address common.Address) (string, error) {
+	data, err := common.HexDecode(string(address))
+	if err != nil || len(data) != common.AddressLength {
+		return "", fmt.Errorf("%w: %v", ErrAccountNotFound, address)
+	}
+
+	return filepath.Join(keystore.dir, strings.ToLower(string(address))+".json"), nil
+}
+
+// load reads the key file for the given address
+func (keystore *Keystore) load(address common.Address) (*keyFile, error) {
+	path, err := keystore.path(address)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %v", ErrAccountNotFound, address)
+		}
+
+		return nil, fmt.Errorf("key file read failed: %w", err)
+	}
+
+	file := new(keyFile)
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("key file '%v' decode failed: %w", path, err)
+	}
+
+	return file, nil
+}
+
+// store encrypts the key with the password and writes its key file.
+// The file is written atomically so that a key is never partially written.
+func (keystore *Keystore) store(key ed25519.PrivateKey, password string) (common.Address, error) {
+	file, err := encryptKey(key, password, keystore.scryptN, keystore.scryptP)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("key file encode failed: %w", err)
+	}
+
+	path, err := keystore.path(file.Address)
+	if err != nil {
+		return "", err
+	}
+
+	temp, err := os.CreateTemp(keystore.dir, ".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("key file write failed: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		_ = temp.Close()
+		return "", fmt.Errorf("key file write failed: %w", err)
+	}
+
+	if err := temp.Close(); err != nil {
+		return "", fmt.Errorf("key file write failed: %w", err)
+	}
+
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return "", fmt.Errorf("key file write failed: %w", err)
+	}
+
+	return file.Address, nil
+}
+
+// decrypt returns the decrypted key for the given address
+func (keystore *Keystore) decrypt(address common.Address, password string) (ed25519.PrivateKey, error) {
+	file, err := keystore.load(address)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := decryptKey(file, password)
+	if err != nil {
+		return nil, err
+	}
+
+	// The key must belong to the address of its file
+	if common.PublicKeyToAddress(key.Public().(ed25519.PublicKey)) != file.Address {
+		return nil, fmt.Errorf("key file for '%v' contains the key of another address", address)
+	}
+
+	return key, nil
+}
+
+// Create generates a new key, stores it encrypted with the given password and returns its address
+func (keystore *Keystore) Create(password string) (common.Address, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("key generation failed: %w", err)
+	}
+
+	return keystore.store(key, password)
+}
+
+// Import adds the key from the given key file, which is decrypted with the given password,
+// and stores it encrypted with the new password. Returns the address of the key.
+func (keystore *Keystore) Import(keyJSON []byte, password, newPassword string) (common.Address, error) {
+	file := new(keyFile)
+	if err := json.Unmarshal(keyJSON, file); err != nil {
+		return "", fmt.Errorf("key file decode failed: %w", err)
+	}
+
+	key, err := decryptKey(file, password)
+	if err != nil {
+		return "", err
+	}
+
+	address := common.PublicKeyToAddress(key.Public().(ed25519.PublicKey))
+	if keystore.Has(address) {
+		return "", fmt.Errorf("%w: %v", ErrAccountExists, address)
+	}
+
+	return keystore.store(key, newPassword)
+}
+
+// ImportKey stores the given private key encrypted with the password and returns its address
+func (keystore *Keystore) ImportKey(key ed25519.PrivateKey, password string) (common.Address, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return "", fmt.Errorf("invalid private key length %v", len(key))
+	}
+
+	address := common.PublicKeyToAddress(key.Public().(ed25519.PublicKey))
+	if keystore.Has(address) {
+		return "", fmt.Errorf("%w: %v", ErrAccountExists, address)
+	}
+
+	return keystore.store(key, password)
+}
+
+// Export returns the key file of the given address, re-encrypted with the new password
+func (keystore *Keystore) Export(address common.Address, password, newPassword string) ([]byte, error) {
+	key, err := keystore.decrypt(address, password)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := encryptKey(key, newPassword, keystore.scryptN, keystore.scryptP)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// Update changes the password of the key for the given address
+func (keystore *Keystore) Update(address common.Address, password, newPassword string) error {
+	key, err := keystore.decrypt(address, password)
+	if err != nil {
+		return err
+	}
+
+	_, err = keystore.store(key, newPassword)
+	return err
+}
+
+// PublicKey returns the public key of the key for the given address, which is decrypted with the given password.
+// The key files only store the address of their key, which is the hash of the public key.
+func (keystore *Keystore) PublicKey(address common.Address, password string) (ed25519.PublicKey, error) {
+	key, err := keystore.decrypt(address, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return key.Public().(ed25519.PublicKey), nil
+}
+
+// Has returns whether the Keystore has a key for the given address
+func (keystore *Keystore) Has(address common.Address) bool {
+	_, err := keystore.load(address)
+	return err == nil
+}
+
+// Accounts returns the addresses of all the keys in the Keystore in lexicographic order
+func (keystore *Keystore) Accounts() ([]common.Address, error) {
+	return ListAccounts(keystore.dir)
+}
+
+// ListAccounts returns the addresses of all the keys in the keystore directory in lexicographic
+// order, without opening the Keystore. A directory that does not exist has no keys.
+func ListAccounts(dir string) ([]common.Address, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("keystore list failed: %w", err)
+	}
+
+	addresses := make([]common.Address, 0, len(files))
+	for _, file := range files {
+		address := common.Address(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if data, err := common.HexDecode(string(address)); err == nil && len(data) == common.AddressLength {
+			addresses = append(addresses, address)
+		}
+	}
+
+	sort.Slice(addresses, func(i, j int) bool { return addresses[i] < addresses[j] })
+	return addresses, nil
+}
+
+// Unlock decrypts the key for the given address and holds it in memory for the given duration,
+// after which it is locked again. A duration of 0 keeps the key unlocked until it is locked.
+// Unlocking a key that is already unlocked replaces its duration.
+func (keystore *Keystore) Unlock(address common.Address, password string, duration time.Duration) error {
+	key, err := keystore.decrypt(address, password)
+	if err != nil {
+		return err
+	}
+
+	keystore.mutex.Lock()
+	defer keystore.mutex.Unlock()
+
+	keystore.lock(address)
+
+	unlocked := &unlockedKey{key: key}
+	if duration > 0 {
+		unlocked.expires = time.Now().Add(duration)
+		unlocked.timer = time.AfterFunc(duration, func() {
+			keystore.mutex.Lock()
+			defer keystore.mutex.Unlock()
+
+			// Only lock the key if it has not been unlocked again since
+			if keystore.unlocked[address] == unlocked {
+				keystore.lock(address)
+			}
+		})
+	}
+
+	keystore.unlocked[address] = unlocked
+	return nil
+}
+
+// Lock removes the decrypted key for the given address from memory
+func (keystore *Keystore) Lock(address common.Address) {
+	keystore.mutex.Lock()
+	defer keystore.mutex.Unlock()
+
+	keystore.lock(address)
+}
+
+// LockAll removes all decrypted keys from memory
+func (keystore *Keystore) LockAll() {
+	keystore.mutex.Lock()
+	defer keystore.mutex.Unlock()
+
+	for address := range keystore.unlocked {
+		keystore.lock(address)
+	}
+}
+
+// lock removes the decrypted key for the given address from memory. The mutex must be held.
+func (keystore *Keystore) lock(address common.Address) {
+	unlocked, ok := keystore.unlocked[address]
+	if !ok {
+		return
+	}
+
+	if unlocked.timer != nil {
+		unlocked.timer.Stop()
+	}
+
+	// Zero the key so that it does not linger in memory
+	for idx := range unlocked.key {
+		unlocked.key[idx] = 0
+	}
+
+	delete(keystore.unlocked, address)
+}
+
+// Unlocked returns whether the key for the given address is unlocked and the time at
+// which it will be locked. The zero time is returned for keys that do not expire.
+func (keystore *Keystore) Unlocked(address common.Address) (bool, time.Time) {
+	keystore.mutex.Lock()
+	defer keystore.mutex.Unlock()
+
+	unlocked, ok := keystore.unlocked[address]
+	if !ok {
+		return false, time.Time{}
+	}
+
+	return true, unlocked.expires
+}
+
+// SignTransaction signs the Transaction with the unlocked key of its sender.
+// Returns ErrLocked if the key of the sender is not unlocked.
+func (keystore *Keystore) SignTransaction(txn *core.Transaction) error {
+	keystore.mutex.Lock()
+	defer keystore.mutex.Unlock()
+
+	unlocked, ok := keystore.unlocked[txn.From]
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrLocked, txn.From)
+	}
+
+	return txn.Sign(unlocked.key)
+}
+
+// SignTransactionWithPassword signs the Transaction with the key of its sender, which is
+// decrypted with the given password for this signature only and is not unlocked.
+func (keystore *Keystore) SignTransactionWithPassword(txn *core.Transaction, password string) error {
+	key, err := keystore.decrypt(txn.From, password)
+	if err != nil {
+		return err
+	}
+
+	return txn.Sign(key)
+}
+
+// SignScriptWithPassword returns the signature of the key for the given address over the Transaction, for a
+// lock script that checks it (see core.Transaction.SignScript). The key is decrypted with the given password
+// for this signature only and is not unlocked.
+func (keystore *Keystore) SignScriptWithPassword(txn *core.Transaction, address common.Address, password string) ([]byte, error) {
+	key, err := keystore.decrypt(address, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return txn.SignScript(key)
+}
+
+// SignPartialTransaction signs the core.PartialTransaction with the unlocked key for the given address,
+// which must be a participant of its multisig account. Returns ErrLocked if the key is not unlocked.
+func (keystore *Keystore) SignPartialTransaction(partial *core.PartialTransaction, address common.Address) error {
+	keystore.mutex.Lock()
+	defer keystore.mutex.Unlock()
+
+	unlocked, ok := keystore.unlocked[address]
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrLocked, address)
+	}
+
+	return partial.Sign(unlocked.key)
+}
+
+// SignPartialTransactionWithPassword signs the core.PartialTransaction with the key for the given address,
+// which is decrypted with the given password for this signature only and is not unlocked.
+func (keystore *Keystore) SignPartialTransactionWithPassword(partial *core.PartialTransaction, address common.Address, password string) error {
+	key, err := keystore.decrypt(address, password)
+	if err != nil {
+		return err
+	}
+
+	return partial.Sign(key)
+}
+
+// Dir returns the path to the default directory of the Keystore.
+// It is always in the same directory as the running binary.
+func Dir() string {
+	// Get path to executable
+	executable, err := os.Executable()
+	if err != nil {
+		panic(fmt.Errorf("keystore directory detection failure: exec path detection failure: %w", err))
+	}
+
+	// Add keystoreFolder to the directory of the executable
+	return filepath.Join(filepath.Dir(executable), keystoreFolder)
+}
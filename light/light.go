@@ -0,0 +1,246 @@
+package light
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/manishmeganathan/essensio/client"
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+	"github.com/manishmeganathan/essensio/core/state"
+	"github.com/manishmeganathan/essensio/jsonrpc"
+)
+
+var (
+	// ErrDiverged is returned when the headers of a light chain are not on the chain of its full node
+	ErrDiverged = errors.New("light chain diverged from the full node")
+	// ErrWorkMismatch is returned when the chain work reported by a full node does not match its headers
+	ErrWorkMismatch = errors.New("chain work does not match the full node")
+	// ErrHashMismatch is returned when a full node returns a transaction with a different hash than requested
+	ErrHashMismatch = errors.New("transaction hash does not match")
+)
+
+// Payment is a Transaction whose inclusion on the chain has been verified by a light node
+type Payment struct {
+	// Represents the verified Transaction
+	Transaction *core.Transaction
+	// Represents the Header of the Block that includes the Transaction
+	Header *core.Header
+	// Represents the number of Blocks on the chain from the Block of the Transaction to the chain head
+	Confirmations int64
+}
+
+// AccountState is the state of an address whose proof against the chain state has been verified by a light node
+type AccountState struct {
+	// Represents the verified Account of the address
+	Account *state.Account
+	// Represents the Header of the Block whose state root proves the Account
+	Header *core.Header
+}
+
+// Sync downloads the headers from the chain head of the HeaderChain to the chain head of the full node of the
+// client and adds them to the HeaderChain, which validates each of them against its trusted Genesis Block.
+// The full node must report the trusted genesis hash, and the cumulative work of the chain must match the
+// total work reported by the full node. Returns the number of headers that were added.
+func Sync(ctx context.Context, chain *chainmgr.HeaderChain, node *client.Client) (int, error) {
+	info, err := node.GetChainInfo(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("chain info retrieve failed: %w", err)
+	}
+
+	if info.ChainID != chain.ChainID() {
+		return 0, fmt.Errorf("%w: expected %v, got %v", core.ErrWrongChain, chain.ChainID(), info.ChainID)
+	}
+
+	// A full node on another chain is rejected before any headers are downloaded
+	if info.GenesisHash != chain.Genesis().Hex() {
+		return 0, fmt.Errorf("%w: expected %v, full node has %v", chainmgr.ErrWrongGenesis, chain.Genesis().Hex(), info.GenesisHash)
+	}
+
+	// The chain head must be on the chain of the full node
+	if lightHead, lightHeight := chain.Tip(); lightHeight > 0 {
+		if lightHeight > int64(info.ChainHeight) {
+			return 0, fmt.Errorf("%w: full node is at height %v, light chain is at height %v", ErrDiverged, info.ChainHeight, lightHeight)
+		}
+
+		height := uint64(lightHeight - 1)
+		remote, err := node.GetBlockByHeight(ctx, &jsonrpc.GetBlockByHeightArgs{Height: &height, HeadersOnly: true})
+		if err != nil {
+			return 0, fmt.Errorf("header %v retrieve failed: %w", height, err)
+		}
+
+		if remote.BlockHash != lightHead.Hex() {
+			return 0, fmt.Errorf("%w: block %v is %v on the full node", ErrDiverged, height, remote.BlockHash)
+		}
+	}
+
+	added := 0
+	for chain.Height() < int64(info.ChainHeight) {
+		start := uint64(chain.Height())
+		page, err := node.ShowChain(ctx, &jsonrpc.ShowChainArgs{
+			Start: &start, Limit: jsonrpc.MaxPageLimit, Direction: jsonrpc.DirectionAsc, HeadersOnly: true,
+		})
+		if err != nil {
+			return added, fmt.Errorf("headers from %v retrieve failed: %w", start, err)
+		}
+
+		if len(page.Blocks) == 0 {
+			break
+		}
+
+		for _, block := range page.Blocks {
+			header, err := block.Header()
+			if err != nil {
+				return added, fmt.Errorf("header %v: %w", block.Height, err)
+			}
+
+			if err := chain.AddHeader(header); err != nil {
+				return added, fmt.Errorf("header %v: %w", block.Height, err)
+			}
+
+			added++
+		}
+	}
+
+	// The work of the chain up to the chain head reported by the full node must match the total
+	// work reported by the full node. The headers themselves are secured by the trusted Genesis
+	// Block, whose target every header must meet, so this only checks the node's consistency.
+	head, err := common.HexToHash(info.ChainHead)
+	if err != nil {
+		return added, fmt.Errorf("invalid chain head '%v': %w", info.ChainHead, err)
+	}
+
+	work, err := chain.GetChainWork(head)
+	if err != nil {
+		return added, err
+	}
+
+	reported, err := common.HexDecode(info.TotalWork)
+	if err != nil {
+		return added, fmt.Errorf("invalid total work '%v': %w", info.TotalWork, err)
+	}
+
+	if new(big.Int).SetBytes(reported).Cmp(work) != 0 {
+		return added, fmt.Errorf("%w: expected %v, got %v", ErrWorkMismatch, work, info.TotalWork)
+	}
+
+	return added, nil
+}
+
+// Follow syncs the HeaderChain with the full node of the client and then syncs
+// it again whenever the full node has a new chain head, until the context is cancelled.
+// The number of headers that are added by each sync is reported to the given function.
+func Follow(ctx context.Context, chain *chainmgr.HeaderChain, node *client.Client, report func(int)) error {
+	heads := make(chan jsonrpc.ChainBlock, 1)
+	sub, err := node.SubscribeNewHeads(ctx, heads)
+	if err != nil {
+		return fmt.Errorf("new heads subscribe failed: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		added, err := Sync(ctx, chain, node)
+		if err != nil {
+			return err
+		}
+
+		report(added)
+
+		select {
+		case <-heads:
+		case err := <-sub.Err():
+			return fmt.Errorf("new heads subscription failed: %w", err)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// VerifyTransaction retrieves the Transaction with the given hash and its Merkle proof from the full node of the
+// client and verifies that it is included in a Block on the HeaderChain. The signature or lock script of its sender
+// is also verified, but not whether its sender could afford it, which requires the chain state.
+// Returns chainmgr.ErrBlockNotFound if the Block of the Transaction is not on the HeaderChain, which must be synced first.
+func VerifyTransaction(ctx context.Context, chain *chainmgr.HeaderChain, node *client.Client, hash common.Hash) (*Payment, error) {
+	result, err := node.GetTransactionProof(ctx, &jsonrpc.GetTransactionProofArgs{Hash: hash.Hex()})
+	if err != nil {
+		return nil, fmt.Errorf("transaction proof retrieve failed: %w", err)
+	}
+
+	txn, err := result.Transaction()
+	if err != nil {
+		return nil, err
+	}
+
+	// The transaction must be the requested one, which also checks its fields against its hash
+	if txnhash, err := txn.Hash(); err != nil {
+		return nil, fmt.Errorf("transaction hash failed: %w", err)
+	} else if txnhash != hash {
+		return nil, fmt.Errorf("%w: expected %v, got %v", ErrHashMismatch, hash.Hex(), txnhash.Hex())
+	}
+
+	block, err := common.HexToHash(result.BlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block hash '%v': %w", result.BlockHash, err)
+	}
+
+	proof, err := result.MerkleProof()
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := chain.VerifyTransaction(txn, block, proof)
+	if err != nil {
+		return nil, err
+	}
+
+	// Coinbase transactions are not signed, and the lock script of other senders is executed at the height of the block
+	if !txn.IsCoinbase() {
+		if err := txn.VerifySender(header.BlockHeight); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Payment{txn, header, chain.Height() - header.BlockHeight}, nil
+}
+
+// VerifyAccount retrieves the state of the given address after the Block at the given height, or after the chain head
+// if the height is nil, and its proof against the state root of that Block from the full node of the client and verifies
+// it against the Header of the Block on the HeaderChain. The HeaderChain is synced with the full node if it does not have
+// the Block yet. The Account is only proven as of that Block.
+func VerifyAccount(ctx context.Context, chain *chainmgr.HeaderChain, node *client.Client, address common.Address, height *uint64) (*AccountState, error) {
+	result, err := node.GetAccountProof(ctx, &jsonrpc.AccountArgs{Address: string(address), BlockHeight: height})
+	if err != nil {
+		return nil, fmt.Errorf("account proof retrieve failed: %w", err)
+	}
+
+	block, err := common.HexToHash(result.BlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block hash '%v': %w", result.BlockHash, err)
+	}
+
+	proof, err := result.AccountProof()
+	if err != nil {
+		return nil, err
+	}
+
+	account := result.Account()
+
+	header, err := chain.VerifyAccount(address, account, block, proof)
+	if errors.Is(err, chainmgr.ErrBlockNotFound) {
+		// The proof is against a chain head that the light chain has not synced yet
+		if _, err := Sync(ctx, chain, node); err != nil {
+			return nil, err
+		}
+
+		header, err = chain.VerifyAccount(address, account, block, proof)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountState{account, header}, nil
+}
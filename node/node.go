@@ -0,0 +1,184 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/config"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+	"github.com/manishmeganathan/essensio/jsonrpc"
+	"github.com/manishmeganathan/essensio/keystore"
+)
+
+// ErrShutdownTimeout is returned when in-flight requests do not complete within the shutdown timeout
+var ErrShutdownTimeout = errors.New("in-flight requests did not complete before the shutdown timeout")
+
+// Node is an Essensio node that serves the JSON-RPC API for a blockchain.
+// It manages the lifecycle of the RPC server and the ChainManager.
+type Node struct {
+	config *config.Config
+
+	chain     *chainmgr.ChainManager
+	keys      *keystore.Keystore
+	api       *jsonrpc.API
+	websocket *jsonrpc.WebSocketServer
+	server    *http.Server
+}
+
+// ChainConfig returns the ChainManager settings of the given Config
+func ChainConfig(cfg *config.Config) chainmgr.Config {
+	// Block bodies are only pruned in the prune storage mode
+	var depth int64
+	if cfg.Storage.Mode == config.StoragePrune {
+		depth = cfg.Storage.PruneDepth
+	}
+
+	return chainmgr.Config{
+		DataDir:      cfg.DataDir,
+		MinerAddress: common.Address(cfg.Miner.Address),
+		Difficulty:   cfg.Miner.Difficulty,
+		Params: core.ChainParams{
+			ChainID:         cfg.Chain.ID,
+			InitialReward:   cfg.Chain.InitialReward,
+			HalvingInterval: cfg.Chain.HalvingInterval,
+			Ledger:          cfg.Chain.Ledger,
+		},
+		PruneDepth:       depth,
+		SnapshotInterval: cfg.Storage.SnapshotInterval,
+		SnapshotRetain:   cfg.Storage.SnapshotRetain,
+	}
+}
+
+// New returns a new Node for the given Config. The blockchain is loaded from (or initialized
+// in) the data directory, and its genesis block is abandoned if the context is cancelled.
+func New(ctx context.Context, cfg *config.Config) (*Node, error) {
+	// Start the blockchain
+	chain, err := chainmgr.NewChainManager(ctx, ChainConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start blockchain: %w", err)
+	}
+
+	// Open the keystore that signs transactions for unlocked accounts
+	keys, err := keystore.New(cfg.KeystoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		_ = chain.Stop()
+		return nil, fmt.Errorf("failed to open keystore: %w", err)
+	}
+
+	// Create a new JSON-RPC 2.0 Server
+	server := jsonrpc.NewServer()
+
+	// Create a new JSON-RPC API for Essensio
+	api := jsonrpc.NewAPI(chain, keys)
+
+	// Register the Essensio API with the Server
+	if err := server.RegisterService(api, ""); err != nil {
+		_ = chain.Stop()
+		return nil, fmt.Errorf("failed to register essensio api: %w", err)
+	}
+
+	websocket := jsonrpc.NewWebSocketServer(api)
+
+	// Set up a new Multiplexed Router
+	router := mux.NewRouter()
+	router.Handle(cfg.RPC.Path, server)
+	router.Handle(cfg.RPC.WSPath, websocket)
+
+	return &Node{
+		config:    cfg,
+		chain:     chain,
+		keys:      keys,
+		api:       api,
+		websocket: websocket,
+		server:    &http.Server{Addr: cfg.ListenAddr(), Handler: router},
+	}, nil
+}
+
+// Run serves the RPC server until the context is cancelled, after which the Node is shut down.
+// Returns an error if the server fails or if the Node does not shut down cleanly.
+func (node *Node) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", node.server.Addr)
+	if err != nil {
+		_ = node.chain.Stop()
+		return fmt.Errorf("failed to listen on %v: %w", node.server.Addr, err)
+	}
+
+	log.Printf("Server Listening on %v\n", listener.Addr())
+
+	// Serve the RPC server in the background
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- node.server.Serve(listener) }()
+
+	select {
+	case err := <-serveErr:
+		// The server failed before a shutdown was requested
+		log.Println("Server Failed:", err)
+		if stopErr := node.chain.Stop(); stopErr != nil {
+			log.Println("Failed to Close Database:", stopErr)
+		}
+
+		return fmt.Errorf("rpc server failed: %w", err)
+
+	case <-ctx.Done():
+		log.Println("Shutdown Requested")
+		return node.Shutdown()
+	}
+}
+
+// Shutdown stops the Node. It stops accepting RPC requests, abandons any block
+// being mined, locks all unlocked keys, waits for in-flight requests to complete within the shutdown timeout,
+// closes subscriptions and finally flushes and closes the database.
+// Returns an error if any of these steps did not complete cleanly.
+func (node *Node) Shutdown() error {
+	var errs []string
+
+	ctx, cancel := context.WithTimeout(context.Background(), node.config.RPC.ShutdownTimeout)
+	defer cancel()
+
+	// Stop accepting requests and start draining in-flight requests
+	drained := make(chan error, 1)
+	go func() { drained <- node.server.Shutdown(ctx) }()
+
+	// Close the WebSocket connections, which are not tracked by the http.Server
+	node.websocket.Close()
+
+	// Abandon any block being mined, which unblocks its request
+	node.chain.Interrupt()
+
+	// Remove all decrypted keys from memory
+	node.keys.LockAll()
+
+	// Wait for in-flight requests to drain, and force
+	// the remaining connections closed if they do not
+	log.Println("Draining In-Flight Requests...")
+	if err := <-drained; err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = ErrShutdownTimeout
+		}
+
+		errs = append(errs, err.Error())
+		_ = node.server.Close()
+	}
+
+	// Flush and close the database
+	log.Println("Closing Database...")
+	if err := node.chain.Stop(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("unclean shutdown: %v", strings.Join(errs, "; "))
+	}
+
+	log.Println("Shutdown Complete")
+	return nil
+}